@@ -2,27 +2,34 @@ package main
 
 import (
 	"context"
-	"log"
 	"sync"
 
 	"github.com/google/wire"
+	"github.com/thekhanj/csdmpro/control"
 	"github.com/thekhanj/csdmpro/core"
+	"github.com/thekhanj/csdmpro/logging"
 	"github.com/thekhanj/csdmpro/tg"
 )
 
+var appLog = logging.Component("app")
+
 type App struct {
 	CoreObserver *core.Observer
 	TgServer     *tg.Server
 	Notifier     *tg.Notifier
+	Scheduler    *tg.Scheduler
+	Alerter      *tg.Alerter
+	LiveBoards   *tg.LiveBoards
+	Control      *control.Server
 }
 
 func (this *App) Start(ctx context.Context) {
-	log.Println("app: started")
-	defer log.Println("app: stopped")
+	appLog.Info("started")
+	defer appLog.Info("stopped")
 
 	var wg sync.WaitGroup
 
-	wg.Add(3)
+	wg.Add(7)
 
 	go func() {
 		defer wg.Done()
@@ -39,6 +46,26 @@ func (this *App) Start(ctx context.Context) {
 
 		this.Notifier.Start(ctx)
 	}()
+	go func() {
+		defer wg.Done()
+
+		this.Scheduler.Start(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+
+		this.Alerter.Start(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+
+		this.LiveBoards.Start(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+
+		this.Control.Listen(ctx)
+	}()
 	wg.Wait()
 }
 
@@ -46,12 +73,22 @@ func ProvideApp(
 	observer *core.Observer,
 	tgServer *tg.Server,
 	notifier *tg.Notifier,
+	scheduler *tg.Scheduler,
+	alerter *tg.Alerter,
+	liveBoards *tg.LiveBoards,
+	controlServer *control.Server,
 ) *App {
 	return &App{
 		CoreObserver: observer,
 		TgServer:     tgServer,
 		Notifier:     notifier,
+		Scheduler:    scheduler,
+		Alerter:      alerter,
+		LiveBoards:   liveBoards,
+		Control:      controlServer,
 	}
 }
 
-var AppModule = wire.NewSet(ProvideApp, tg.TgModule, core.CoreModule)
+var AppModule = wire.NewSet(
+	ProvideApp, tg.TgModule, core.CoreModule, control.ControlModule,
+)
@@ -0,0 +1,83 @@
+package chart
+
+import (
+	"fmt"
+	"image"
+	"math"
+)
+
+type Bar struct {
+	Label string
+	Value float64
+}
+
+type BarOptions struct {
+	Title string
+	// FormatY labels the y axis, plain integers by default.
+	FormatY func(float64) string
+}
+
+// Bars renders a bar chart in png, the bars being laid out in order.
+func Bars(bars []Bar, opts BarOptions) ([]byte, error) {
+	if len(bars) == 0 {
+		return nil, ERR_NO_DATA
+	}
+	if opts.FormatY == nil {
+		opts.FormatY = func(v float64) string {
+			return fmt.Sprintf("%d", int64(v))
+		}
+	}
+
+	c := newCanvas()
+	c.title(opts.Title)
+	plot := c.plot()
+
+	maxV := 0.0
+	for _, b := range bars {
+		maxV = math.Max(maxV, b.Value)
+	}
+	if maxV == 0 {
+		maxV = 1
+	}
+
+	y := func(v float64) int {
+		return plot.Max.Y - int(v/maxV*float64(plot.Dy()))
+	}
+
+	for _, v := range ticks(0, maxV, 5) {
+		py := y(v)
+		c.line(plot.Min.X, py, plot.Max.X, py, 1, GridColor)
+
+		label := opts.FormatY(v)
+		c.text(
+			plot.Min.X-TextWidth(label, 2)-10, py-GLYPH_HEIGHT, label, 2, TextColor,
+		)
+	}
+
+	slot := plot.Dx() / len(bars)
+	width := max(slot*2/3, 1)
+
+	// skip labels when they would overlap each other
+	labelEvery := 1
+	for labelEvery < len(bars) &&
+		TextWidth(bars[0].Label, 2)+10 > slot*labelEvery {
+		labelEvery++
+	}
+
+	for i, b := range bars {
+		left := plot.Min.X + i*slot + (slot-width)/2
+		c.fillRect(image.Rect(left, y(b.Value), left+width, plot.Max.Y), AccentColor)
+
+		if i%labelEvery == 0 {
+			center := left + width/2
+			c.text(
+				center-TextWidth(b.Label, 2)/2, plot.Max.Y+12, b.Label, 2, TextColor,
+			)
+		}
+	}
+
+	c.line(plot.Min.X, plot.Max.Y, plot.Max.X, plot.Max.Y, 2, TextColor)
+	c.line(plot.Min.X, plot.Min.Y, plot.Min.X, plot.Max.Y, 2, TextColor)
+
+	return c.encode()
+}
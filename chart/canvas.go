@@ -0,0 +1,147 @@
+package chart
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+)
+
+var (
+	BackgroundColor = color.RGBA{0x1e, 0x21, 0x29, 0xff}
+	GridColor       = color.RGBA{0x3a, 0x3f, 0x4b, 0xff}
+	TextColor       = color.RGBA{0xd8, 0xdc, 0xe3, 0xff}
+	AccentColor     = color.RGBA{0x4f, 0xc3, 0xf7, 0xff}
+)
+
+const (
+	WIDTH  = 800
+	HEIGHT = 400
+
+	MARGIN_TOP    = 50
+	MARGIN_RIGHT  = 30
+	MARGIN_BOTTOM = 50
+	MARGIN_LEFT   = 80
+)
+
+type canvas struct {
+	img *image.RGBA
+}
+
+func newCanvas() *canvas {
+	img := image.NewRGBA(image.Rect(0, 0, WIDTH, HEIGHT))
+	draw.Draw(img, img.Bounds(), image.NewUniform(BackgroundColor), image.Point{}, draw.Src)
+
+	return &canvas{img}
+}
+
+// plot is the area the data is drawn in, inside the margins.
+func (this *canvas) plot() image.Rectangle {
+	return image.Rect(
+		MARGIN_LEFT, MARGIN_TOP, WIDTH-MARGIN_RIGHT, HEIGHT-MARGIN_BOTTOM,
+	)
+}
+
+func (this *canvas) fillRect(r image.Rectangle, c color.Color) {
+	draw.Draw(this.img, r.Intersect(this.img.Bounds()), image.NewUniform(c), image.Point{}, draw.Src)
+}
+
+// line draws a line using Bresenham's algorithm, thickened by drawing
+// a square brush on every point.
+func (this *canvas) line(x0, y0, x1, y1, thickness int, c color.Color) {
+	dx := int(math.Abs(float64(x1 - x0)))
+	dy := -int(math.Abs(float64(y1 - y0)))
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	e := dx + dy
+
+	half := thickness / 2
+	for {
+		this.fillRect(
+			image.Rect(x0-half, y0-half, x0-half+thickness, y0-half+thickness), c,
+		)
+
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+
+		e2 := 2 * e
+		if e2 >= dy {
+			e += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			e += dx
+			y0 += sy
+		}
+	}
+}
+
+// text draws text with its top left corner at (x, y).
+func (this *canvas) text(x, y int, text string, scale int, c color.Color) {
+	for _, r := range text {
+		g := glyph(r)
+
+		for row := 0; row < GLYPH_HEIGHT; row++ {
+			for col := 0; col < GLYPH_WIDTH; col++ {
+				if g[row]&(1<<(GLYPH_WIDTH-1-col)) == 0 {
+					continue
+				}
+
+				px := x + col*scale
+				py := y + row*scale
+				this.fillRect(image.Rect(px, py, px+scale, py+scale), c)
+			}
+		}
+
+		x += (GLYPH_WIDTH + 1) * scale
+	}
+}
+
+func (this *canvas) title(title string) {
+	x := (WIDTH - TextWidth(title, 2)) / 2
+	this.text(x, (MARGIN_TOP-GLYPH_HEIGHT*2)/2, title, 2, TextColor)
+}
+
+func (this *canvas) encode() ([]byte, error) {
+	var buf bytes.Buffer
+
+	err := png.Encode(&buf, this.img)
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// ticks splits the [min, max] range into about n round steps.
+func ticks(min float64, max float64, n int) []float64 {
+	if max <= min {
+		return []float64{min}
+	}
+
+	raw := (max - min) / float64(n)
+	magnitude := math.Pow(10, math.Floor(math.Log10(raw)))
+
+	step := magnitude
+	for _, m := range []float64{1, 2, 5, 10} {
+		step = m * magnitude
+		if step >= raw {
+			break
+		}
+	}
+
+	ret := make([]float64, 0, n+1)
+	for v := math.Ceil(min/step) * step; v <= max; v += step {
+		ret = append(ret, v)
+	}
+
+	return ret
+}
@@ -0,0 +1,111 @@
+package chart
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+	"time"
+)
+
+func TestLine(t *testing.T) {
+	now := time.Now()
+	points := []Point{
+		{Time: now.Add(-time.Hour * 48), Value: 12},
+		{Time: now.Add(-time.Hour * 24), Value: 7},
+		{Time: now, Value: 3},
+	}
+
+	data, err := Line(points, LineOptions{Title: "Rank of thekhanj", InvertY: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Bounds().Dx() != WIDTH || img.Bounds().Dy() != HEIGHT {
+		t.Fatalf("unexpected image size %v", img.Bounds())
+	}
+
+	// rank 3 is the best one, drawn on the top right corner of the plot
+	r, g, b, _ := img.At(WIDTH-MARGIN_RIGHT, MARGIN_TOP).RGBA()
+	ar, ag, ab, _ := AccentColor.RGBA()
+	if r != ar || g != ag || b != ab {
+		t.Fatal("expected last point to be drawn on top of the plot")
+	}
+
+	_, err = Line(nil, LineOptions{})
+	if err != ERR_NO_DATA {
+		t.Fatal("expected no data error for empty points")
+	}
+}
+
+func TestBars(t *testing.T) {
+	bars := []Bar{
+		{Label: "01/01", Value: 30},
+		{Label: "01/02", Value: 0},
+		{Label: "01/03", Value: 90},
+	}
+
+	data, err := Bars(bars, BarOptions{Title: "Daily playtime"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTicks(t *testing.T) {
+	got := ticks(0, 100, 5)
+	expected := []float64{0, 20, 40, 60, 80, 100}
+
+	if len(got) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, got)
+	}
+	for i := range got {
+		if got[i] != expected[i] {
+			t.Fatalf("expected %v, got %v", expected, got)
+		}
+	}
+}
+
+func TestHeatmap(t *testing.T) {
+	values := [][]float64{
+		{0, 10, 20},
+		{30, 0, 0},
+	}
+
+	data, err := Heatmap(values, HeatmapOptions{
+		Title:     "Activity",
+		RowLabels: []string{"Mon", "Tue"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the busiest cell is drawn in full accent color
+	plotW := WIDTH - MARGIN_LEFT - MARGIN_RIGHT
+	plotH := HEIGHT - MARGIN_TOP - MARGIN_BOTTOM
+	x := MARGIN_LEFT + plotW/3/2
+	y := MARGIN_TOP + plotH/2 + plotH/2/2
+
+	r, g, b, _ := img.At(x, y).RGBA()
+	ar, ag, ab, _ := AccentColor.RGBA()
+	if r != ar || g != ag || b != ab {
+		t.Fatal("expected the busiest cell to be drawn in accent color")
+	}
+
+	_, err = Heatmap(nil, HeatmapOptions{})
+	if err != ERR_NO_DATA {
+		t.Fatal("expected no data error for empty values")
+	}
+}
@@ -0,0 +1,88 @@
+package chart
+
+import (
+	"image"
+	"image/color"
+)
+
+var EmptyCellColor = color.RGBA{0x2a, 0x2e, 0x38, 0xff}
+
+type HeatmapOptions struct {
+	Title string
+	// RowLabels and ColumnLabels are drawn beside the rows and under the
+	// columns, a column label left empty is skipped.
+	RowLabels    []string
+	ColumnLabels []string
+}
+
+// Heatmap renders a grid of values in png, shading each cell relative to the
+// largest value.
+func Heatmap(values [][]float64, opts HeatmapOptions) ([]byte, error) {
+	if len(values) == 0 || len(values[0]) == 0 {
+		return nil, ERR_NO_DATA
+	}
+
+	c := newCanvas()
+	c.title(opts.Title)
+	plot := c.plot()
+
+	maxV := 0.0
+	for _, row := range values {
+		for _, v := range row {
+			maxV = max(maxV, v)
+		}
+	}
+
+	rows, cols := len(values), len(values[0])
+	cellW := plot.Dx() / cols
+	cellH := plot.Dy() / rows
+
+	for r, row := range values {
+		top := plot.Min.Y + r*cellH
+
+		if r < len(opts.RowLabels) {
+			label := opts.RowLabels[r]
+			c.text(
+				plot.Min.X-TextWidth(label, 2)-10, top+(cellH-GLYPH_HEIGHT*2)/2,
+				label, 2, TextColor,
+			)
+		}
+
+		for col, v := range row {
+			left := plot.Min.X + col*cellW
+
+			ratio := 0.0
+			if maxV != 0 {
+				ratio = v / maxV
+			}
+
+			c.fillRect(
+				image.Rect(left+1, top+1, left+cellW-1, top+cellH-1),
+				blend(EmptyCellColor, AccentColor, ratio),
+			)
+		}
+	}
+
+	for col, label := range opts.ColumnLabels {
+		if label == "" || col >= cols {
+			continue
+		}
+
+		left := plot.Min.X + col*cellW
+		c.text(
+			left, plot.Min.Y+rows*cellH+12, label, 2, TextColor,
+		)
+	}
+
+	return c.encode()
+}
+
+func blend(from color.RGBA, to color.RGBA, ratio float64) color.RGBA {
+	mix := func(a uint8, b uint8) uint8 {
+		return uint8(float64(a) + (float64(b)-float64(a))*ratio)
+	}
+
+	return color.RGBA{
+		mix(from.R, to.R), mix(from.G, to.G), mix(from.B, to.B), 0xff,
+	}
+}
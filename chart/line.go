@@ -0,0 +1,106 @@
+package chart
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+var ERR_NO_DATA error = errors.New("not enough data to draw a chart")
+
+type Point struct {
+	Time  time.Time
+	Value float64
+}
+
+type LineOptions struct {
+	Title string
+	// InvertY puts lower values on top, as in rank one being the best.
+	InvertY bool
+	// FormatY labels the y axis, plain integers by default.
+	FormatY func(float64) string
+}
+
+// Line renders points, sorted by time, as a line chart in png.
+func Line(points []Point, opts LineOptions) ([]byte, error) {
+	if len(points) == 0 {
+		return nil, ERR_NO_DATA
+	}
+	if opts.FormatY == nil {
+		opts.FormatY = func(v float64) string {
+			return fmt.Sprintf("%d", int64(v))
+		}
+	}
+
+	c := newCanvas()
+	c.title(opts.Title)
+	plot := c.plot()
+
+	minT, maxT := points[0].Time, points[len(points)-1].Time
+	minV, maxV := math.Inf(1), math.Inf(-1)
+	for _, p := range points {
+		minV = math.Min(minV, p.Value)
+		maxV = math.Max(maxV, p.Value)
+	}
+	if minV == maxV {
+		minV, maxV = minV-1, maxV+1
+	}
+	if maxT.Equal(minT) {
+		minT, maxT = minT.Add(-time.Hour), maxT.Add(time.Hour)
+	}
+
+	x := func(t time.Time) int {
+		ratio := float64(t.Sub(minT)) / float64(maxT.Sub(minT))
+		return plot.Min.X + int(ratio*float64(plot.Dx()))
+	}
+	y := func(v float64) int {
+		ratio := (v - minV) / (maxV - minV)
+		if opts.InvertY {
+			ratio = 1 - ratio
+		}
+		return plot.Max.Y - int(ratio*float64(plot.Dy()))
+	}
+
+	for _, v := range ticks(minV, maxV, 5) {
+		py := y(v)
+		c.line(plot.Min.X, py, plot.Max.X, py, 1, GridColor)
+
+		label := opts.FormatY(v)
+		c.text(
+			plot.Min.X-TextWidth(label, 2)-10, py-GLYPH_HEIGHT, label, 2, TextColor,
+		)
+	}
+
+	days := maxT.Sub(minT).Hours() / 24
+	for i := 0; i <= 4; i++ {
+		t := minT.Add(time.Duration(float64(maxT.Sub(minT)) * float64(i) / 4))
+
+		layout := "01/02"
+		if days < 2 {
+			layout = "15:04"
+		}
+
+		label := t.Format(layout)
+		px := x(t)
+		c.line(px, plot.Max.Y, px, plot.Max.Y+5, 1, GridColor)
+		lx := min(max(px-TextWidth(label, 2)/2, 0), WIDTH-TextWidth(label, 2))
+		c.text(lx, plot.Max.Y+12, label, 2, TextColor)
+	}
+
+	c.line(plot.Min.X, plot.Max.Y, plot.Max.X, plot.Max.Y, 2, TextColor)
+	c.line(plot.Min.X, plot.Min.Y, plot.Min.X, plot.Max.Y, 2, TextColor)
+
+	for i := 1; i < len(points); i++ {
+		c.line(
+			x(points[i-1].Time), y(points[i-1].Value),
+			x(points[i].Time), y(points[i].Value),
+			3, AccentColor,
+		)
+	}
+
+	last := points[len(points)-1]
+	c.line(x(last.Time), y(last.Value), x(last.Time), y(last.Value), 7, AccentColor)
+
+	return c.encode()
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/thekhanj/csdmpro/control"
+	"github.com/thekhanj/csdmpro/core"
+)
+
+var ERR_USAGE error = errors.New("invalid usage")
+
+const USAGE = `usage: csdmpro [observer <command>]
+
+Runs the bot when given no command, otherwise controls the running bot over
+its control socket, CONTROL_SOCKET or csdmpro.sock by default.
+
+observer commands:
+  status                     show how the crawling loops are doing
+  pause <loop>               stop the loop from crawling
+  resume <loop>              let the loop crawl again, crawling right away
+  crawl <loop>               crawl right away, even if the loop is paused
+  interval <loop> <interval> crawl every interval, e.g. 90s, 5m or 1h
+
+<loop> is onlines or stats.
+`
+
+// runCli runs the command of the arguments against the running bot.
+func runCli(args []string, out io.Writer) error {
+	if len(args) < 2 || args[0] != "observer" {
+		return ERR_USAGE
+	}
+
+	client := control.NewClient(control.SocketPath())
+	command, args := args[1], args[2:]
+
+	var status control.ObserverStatus
+	var err error
+
+	switch {
+	case command == "status" && len(args) == 0:
+		status, err = client.Status()
+	case command == "pause" && len(args) == 1:
+		status, err = withLoop(args[0], client.Pause)
+	case command == "resume" && len(args) == 1:
+		status, err = withLoop(args[0], client.Resume)
+	case command == "crawl" && len(args) == 1:
+		status, err = withLoop(args[0], client.Crawl)
+	case command == "interval" && len(args) == 2:
+		interval, parseErr := time.ParseDuration(args[1])
+		if parseErr != nil {
+			return parseErr
+		}
+
+		status, err = withLoop(
+			args[0], func(loop core.ObserverLoop) (control.ObserverStatus, error) {
+				return client.SetInterval(loop, interval)
+			},
+		)
+	default:
+		return ERR_USAGE
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return printStatus(out, status, time.Now())
+}
+
+func withLoop(
+	s string, f func(core.ObserverLoop) (control.ObserverStatus, error),
+) (control.ObserverStatus, error) {
+	loop, err := core.ParseObserverLoop(s)
+	if err != nil {
+		return control.ObserverStatus{}, err
+	}
+
+	return f(loop)
+}
+
+func printStatus(out io.Writer, status control.ObserverStatus, now time.Time) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(w, "LOOP\tEVERY\tLAST CRAWL\tTOOK\tNEXT CRAWL\tERROR")
+	printLoop(w, core.OnlinesLoop, status.Onlines, now)
+	printLoop(w, core.StatsLoop, status.Stats, now)
+
+	return w.Flush()
+}
+
+func printLoop(
+	w io.Writer, loop core.ObserverLoop, s control.LoopStatus, now time.Time,
+) {
+	last, took := "never", "-"
+	if !s.At.IsZero() {
+		last = now.Sub(s.At).Round(time.Second).String() + " ago"
+		took = s.Duration.Round(time.Millisecond).String()
+	}
+
+	next := "paused"
+	if !s.Paused {
+		next = "now"
+		if wait := s.Next.Sub(now); wait > 0 {
+			next = "in " + wait.Round(time.Second).String()
+		}
+	}
+
+	errMsg := s.Err
+	if errMsg == "" {
+		errMsg = "-"
+	}
+
+	fmt.Fprintf(
+		w, "%s\t%s\t%s\t%s\t%s\t%s\n", loop, s.Interval, last, took, next, errMsg,
+	)
+}
+
+// cli runs the command line, exiting once done.
+func cli(args []string) {
+	err := runCli(args, os.Stdout)
+	if err == ERR_USAGE {
+		fmt.Fprint(os.Stderr, USAGE)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "csdmpro: %s\n", err)
+		os.Exit(1)
+	}
+
+	os.Exit(0)
+}
@@ -0,0 +1,102 @@
+package control
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/thekhanj/csdmpro/core"
+)
+
+// CONTROL_TIMEOUT is how long the client waits for the bot to answer.
+const CONTROL_TIMEOUT = 10 * time.Second
+
+// Client sends control requests to the bot over its socket.
+type Client struct {
+	http *http.Client
+}
+
+func (this *Client) Status() (ObserverStatus, error) {
+	return this.request(http.MethodGet, "/observer")
+}
+
+func (this *Client) Crawl(loop core.ObserverLoop) (ObserverStatus, error) {
+	return this.request(http.MethodPost, this.loopPath(loop, "crawl"))
+}
+
+func (this *Client) Pause(loop core.ObserverLoop) (ObserverStatus, error) {
+	return this.request(http.MethodPost, this.loopPath(loop, "pause"))
+}
+
+func (this *Client) Resume(loop core.ObserverLoop) (ObserverStatus, error) {
+	return this.request(http.MethodPost, this.loopPath(loop, "resume"))
+}
+
+func (this *Client) SetInterval(
+	loop core.ObserverLoop, interval time.Duration,
+) (ObserverStatus, error) {
+	return this.request(
+		http.MethodPost,
+		this.loopPath(loop, "interval", url.PathEscape(interval.String())),
+	)
+}
+
+func (this *Client) loopPath(loop core.ObserverLoop, parts ...string) string {
+	return "/observer/" + url.PathEscape(string(loop)) + "/" +
+		strings.Join(parts, "/")
+}
+
+func (this *Client) request(method string, path string) (ObserverStatus, error) {
+	var status ObserverStatus
+
+	// the host is ignored, the socket is dialed whatever it is
+	req, err := http.NewRequest(method, "http://csdmpro"+path, nil)
+	if err != nil {
+		return status, err
+	}
+
+	res, err := this.http.Do(req)
+	if err != nil {
+		return status, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		msg := strings.TrimSpace(string(body))
+		if msg == "" {
+			msg = res.Status
+		}
+
+		return status, errors.New(msg)
+	}
+
+	err = json.NewDecoder(res.Body).Decode(&status)
+	if err != nil {
+		return status, fmt.Errorf("invalid response: %w", err)
+	}
+
+	return status, nil
+}
+
+func NewClient(path string) *Client {
+	dialer := &net.Dialer{}
+
+	return &Client{
+		http: &http.Client{
+			Timeout: CONTROL_TIMEOUT,
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					return dialer.DialContext(ctx, "unix", path)
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,24 @@
+package control
+
+import (
+	"os"
+
+	"github.com/google/wire"
+	"github.com/thekhanj/csdmpro/core"
+)
+
+// SocketPath is where the control socket is, CONTROL_SOCKET if set.
+func SocketPath() string {
+	path := os.Getenv("CONTROL_SOCKET")
+	if path == "" {
+		return DEFAULT_CONTROL_SOCKET
+	}
+
+	return path
+}
+
+func ProvideServer(observer *core.Observer) *Server {
+	return NewServer(observer, SocketPath())
+}
+
+var ControlModule = wire.NewSet(ProvideServer)
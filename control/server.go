@@ -0,0 +1,194 @@
+// Package control lets the running bot be controlled from the command line,
+// over a unix socket only the user running the bot can connect to.
+package control
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/thekhanj/csdmpro/core"
+	"github.com/thekhanj/csdmpro/logging"
+)
+
+var controlLog = logging.Component("control")
+
+const (
+	// DEFAULT_CONTROL_SOCKET is where the socket is unless CONTROL_SOCKET says
+	// otherwise.
+	DEFAULT_CONTROL_SOCKET = "csdmpro.sock"
+	// Time given to the requests being handled when the server stops.
+	CONTROL_SHUTDOWN_TIMEOUT = 5 * time.Second
+)
+
+// LoopStatus is core.CrawlStatus as sent over the socket.
+type LoopStatus struct {
+	At       time.Time     `json:"at"`
+	Duration time.Duration `json:"duration"`
+	Err      string        `json:"err,omitempty"`
+	Paused   bool          `json:"paused"`
+	Interval time.Duration `json:"interval"`
+	Next     time.Time     `json:"next"`
+}
+
+type ObserverStatus struct {
+	Onlines LoopStatus `json:"onlines"`
+	Stats   LoopStatus `json:"stats"`
+}
+
+func newLoopStatus(s core.CrawlStatus) LoopStatus {
+	status := LoopStatus{
+		At:       s.At,
+		Duration: s.Duration,
+		Paused:   s.Paused,
+		Interval: s.Interval,
+		Next:     s.Next(),
+	}
+	if s.Err != nil {
+		status.Err = s.Err.Error()
+	}
+
+	return status
+}
+
+func newObserverStatus(s core.ObserverStatus) ObserverStatus {
+	return ObserverStatus{newLoopStatus(s.Onlines), newLoopStatus(s.Stats)}
+}
+
+// Server serves the control requests on the socket at path, answering them
+// with the status of the observer.
+type Server struct {
+	observer *core.Observer
+	path     string
+}
+
+func (this *Server) Listen(ctx context.Context) {
+	// left from a bot that didn't stop cleanly, nobody's listening on it
+	err := os.Remove(this.path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		controlLog.Error("failed removing old socket", "path", this.path, "err", err)
+		return
+	}
+
+	ln, err := net.Listen("unix", this.path)
+	if err != nil {
+		controlLog.Error("failed listening", "path", this.path, "err", err)
+		return
+	}
+
+	err = os.Chmod(this.path, 0600)
+	if err != nil {
+		ln.Close()
+		controlLog.Error("failed securing socket", "path", this.path, "err", err)
+		return
+	}
+
+	srv := &http.Server{
+		Handler:           this.handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	serveDone := make(chan struct{})
+	go func() {
+		defer close(serveDone)
+
+		controlLog.Info("listening", "path", this.path)
+
+		err := srv.Serve(ln)
+		if err != nil && err != http.ErrServerClosed {
+			controlLog.Error("server failed", "err", err)
+		}
+	}()
+
+	select {
+	case <-ctx.Done():
+	case <-serveDone:
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(
+		context.Background(), CONTROL_SHUTDOWN_TIMEOUT,
+	)
+	defer cancel()
+
+	err = srv.Shutdown(shutdownCtx)
+	if err != nil {
+		controlLog.Warn("failed shutting down", "err", err)
+		srv.Close()
+	}
+	<-serveDone
+
+	controlLog.Info("stopped")
+}
+
+func (this *Server) handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /observer", func(w http.ResponseWriter, r *http.Request) {
+		this.respond(w, nil)
+	})
+	mux.HandleFunc("POST /observer/{loop}/crawl", this.control(this.observer.Crawl))
+	mux.HandleFunc("POST /observer/{loop}/pause", this.control(this.observer.Pause))
+	mux.HandleFunc("POST /observer/{loop}/resume", this.control(this.observer.Resume))
+	mux.HandleFunc(
+		"POST /observer/{loop}/interval/{interval}",
+		func(w http.ResponseWriter, r *http.Request) {
+			interval, err := time.ParseDuration(r.PathValue("interval"))
+			if err != nil {
+				this.respond(w, err)
+				return
+			}
+
+			this.respond(w, this.withLoop(r, func(loop core.ObserverLoop) error {
+				return this.observer.SetInterval(loop, interval)
+			}))
+		},
+	)
+
+	return mux
+}
+
+// control does the control to the loop of the request.
+func (this *Server) control(
+	control func(core.ObserverLoop) error,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		this.respond(w, this.withLoop(r, control))
+	}
+}
+
+func (this *Server) withLoop(
+	r *http.Request, f func(core.ObserverLoop) error,
+) error {
+	loop, err := core.ParseObserverLoop(r.PathValue("loop"))
+	if err != nil {
+		return err
+	}
+
+	err = f(loop)
+	if err != nil {
+		return err
+	}
+
+	controlLog.Info("controlled the observer", "loop", loop, "path", r.URL.Path)
+
+	return nil
+}
+
+// respond sends the error, if any, otherwise the status of the observer.
+func (this *Server) respond(w http.ResponseWriter, err error) {
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(newObserverStatus(this.observer.Status()))
+}
+
+func NewServer(observer *core.Observer, path string) *Server {
+	return &Server{observer, path}
+}
@@ -0,0 +1,108 @@
+package control
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/thekhanj/csdmpro/core"
+)
+
+// listen serves the observer's controls on a socket, returning a client of
+// it. The observer isn't started, its loops only keep what they're told.
+func listen(t *testing.T, observer *core.Observer) *Client {
+	path := filepath.Join(t.TempDir(), "control.sock")
+
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan struct{})
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	go func() {
+		defer close(done)
+		NewServer(observer, path).Listen(ctx)
+	}()
+
+	client := NewClient(path)
+
+	// the socket shows up once the server is listening
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		_, err := client.Status()
+		if err == nil {
+			return client
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the server to listen, got %v", err)
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestControlObserver(t *testing.T) {
+	observer := core.NewObserver(nil, nil, time.Hour, time.Minute)
+	client := listen(t, observer)
+
+	status, err := client.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Onlines.Interval != time.Minute || status.Stats.Interval != time.Hour {
+		t.Fatalf("unexpected intervals %+v", status)
+	}
+
+	status, err = client.Pause(core.StatsLoop)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status.Stats.Paused || status.Onlines.Paused {
+		t.Fatalf("expected only the stats to be paused, got %+v", status)
+	}
+	if !observer.Status().Stats.Paused {
+		t.Fatal("expected the observer's stats to be paused")
+	}
+
+	status, err = client.SetInterval(core.OnlinesLoop, 90*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Onlines.Interval != 90*time.Second {
+		t.Fatalf("expected the onlines every 90s, got %s", status.Onlines.Interval)
+	}
+
+	status, err = client.Resume(core.StatsLoop)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Stats.Paused {
+		t.Fatal("expected the stats to be resumed")
+	}
+
+	_, err = client.Crawl(core.OnlinesLoop)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestControlObserverErrors(t *testing.T) {
+	observer := core.NewObserver(nil, nil, time.Hour, time.Minute)
+	client := listen(t, observer)
+
+	_, err := client.Pause("players")
+	if err == nil || err.Error() != core.ERR_UNKNOWN_OBSERVER_LOOP.Error() {
+		t.Fatalf("expected %v, got %v", core.ERR_UNKNOWN_OBSERVER_LOOP, err)
+	}
+
+	_, err = client.SetInterval(core.StatsLoop, time.Second)
+	if err == nil || err.Error() != core.ERR_INVALID_OBSERVER_INTERVAL.Error() {
+		t.Fatalf("expected %v, got %v", core.ERR_INVALID_OBSERVER_INTERVAL, err)
+	}
+
+	if observer.Status().Stats.Interval != time.Hour {
+		t.Fatal("expected the stats interval to stay as it was")
+	}
+}
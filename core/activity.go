@@ -0,0 +1,143 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Heatmap holds the time spent online in each hour of each weekday, indexed
+// by time.Weekday and then by hour.
+type Heatmap [7][24]time.Duration
+
+// An hour of a weekday counts as a usual playing time when it has at least
+// this fraction of the busiest hour's activity.
+const USUAL_ACTIVITY_RATIO = 0.5
+
+// BuildHeatmap buckets the sessions into weekdays and hours of loc.
+func BuildHeatmap(sessions []Session, now time.Time, loc *time.Location) Heatmap {
+	var h Heatmap
+
+	for _, s := range sessions {
+		t := s.Start.In(loc)
+		end := s.EndOr(now).In(loc)
+
+		for t.Before(end) {
+			// not using Truncate, it works on absolute time and breaks
+			// on zones with offsets that aren't whole hours
+			hourEnd := time.Date(
+				t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, loc,
+			).Add(time.Hour)
+
+			to := minTime(hourEnd, end)
+			h[t.Weekday()][t.Hour()] += to.Sub(t)
+			t = to
+		}
+	}
+
+	return h
+}
+
+func (this *PlayerRepo) ActivityHeatmap(
+	id PlayerId, since time.Time, loc *time.Location,
+) (Heatmap, error) {
+	sessions, err := this.Sessions(id, since)
+	if err != nil {
+		return Heatmap{}, err
+	}
+
+	for i := range sessions {
+		if sessions[i].Start.Before(since) {
+			sessions[i].Start = since
+		}
+	}
+
+	return BuildHeatmap(sessions, time.Now(), loc), nil
+}
+
+func (this *Heatmap) Max() time.Duration {
+	var ret time.Duration
+
+	for _, hours := range this {
+		for _, d := range hours {
+			ret = max(ret, d)
+		}
+	}
+
+	return ret
+}
+
+// ActivitySlot is a range of hours, From inclusive and To exclusive, in which
+// a player is usually online on the given days.
+type ActivitySlot struct {
+	Days     []time.Weekday
+	From     int
+	To       int
+	activity time.Duration
+}
+
+func (this ActivitySlot) String() string {
+	days := make([]string, 0, len(this.Days))
+	for _, d := range this.Days {
+		days = append(days, d.String()[:3])
+	}
+
+	return fmt.Sprintf(
+		"%s %02d:00–%02d:00", strings.Join(days, "/"), this.From, this.To%24,
+	)
+}
+
+// UsualSlots finds up to n slots in which the player is usually online, the
+// busiest first. Days sharing the same hours are merged into one slot.
+func (this *Heatmap) UsualSlots(n int) []ActivitySlot {
+	peak := this.Max()
+	if peak == 0 {
+		return []ActivitySlot{}
+	}
+	threshold := time.Duration(float64(peak) * USUAL_ACTIVITY_RATIO)
+
+	slots := make([]ActivitySlot, 0)
+	merge := func(day time.Weekday, from int, to int, activity time.Duration) {
+		for i := range slots {
+			if slots[i].From == from && slots[i].To == to {
+				slots[i].Days = append(slots[i].Days, day)
+				slots[i].activity += activity
+				return
+			}
+		}
+
+		slots = append(slots, ActivitySlot{
+			Days: []time.Weekday{day}, From: from, To: to, activity: activity,
+		})
+	}
+
+	// starting the week on monday
+	for i := 1; i <= 7; i++ {
+		day := time.Weekday(i % 7)
+
+		from := -1
+		var activity time.Duration
+		for hour := 0; hour <= 24; hour++ {
+			hot := hour < 24 && this[day][hour] >= threshold
+
+			if hot && from == -1 {
+				from = hour
+				activity = 0
+			}
+			if hot {
+				activity += this[day][hour]
+			}
+			if !hot && from != -1 {
+				merge(day, from, hour, activity)
+				from = -1
+			}
+		}
+	}
+
+	sort.SliceStable(slots, func(i, j int) bool {
+		return slots[i].activity > slots[j].activity
+	})
+
+	return slots[:min(n, len(slots))]
+}
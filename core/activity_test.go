@@ -0,0 +1,44 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHeatmapUsualSlots(t *testing.T) {
+	loc := time.FixedZone("IRST", 3*3600+1800)
+	now := time.Date(2025, 4, 30, 0, 0, 0, 0, loc)
+
+	session := func(day int, fromHour int, toHour int) Session {
+		end := time.Date(2025, 4, day, toHour, 0, 0, 0, loc)
+		return Session{
+			Start: time.Date(2025, 4, day, fromHour, 0, 0, 0, loc),
+			End:   &end,
+		}
+	}
+
+	sessions := []Session{
+		// tuesdays and thursdays 21:00-23:00
+		session(1, 21, 23), session(3, 21, 23),
+		session(8, 21, 23), session(10, 21, 23),
+		// a quick sunday visit
+		session(6, 10, 11),
+	}
+
+	h := BuildHeatmap(sessions, now, loc)
+
+	if h[time.Tuesday][21] != time.Hour*2 {
+		t.Fatalf("expected 2 hours on tuesdays at 21, got %s", h[time.Tuesday][21])
+	}
+	if h[time.Sunday][10] != time.Hour {
+		t.Fatalf("expected an hour on sunday at 10, got %s", h[time.Sunday][10])
+	}
+
+	slots := h.UsualSlots(3)
+	if len(slots) != 2 {
+		t.Fatalf("expected 2 usual slots, got %v", slots)
+	}
+	if slots[0].String() != "Tue/Thu 21:00–23:00" {
+		t.Fatalf("unexpected busiest slot %s", slots[0])
+	}
+}
@@ -0,0 +1,189 @@
+package core
+
+import "time"
+
+type PlayerSummary struct {
+	DbPlayer DbPlayer
+	// BestRank is the highest rank reached in the compared period, nil if the
+	// player was never ranked.
+	BestRank *int
+	// RankChange is how many places the player climbed in the compared
+	// period, negative when dropped.
+	RankChange int
+	Playtime   time.Duration
+}
+
+type Comparison struct {
+	Since   time.Time
+	Players [2]PlayerSummary
+}
+
+type ComparedMetric struct {
+	Name          string
+	Values        [2]float64
+	LowerIsBetter bool
+}
+
+const (
+	ComparedRank       = "rank"
+	ComparedScore      = "score"
+	ComparedKills      = "kills"
+	ComparedDeaths     = "deaths"
+	ComparedKD         = "kd"
+	ComparedAccuracy   = "accuracy"
+	ComparedBestRank   = "best-rank"
+	ComparedRankChange = "rank-change"
+	ComparedPlaytime   = "playtime"
+)
+
+// Leader returns the index of the player leading in the metric, or -1 on a
+// tie.
+func (this ComparedMetric) Leader() int {
+	a, b := this.Values[0], this.Values[1]
+	if a == b {
+		return -1
+	}
+
+	if (a < b) == this.LowerIsBetter {
+		return 0
+	}
+
+	return 1
+}
+
+func (this *Comparison) Metrics() []ComparedMetric {
+	var p [2]PlayerSummary = this.Players
+
+	// unranked players are pushed behind everyone else
+	rankValue := func(rank *int) float64 {
+		if rank == nil {
+			return float64(^uint32(0))
+		}
+		return float64(*rank)
+	}
+	kd := func(p Player) float64 {
+		return float64(p.Kills) / float64(max(p.Deaths, 1))
+	}
+
+	return []ComparedMetric{
+		{
+			Name: ComparedRank,
+			Values: [2]float64{
+				rankValue(p[0].DbPlayer.Player.Rank),
+				rankValue(p[1].DbPlayer.Player.Rank),
+			},
+			LowerIsBetter: true,
+		},
+		{
+			Name: ComparedScore,
+			Values: [2]float64{
+				float64(p[0].DbPlayer.Player.Score),
+				float64(p[1].DbPlayer.Player.Score),
+			},
+		},
+		{
+			Name: ComparedKills,
+			Values: [2]float64{
+				float64(p[0].DbPlayer.Player.Kills),
+				float64(p[1].DbPlayer.Player.Kills),
+			},
+		},
+		{
+			Name: ComparedDeaths,
+			Values: [2]float64{
+				float64(p[0].DbPlayer.Player.Deaths),
+				float64(p[1].DbPlayer.Player.Deaths),
+			},
+			LowerIsBetter: true,
+		},
+		{
+			Name: ComparedKD,
+			Values: [2]float64{
+				kd(p[0].DbPlayer.Player), kd(p[1].DbPlayer.Player),
+			},
+		},
+		{
+			Name: ComparedAccuracy,
+			Values: [2]float64{
+				float64(p[0].DbPlayer.Player.Accuracy),
+				float64(p[1].DbPlayer.Player.Accuracy),
+			},
+		},
+		{
+			Name: ComparedBestRank,
+			Values: [2]float64{
+				rankValue(p[0].BestRank), rankValue(p[1].BestRank),
+			},
+			LowerIsBetter: true,
+		},
+		{
+			Name: ComparedRankChange,
+			Values: [2]float64{
+				float64(p[0].RankChange), float64(p[1].RankChange),
+			},
+		},
+		{
+			Name: ComparedPlaytime,
+			Values: [2]float64{
+				p[0].Playtime.Seconds(), p[1].Playtime.Seconds(),
+			},
+		},
+	}
+}
+
+// Compare puts two players side by side, their current stats along with
+// their rank history and playtime since the given time.
+func (this *PlayerRepo) Compare(
+	a PlayerId, b PlayerId, since time.Time,
+) (Comparison, error) {
+	c := Comparison{Since: since}
+
+	for i, id := range [2]PlayerId{a, b} {
+		summary, err := this.summarize(id, since)
+		if err != nil {
+			return Comparison{}, err
+		}
+
+		c.Players[i] = summary
+	}
+
+	return c, nil
+}
+
+func (this *PlayerRepo) summarize(
+	id PlayerId, since time.Time,
+) (PlayerSummary, error) {
+	p, err := this.GetPlayer(id)
+	if err != nil {
+		return PlayerSummary{}, err
+	}
+
+	history, err := this.StatsHistory(id, since)
+	if err != nil {
+		return PlayerSummary{}, err
+	}
+
+	playtime, err := this.Playtime(id, since)
+	if err != nil {
+		return PlayerSummary{}, err
+	}
+
+	summary := PlayerSummary{
+		DbPlayer: p,
+		BestRank: p.Player.Rank,
+		Playtime: playtime,
+	}
+
+	for _, s := range history {
+		r := s.Player.Rank
+		if r != nil && (summary.BestRank == nil || *r < *summary.BestRank) {
+			summary.BestRank = r
+		}
+	}
+
+	if len(history) != 0 && history[0].Player.Rank != nil && p.Player.Rank != nil {
+		summary.RankChange = *history[0].Player.Rank - *p.Player.Rank
+	}
+
+	return summary, nil
+}
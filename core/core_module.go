@@ -1,17 +1,19 @@
 package core
 
 import (
-	"log"
 	"time"
 
 	"github.com/google/wire"
 	"github.com/thekhanj/csdmpro/db"
+	"github.com/thekhanj/csdmpro/logging"
 )
 
+var repoLog = logging.Component("repo")
+
 func ProvidePlayerRepo(db db.Database) *PlayerRepo {
 	repo, err := CreatePlayerRepo(db)
 	if err != nil {
-		log.Fatal(err)
+		logging.Fatal(repoLog, "failed creating player repo", "err", err)
 	}
 
 	return repo
@@ -0,0 +1,62 @@
+package core
+
+import (
+	"path"
+	"strings"
+)
+
+const UNKNOWN_COUNTRY_FLAG = "🏳️"
+
+// ParseCountry normalizes the flag image source found on csdm.pro into an
+// ISO 3166-1 alpha-2 country code.
+//
+//	Example: /images/flags/ir.png -> IR
+//
+// An empty string is returned when no code can be extracted.
+func ParseCountry(src string) string {
+	src = strings.TrimSpace(src)
+	if src == "" {
+		return ""
+	}
+
+	if i := strings.IndexAny(src, "?#"); i != -1 {
+		src = src[:i]
+	}
+
+	name := path.Base(src)
+	name = strings.TrimSuffix(name, path.Ext(name))
+
+	if len(name) != 2 {
+		return ""
+	}
+
+	code := strings.ToUpper(name)
+	for _, c := range code {
+		if c < 'A' || c > 'Z' {
+			return ""
+		}
+	}
+
+	return code
+}
+
+// CountryFlag renders an ISO 3166-1 alpha-2 code as a flag emoji, built out of
+// the two matching regional indicator symbols.
+func CountryFlag(code string) string {
+	if len(code) != 2 {
+		return UNKNOWN_COUNTRY_FLAG
+	}
+
+	code = strings.ToUpper(code)
+
+	flag := ""
+	for _, c := range code {
+		if c < 'A' || c > 'Z' {
+			return UNKNOWN_COUNTRY_FLAG
+		}
+
+		flag += string(rune(0x1F1E6 + c - 'A'))
+	}
+
+	return flag
+}
@@ -0,0 +1,34 @@
+package core
+
+import "testing"
+
+func TestParseCountry(t *testing.T) {
+	cases := map[string]string{
+		"/images/flags/ir.png":                 "IR",
+		"https://www.csdm.pro/img/flag/DE.gif": "DE",
+		"/flags/us.png?v=2":                    "US",
+		"IR":                                   "IR",
+		"":                                     "",
+		"/images/flags/unknown.png":            "",
+		"/images/flags/1a.png":                 "",
+	}
+
+	for src, expected := range cases {
+		got := ParseCountry(src)
+		if got != expected {
+			t.Errorf("ParseCountry(%q) = %q, expected %q", src, got, expected)
+		}
+	}
+}
+
+func TestCountryFlag(t *testing.T) {
+	if flag := CountryFlag("IR"); flag != "🇮🇷" {
+		t.Errorf("expected iran's flag, got %s", flag)
+	}
+	if flag := CountryFlag("de"); flag != "🇩🇪" {
+		t.Errorf("expected germany's flag, got %s", flag)
+	}
+	if flag := CountryFlag(""); flag != UNKNOWN_COUNTRY_FLAG {
+		t.Errorf("expected unknown flag, got %s", flag)
+	}
+}
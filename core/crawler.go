@@ -1,18 +1,19 @@
 package core
 
 import (
-	"errors"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
 	"regexp"
 	"strconv"
 	"strings"
 
 	"github.com/PuerkitoBio/goquery"
+	"github.com/thekhanj/csdmpro/logging"
 )
 
+var crawlerLog = logging.Component("crawler")
+
 const CSDMPRO_SITE = "https://www.csdm.pro"
 
 type Player struct {
@@ -79,19 +80,19 @@ func (this *HttpCrawler) parseBody(body io.ReadCloser) ([]Player, error) {
 		re := regexp.MustCompile("([0-9]*)")
 		accuracyStrCleanedMatches := re.FindStringSubmatch(accuracyStr)
 		if len(accuracyStrCleanedMatches) == 0 {
-			log.Println(errors.New("accuracy regex not matched!"))
+			crawlerLog.Warn("accuracy regex not matched", "player", username)
 			return
 		}
 		accuracyStrCleaned := accuracyStrCleanedMatches[0]
 		accuracy, err := strconv.Atoi(accuracyStrCleaned)
 		if err != nil {
-			log.Println(err)
+			crawlerLog.Warn("invalid accuracy", "player", username, "err", err)
 			return
 		}
 
 		player := Player{
 			Name:     username,
-			Country:  imgSrc,
+			Country:  ParseCountry(imgSrc),
 			Rank:     &rank,
 			Score:    score,
 			Kills:    kills,
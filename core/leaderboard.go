@@ -0,0 +1,171 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+type Metric string
+
+const (
+	MetricKD         Metric = "kd"
+	MetricAccuracy   Metric = "accuracy"
+	MetricKills      Metric = "kills"
+	MetricScoreToday Metric = "score-today"
+	MetricScoreWeek  Metric = "score-week"
+	MetricPlaytime   Metric = "playtime"
+)
+
+var Metrics = []Metric{
+	MetricKD, MetricAccuracy, MetricKills,
+	MetricScoreToday, MetricScoreWeek, MetricPlaytime,
+}
+
+// Players with fewer kills than these are left out of the ratio based
+// leaderboards, a handful of lucky rounds shouldn't top the list.
+const (
+	LEADERBOARD_KD_MIN_KILLS       = 500
+	LEADERBOARD_ACCURACY_MIN_KILLS = 500
+)
+
+// Playtime leaderboard covers the sessions of the last week.
+const LEADERBOARD_PLAYTIME_PERIOD = time.Hour * 24 * 7
+
+var ERR_UNKNOWN_METRIC error = errors.New("unknown metric")
+
+type LeaderboardEntry struct {
+	DbPlayer DbPlayer
+	// Value is a ratio for kd, a percentage for accuracy, seconds for
+	// playtime and a plain count for the rest.
+	Value float64
+}
+
+func (this *PlayerRepo) Leaderboard(
+	metric Metric, offset int, limit int,
+) ([]LeaderboardEntry, error) {
+	now := time.Now()
+
+	var value, where string
+	var args []any
+
+	switch metric {
+	case MetricKD:
+		value = "CAST(p.kills AS REAL) / MAX(p.deaths, 1)"
+		where = "p.kills >= ?"
+		args = []any{LEADERBOARD_KD_MIN_KILLS}
+	case MetricAccuracy:
+		value = "p.accuracy"
+		where = "p.kills >= ?"
+		args = []any{LEADERBOARD_ACCURACY_MIN_KILLS}
+	case MetricKills:
+		value = "p.kills"
+		where = "p.kills > 0"
+	case MetricScoreToday, MetricScoreWeek:
+		since := StartOfDay(now)
+		if metric == MetricScoreWeek {
+			since = StartOfWeek(now)
+		}
+
+		value = `p.score - COALESCE(
+			(
+				SELECT s.score FROM player_stats AS s
+				WHERE s.player_id = p.id AND s.time <= ?
+				ORDER BY s.time DESC LIMIT 1
+			),
+			(
+				SELECT s.score FROM player_stats AS s
+				WHERE s.player_id = p.id
+				ORDER BY s.time ASC LIMIT 1
+			),
+			p.score
+		)`
+		where = "value > 0"
+		args = []any{since.Unix()}
+	case MetricPlaytime:
+		since := now.Add(-LEADERBOARD_PLAYTIME_PERIOD).Unix()
+
+		value = `(
+			SELECT SUM(COALESCE(o.end_time, ?) - MAX(o.start_time, ?))
+			FROM onlines AS o
+			WHERE o.player_id = p.id AND COALESCE(o.end_time, ?) > ?
+		)`
+		where = "value > 0"
+		args = []any{now.Unix(), since, now.Unix(), since}
+	default:
+		return nil, ERR_UNKNOWN_METRIC
+	}
+
+	rows, err := this.Database.Query(fmt.Sprintf(`
+		SELECT %s, %s AS value
+		FROM players as p
+		WHERE %s
+		ORDER BY value DESC, p.rank ASC
+		LIMIT ? OFFSET ?
+	`, this.getPlayerFields("p."), value, where), append(args, limit, offset)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make([]LeaderboardEntry, 0)
+
+	for rows.Next() {
+		var e LeaderboardEntry
+
+		e.DbPlayer, err = this.scanPlayer(rows, &e.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, e)
+	}
+
+	return entries, nil
+}
+
+// TopEntrants lists the players who are in the top n now but weren't at the
+// given time, best ranked first.
+func (this *PlayerRepo) TopEntrants(n int, since time.Time) ([]DbPlayer, error) {
+	rows, err := this.Database.Query(fmt.Sprintf(`
+		SELECT %s
+		FROM players as p
+		WHERE p.rank IS NOT NULL AND p.rank <= ? AND COALESCE(
+			(
+				SELECT s.rank FROM player_stats AS s
+				WHERE s.player_id = p.id AND s.time <= ?
+				ORDER BY s.time DESC LIMIT 1
+			),
+			? + 1
+		) > ?
+		ORDER BY p.rank ASC
+	`, this.getPlayerFields("p.")), n, since.Unix(), n, n)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	players := make([]DbPlayer, 0)
+
+	for rows.Next() {
+		p, err := this.scanPlayer(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		players = append(players, p)
+	}
+
+	return players, nil
+}
+
+func StartOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+// StartOfWeek returns the beginning of the monday of t's week.
+func StartOfWeek(t time.Time) time.Time {
+	days := (int(t.Weekday()) + 6) % 7
+	return StartOfDay(t).AddDate(0, 0, -days)
+}
@@ -2,15 +2,19 @@ package core
 
 import (
 	"context"
-	"log"
+	"errors"
+	"fmt"
 	"os"
 	"sync"
 	"time"
 
 	"github.com/cskr/pubsub/v2"
 	_ "github.com/mattn/go-sqlite3"
+	"github.com/thekhanj/csdmpro/logging"
 )
 
+var observerLog = logging.Component("observer")
+
 type Topic int
 
 const (
@@ -22,17 +26,234 @@ const (
 
 type Bus = *pubsub.PubSub[Topic, PlayerId]
 
+var ERR_UNKNOWN_OBSERVER_LOOP error = errors.New("loop must be onlines or stats")
+var ERR_INVALID_OBSERVER_INTERVAL error = errors.New(
+	"interval must be between 30s and 24h",
+)
+
+// Intervals the loops can be set to at runtime, crawling the site more often
+// than this would hammer it.
+const (
+	MIN_OBSERVER_INTERVAL = 30 * time.Second
+	MAX_OBSERVER_INTERVAL = 24 * time.Hour
+)
+
+// ObserverLoop names one of the crawling loops of the observer.
+type ObserverLoop string
+
+const (
+	OnlinesLoop ObserverLoop = "onlines"
+	StatsLoop   ObserverLoop = "stats"
+)
+
+var OBSERVER_LOOPS = []ObserverLoop{OnlinesLoop, StatsLoop}
+
+func ParseObserverLoop(s string) (ObserverLoop, error) {
+	for _, loop := range OBSERVER_LOOPS {
+		if string(loop) == s {
+			return loop, nil
+		}
+	}
+
+	return "", ERR_UNKNOWN_OBSERVER_LOOP
+}
+
+// CrawlStatus tells how the last crawl of a loop went, a zero At means it
+// hasn't happened yet, along with how the loop is set to crawl.
+type CrawlStatus struct {
+	At       time.Time
+	Duration time.Duration
+	Err      error
+
+	Paused   bool
+	Interval time.Duration
+}
+
+// Next tells when the loop crawls next, a zero time if it's paused.
+func (this CrawlStatus) Next() time.Time {
+	if this.Paused {
+		return time.Time{}
+	}
+
+	return this.At.Add(this.Duration + this.Interval)
+}
+
+type ObserverStatus struct {
+	Onlines CrawlStatus
+	Stats   CrawlStatus
+}
+
+// observerLoop is the state of a crawling loop, guarded by the observer's
+// mutex. wake is signalled whenever it changes, for the loop to notice.
+// cancel cancels the crawl in progress, if any.
+type observerLoop struct {
+	status CrawlStatus
+	forced bool
+	cancel context.CancelFunc
+	wake   chan struct{}
+}
+
+// Observer crawls the onlines and the stats in loops, publishing the changes
+// of the players on Bus. The loops can be paused, resumed, made to crawl
+// right away and have their intervals changed while running.
 type Observer struct {
 	Bus Bus
 
-	repo           *PlayerRepo
-	crawler        Crawler
-	statsInterval  time.Duration
-	onlineInterval time.Duration
+	repo    *PlayerRepo
+	crawler Crawler
+
+	mutex   sync.Mutex
+	onlines observerLoop
+	stats   observerLoop
 
 	wg sync.WaitGroup
 }
 
+func (this *Observer) Status() ObserverStatus {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	return ObserverStatus{this.onlines.status, this.stats.status}
+}
+
+// CrawlNow cuts the wait for the next crawls short, the ones already in
+// progress finish first.
+func (this *Observer) CrawlNow() {
+	for _, loop := range OBSERVER_LOOPS {
+		this.Crawl(loop)
+	}
+}
+
+// Crawl makes the loop crawl right away, even if it's paused, once the crawl
+// in progress, if any, finishes.
+func (this *Observer) Crawl(loop ObserverLoop) error {
+	return this.update(loop, func(l *observerLoop) {
+		l.forced = true
+	})
+}
+
+// Pause stops the loop from crawling until it's resumed. The onlines crawl in
+// progress finishes, the stats crawl stops after the page in progress.
+func (this *Observer) Pause(loop ObserverLoop) error {
+	return this.update(loop, func(l *observerLoop) {
+		l.status.Paused = true
+		if l.cancel != nil {
+			l.cancel()
+		}
+	})
+}
+
+// Resume lets the loop crawl again, starting with a crawl right away.
+func (this *Observer) Resume(loop ObserverLoop) error {
+	return this.update(loop, func(l *observerLoop) {
+		if l.status.Paused {
+			l.status.Paused = false
+			l.forced = true
+		}
+	})
+}
+
+// SetInterval sets the time between the end of the loop's crawls and the
+// start of the next ones, taking effect on the wait in progress too.
+func (this *Observer) SetInterval(loop ObserverLoop, interval time.Duration) error {
+	if interval < MIN_OBSERVER_INTERVAL || interval > MAX_OBSERVER_INTERVAL {
+		return ERR_INVALID_OBSERVER_INTERVAL
+	}
+
+	return this.update(loop, func(l *observerLoop) {
+		l.status.Interval = interval
+	})
+}
+
+func (this *Observer) loop(loop ObserverLoop) (*observerLoop, error) {
+	switch loop {
+	case OnlinesLoop:
+		return &this.onlines, nil
+	case StatsLoop:
+		return &this.stats, nil
+	default:
+		return nil, ERR_UNKNOWN_OBSERVER_LOOP
+	}
+}
+
+func (this *Observer) update(loop ObserverLoop, f func(*observerLoop)) error {
+	l, err := this.loop(loop)
+	if err != nil {
+		return err
+	}
+
+	this.mutex.Lock()
+	f(l)
+	status := l.status
+	this.mutex.Unlock()
+
+	select {
+	case l.wake <- struct{}{}:
+	default:
+	}
+
+	observerLog.Info(
+		"updated loop",
+		"loop", loop, "paused", status.Paused, "interval", status.Interval,
+	)
+
+	return nil
+}
+
+func (this *Observer) setStatus(l *observerLoop, start time.Time, err error) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	l.status.At = start
+	l.status.Duration = time.Since(start)
+	l.status.Err = err
+}
+
+// waitNext waits for the loop's next crawl, returning the context to crawl
+// with, or nil once ctx is done. Paused loops wait until they're resumed or
+// made to crawl. Deciding to crawl and letting Pause cancel the crawl happen
+// at once, so a pause can't land in between and go unnoticed.
+func (this *Observer) waitNext(
+	ctx context.Context, l *observerLoop,
+) (context.Context, context.CancelFunc) {
+	for ctx.Err() == nil {
+		this.mutex.Lock()
+		forced := l.forced
+		l.forced = false
+		paused := l.status.Paused
+		wait := time.Until(l.status.Next())
+
+		if forced || (!paused && wait <= 0) {
+			crawlCtx, cancel := context.WithCancel(ctx)
+			l.cancel = cancel
+			this.mutex.Unlock()
+
+			return crawlCtx, cancel
+		}
+		this.mutex.Unlock()
+
+		// a nil channel never fires, so paused loops wait for a wake up
+		var next <-chan time.Time
+		var timer *time.Timer
+		if !paused {
+			timer = time.NewTimer(wait)
+			next = timer.C
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-next:
+		case <-l.wake:
+		}
+
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+
+	return nil, nil
+}
+
 func (this *Observer) observeOnlinePlayers() error {
 	players, err := this.crawler.Online()
 	if err != nil {
@@ -42,11 +263,16 @@ func (this *Observer) observeOnlinePlayers() error {
 	for _, player := range players {
 		err := this.handlePlayer(player)
 		if err != nil {
-			log.Printf("observer: onlines: %s", err)
+			observerLog.Error("failed handling online player", "player", player.Name, "err", err)
 			continue
 		}
 	}
 
+	err = this.repo.RecordPopulation(len(players))
+	if err != nil {
+		observerLog.Error("failed recording population", "err", err)
+	}
+
 	return this.handleOnlinePlayers(players)
 }
 
@@ -95,7 +321,7 @@ func (this *Observer) handleOnlinePlayers(areOnlines []Player) error {
 		if isOnline && !wasOnline[id] {
 			err := this.repo.MarkOnline(id)
 			if err != nil {
-				log.Printf("observer: %s", err)
+				observerLog.Error("failed marking online", "player_id", id, "err", err)
 			}
 			this.Bus.Pub(id, GotOnlineTopic)
 		}
@@ -105,7 +331,7 @@ func (this *Observer) handleOnlinePlayers(areOnlines []Player) error {
 		if wasOnline && !isOnline[id] {
 			err := this.repo.MarkOffline(id)
 			if err != nil {
-				log.Printf("observer: %s", err)
+				observerLog.Error("failed marking offline", "player_id", id, "err", err)
 			}
 			this.Bus.Pub(id, GotOfflineTopic)
 		}
@@ -155,7 +381,9 @@ func (this *Observer) observeStatsPage(page int) error {
 	for _, player := range players {
 		err := this.handlePlayer(player)
 		if err != nil {
-			log.Printf("observer: stats: page %d: %s", page, err)
+			observerLog.Error(
+				"failed handling player", "page", page, "player", player.Name, "err", err,
+			)
 			continue
 		}
 	}
@@ -163,72 +391,88 @@ func (this *Observer) observeStatsPage(page int) error {
 	return nil
 }
 
-func (this *Observer) observeStats(ctx context.Context) {
+// observeStats crawls the stats pages, returning the last page's error if
+// any of them failed.
+func (this *Observer) observeStats(ctx context.Context) error {
 	pageCount := 20
 	if os.Getenv("ENV") == "dev" {
 		pageCount = 1
 	}
 
+	var lastErr error
+
 	for page := 1; page <= pageCount; page++ {
 		select {
 		case <-ctx.Done():
-			return
+			return lastErr
 		default:
 			err := this.observeStatsPage(page)
 			if err != nil {
-				log.Printf("observer: stats: page %d: %s", page, err)
+				observerLog.Error("failed observing stats", "page", page, "err", err)
+				lastErr = fmt.Errorf("page %d: %w", page, err)
 			}
 		}
 	}
+
+	return lastErr
 }
 
 func (this *Observer) Start(ctx context.Context) {
-	log.Println("observer: started")
-	defer log.Println("observer: stopped")
+	observerLog.Info("started")
+	defer observerLog.Info("stopped")
 
 	this.wg.Add(2)
 
 	go func() {
-		log.Println("observer: started observing onlines")
 		defer this.wg.Done()
-		defer log.Println("observer: stopped observing onlines")
 
-		for {
-			err := this.observeOnlinePlayers()
-			if err != nil {
-				log.Println(err)
-			}
-
-			select {
-			case <-ctx.Done():
-				return
-			case <-time.After(this.onlineInterval):
-			}
-		}
+		this.run(ctx, OnlinesLoop, &this.onlines, func(context.Context) error {
+			return this.observeOnlinePlayers()
+		})
 	}()
-
 	go func() {
-		log.Println("observer: started observing stats")
 		defer this.wg.Done()
-		defer log.Println("observer: stopped observing stats")
-
-		for {
-			this.observeStats(ctx)
 
-			select {
-			case <-ctx.Done():
-				return
-			case <-time.After(this.statsInterval):
-			}
-		}
+		this.run(ctx, StatsLoop, &this.stats, this.observeStats)
 	}()
 
 	<-ctx.Done()
 	this.stop()
 }
 
+// run crawls in the loop until ctx is done.
+func (this *Observer) run(
+	ctx context.Context,
+	loop ObserverLoop,
+	l *observerLoop,
+	crawl func(context.Context) error,
+) {
+	observerLog.Info("started observing", "loop", loop)
+	defer observerLog.Info("stopped observing", "loop", loop)
+
+	for {
+		crawlCtx, cancel := this.waitNext(ctx, l)
+		if crawlCtx == nil {
+			return
+		}
+
+		start := time.Now()
+		err := crawl(crawlCtx)
+		if err != nil {
+			observerLog.Error("failed observing", "loop", loop, "err", err)
+		}
+
+		this.mutex.Lock()
+		l.cancel = nil
+		this.mutex.Unlock()
+		cancel()
+
+		this.setStatus(l, start, err)
+	}
+}
+
 func (this *Observer) stop() {
-	log.Println("observer: stopping...")
+	observerLog.Info("stopping")
 
 	this.wg.Wait()
 }
@@ -240,9 +484,16 @@ func NewObserver(
 	return &Observer{
 		Bus: pubsub.New[Topic, PlayerId](0),
 
-		repo:           repo,
-		crawler:        crawler,
-		statsInterval:  statsInterval,
-		onlineInterval: onlineInterval,
+		repo:    repo,
+		crawler: crawler,
+
+		onlines: observerLoop{
+			status: CrawlStatus{Interval: onlineInterval},
+			wake:   make(chan struct{}, 1),
+		},
+		stats: observerLoop{
+			status: CrawlStatus{Interval: statsInterval},
+			wake:   make(chan struct{}, 1),
+		},
 	}
 }
@@ -155,3 +155,179 @@ func TestObserverMultipleEvents(t *testing.T) {
 		}
 	}
 }
+
+func TestObserverCrawlNow(t *testing.T) {
+	tof := TestingObserverFactory{}
+	tof.Init(t)
+	defer tof.Deinit()
+
+	tof.Observer = NewObserver(tof.Repo, tof.Crawler, time.Hour, time.Hour)
+
+	ctx, cancel := context.WithTimeout(t.Context(), time.Second*5)
+	defer cancel()
+
+	gotOnline := tof.Observer.Bus.Sub(GotOnlineTopic)
+	defer func() {
+		go tof.Observer.Bus.Unsub(gotOnline)
+		for {
+			select {
+			case <-gotOnline:
+			default:
+				return
+			}
+		}
+	}()
+
+	go tof.Observer.Start(ctx)
+
+	for tof.Observer.Status().Onlines.At.IsZero() {
+		select {
+		case <-ctx.Done():
+			t.Fatal("expected the first crawl to happen")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+
+	status := tof.Observer.Status().Onlines
+	if status.Err != nil {
+		t.Fatalf("unexpected crawl error: %s", status.Err)
+	}
+
+	name := tof.Crawler.AddPlayer()
+	tof.Crawler.MakeOnline(name)
+
+	tof.Observer.CrawlNow()
+
+	select {
+	case <-ctx.Done():
+		t.Fatal("expected crawling now to bring the player online")
+	case <-gotOnline:
+	}
+}
+
+func TestObserverPauseResume(t *testing.T) {
+	tof := TestingObserverFactory{}
+	tof.Init(t)
+	defer tof.Deinit()
+
+	tof.Observer = NewObserver(tof.Repo, tof.Crawler, time.Hour, time.Hour)
+
+	err := tof.Observer.Pause(OnlinesLoop)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(t.Context(), time.Second*5)
+	defer cancel()
+
+	gotOnline := tof.Observer.Bus.Sub(GotOnlineTopic)
+	defer func() {
+		go tof.Observer.Bus.Unsub(gotOnline)
+		for {
+			select {
+			case <-gotOnline:
+			default:
+				return
+			}
+		}
+	}()
+
+	name := tof.Crawler.AddPlayer()
+	tof.Crawler.MakeOnline(name)
+
+	go tof.Observer.Start(ctx)
+
+	select {
+	case <-gotOnline:
+		t.Fatal("expected the paused loop not to crawl")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	status := tof.Observer.Status().Onlines
+	if !status.Paused || !status.At.IsZero() || !status.Next().IsZero() {
+		t.Fatalf("expected the onlines to be paused, got %+v", status)
+	}
+
+	err = tof.Observer.Resume(OnlinesLoop)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-ctx.Done():
+		t.Fatal("expected resuming to crawl right away")
+	case <-gotOnline:
+	}
+}
+
+func TestObserverCrawlWhilePaused(t *testing.T) {
+	tof := TestingObserverFactory{}
+	tof.Init(t)
+	defer tof.Deinit()
+
+	tof.Observer = NewObserver(tof.Repo, tof.Crawler, time.Hour, time.Hour)
+	tof.Observer.Pause(OnlinesLoop)
+
+	ctx, cancel := context.WithTimeout(t.Context(), time.Second*5)
+	defer cancel()
+
+	go tof.Observer.Start(ctx)
+
+	err := tof.Observer.Crawl(OnlinesLoop)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for tof.Observer.Status().Onlines.At.IsZero() {
+		select {
+		case <-ctx.Done():
+			t.Fatal("expected crawling a paused loop to crawl once")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+
+	if !tof.Observer.Status().Onlines.Paused {
+		t.Fatal("expected the loop to stay paused after crawling")
+	}
+}
+
+func TestObserverSetInterval(t *testing.T) {
+	tof := TestingObserverFactory{}
+	tof.Init(t)
+	defer tof.Deinit()
+
+	tests := []struct {
+		loop     ObserverLoop
+		interval time.Duration
+		err      error
+	}{
+		{StatsLoop, time.Minute, nil},
+		{OnlinesLoop, MIN_OBSERVER_INTERVAL, nil},
+		{OnlinesLoop, time.Second, ERR_INVALID_OBSERVER_INTERVAL},
+		{StatsLoop, 48 * time.Hour, ERR_INVALID_OBSERVER_INTERVAL},
+		{"players", time.Minute, ERR_UNKNOWN_OBSERVER_LOOP},
+	}
+
+	for _, test := range tests {
+		err := tof.Observer.SetInterval(test.loop, test.interval)
+		if err != test.err {
+			t.Fatalf(
+				"expected %v setting %s to %s, got %v",
+				test.err, test.loop, test.interval, err,
+			)
+		}
+	}
+
+	status := tof.Observer.Status()
+	if status.Stats.Interval != time.Minute {
+		t.Fatalf("expected the stats every minute, got %s", status.Stats.Interval)
+	}
+	if status.Onlines.Interval != MIN_OBSERVER_INTERVAL {
+		t.Fatalf("expected the onlines every 30s, got %s", status.Onlines.Interval)
+	}
+
+	_, err := ParseObserverLoop("stats")
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -2,8 +2,8 @@ package core
 
 import (
 	"database/sql"
-	"errors"
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -18,7 +18,7 @@ type PlayerRepo struct {
 	Database *sql.DB
 }
 
-var ERR_PLAYER_NOT_FOUND error = errors.New("player not found")
+var ERR_PLAYER_NOT_FOUND error = NewUserError("errors.player_not_found")
 
 func (this *PlayerRepo) AddPlayer(player Player) (PlayerId, error) {
 	insertSQL := `
@@ -41,6 +41,11 @@ func (this *PlayerRepo) AddPlayer(player Player) (PlayerId, error) {
 	}
 
 	id, err := row.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	err = this.recordStats(PlayerId(id), player)
 
 	return PlayerId(id), err
 }
@@ -86,6 +91,43 @@ func (this *PlayerRepo) UpdatePlayer(id PlayerId, player Player) error {
 		player.Deaths, player.Accuracy,
 		id,
 	)
+	if err != nil {
+		return err
+	}
+
+	return this.recordStats(id, player)
+}
+
+// recordStats appends a snapshot of the player's stats into the history,
+// unless nothing has changed since the last one.
+func (this *PlayerRepo) recordStats(id PlayerId, player Player) error {
+	insertSQL := `
+		INSERT INTO player_stats
+			(player_id, time, rank, score, kills, deaths, accuracy)
+		SELECT ?, ?, ?, ?, ?, ?, ?
+		WHERE NOT EXISTS (
+			SELECT 1
+			FROM (
+				SELECT rank, score, kills, deaths, accuracy
+				FROM player_stats
+				WHERE player_id = ?
+				ORDER BY time DESC, id DESC
+				LIMIT 1
+			) AS last
+			WHERE last.rank IS ? AND last.score = ? AND last.kills = ? AND
+				last.deaths = ? AND last.accuracy = ?
+		)
+	`
+
+	_, err := this.Database.Exec(
+		insertSQL,
+		id, time.Now().Unix(),
+		player.Rank, player.Score, player.Kills,
+		player.Deaths, player.Accuracy,
+		id,
+		player.Rank, player.Score, player.Kills,
+		player.Deaths, player.Accuracy,
+	)
 	return err
 }
 
@@ -145,16 +187,62 @@ func (this *PlayerRepo) Onlines() ([]DbPlayer, error) {
 	return players, nil
 }
 
-func (this *PlayerRepo) scanPlayer(rows *sql.Rows) (DbPlayer, error) {
+type OnlineSession struct {
+	DbPlayer DbPlayer
+	Since    time.Time
+}
+
+// OnlineSessions lists the online players along with when they got online.
+func (this *PlayerRepo) OnlineSessions() ([]OnlineSession, error) {
+	rows, err := this.Database.Query(
+		fmt.Sprintf(
+			`SELECT %s, o.start_time
+				FROM onlines AS o
+				INNER JOIN players as player on player.id = o.player_id
+				WHERE o.end_time IS NULL
+				ORDER BY player.rank ASC
+			`,
+			this.getPlayerFields("player."),
+		))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := make([]OnlineSession, 0)
+
+	for rows.Next() {
+		var since int64
+		p, err := this.scanPlayer(rows, &since)
+		if err != nil {
+			return nil, err
+		}
+
+		sessions = append(sessions, OnlineSession{
+			DbPlayer: p,
+			Since:    time.Unix(since, 0),
+		})
+	}
+
+	return sessions, nil
+}
+
+// scanPlayer scans the columns listed by getPlayerFields, followed by any
+// extra selected columns into extra.
+func (this *PlayerRepo) scanPlayer(
+	rows *sql.Rows, extra ...any,
+) (DbPlayer, error) {
 	var p DbPlayer
 	var rank sql.NullInt32
 
-	err := rows.Scan(
+	dest := []any{
 		&p.ID,
 		&p.Player.Name, &p.Player.Country,
 		&rank, &p.Player.Score, &p.Player.Kills,
 		&p.Player.Deaths, &p.Player.Accuracy,
-	)
+	}
+
+	err := rows.Scan(append(dest, extra...)...)
 
 	if rank.Valid {
 		var r int
@@ -267,6 +355,227 @@ func (this *PlayerRepo) List(offset int, limit int) ([]DbPlayer, error) {
 	return players, nil
 }
 
+// SearchByName finds players whose names contain query, ranked players first.
+func (this *PlayerRepo) SearchByName(query string, limit int) ([]DbPlayer, error) {
+	rows, err := this.Database.Query(fmt.Sprintf(`
+		SELECT %s
+		FROM players as p
+		WHERE p.name LIKE '%%' || ? || '%%' ESCAPE '\'
+		ORDER BY p.rank IS NULL, p.rank ASC, p.name ASC
+		LIMIT ?
+	`, this.getPlayerFields("p.")), escapeLike(query), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	players := make([]DbPlayer, 0, 0)
+
+	for rows.Next() {
+		p, err := this.scanPlayer(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		players = append(players, p)
+	}
+
+	return players, nil
+}
+
+// likeEscaper escapes the wildcards of LIKE, so they match themselves.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+type StatsSnapshot struct {
+	Time   time.Time
+	Player Player
+}
+
+// StatsHistory lists the recorded stats of a player since the given time,
+// oldest first. The last snapshot before since is included too, so the
+// history always starts with the stats the player had at that moment.
+func (this *PlayerRepo) StatsHistory(
+	id PlayerId, since time.Time,
+) ([]StatsSnapshot, error) {
+	rows, err := this.Database.Query(`
+		SELECT s.time, s.rank, s.score, s.kills, s.deaths, s.accuracy
+		FROM player_stats AS s
+		WHERE s.player_id = ? AND s.time >= COALESCE(
+			(
+				SELECT MAX(b.time) FROM player_stats AS b
+				WHERE b.player_id = ? AND b.time <= ?
+			),
+			?
+		)
+		ORDER BY s.time ASC, s.id ASC
+	`, id, id, since.Unix(), since.Unix())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	snapshots := make([]StatsSnapshot, 0)
+
+	for rows.Next() {
+		var s StatsSnapshot
+		var t int64
+		var rank sql.NullInt32
+
+		err := rows.Scan(
+			&t, &rank, &s.Player.Score, &s.Player.Kills,
+			&s.Player.Deaths, &s.Player.Accuracy,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		s.Time = time.Unix(t, 0)
+		if rank.Valid {
+			r := int(rank.Int32)
+			s.Player.Rank = &r
+		}
+
+		snapshots = append(snapshots, s)
+	}
+
+	return snapshots, nil
+}
+
+// Playtime sums up the time a player spent online since the given time,
+// counting the ongoing session up to now.
+func (this *PlayerRepo) Playtime(
+	id PlayerId, since time.Time,
+) (time.Duration, error) {
+	now := time.Now().Unix()
+
+	var seconds sql.NullInt64
+	err := this.Database.QueryRow(`
+		SELECT SUM(COALESCE(o.end_time, ?) - MAX(o.start_time, ?))
+		FROM onlines AS o
+		WHERE o.player_id = ? AND COALESCE(o.end_time, ?) > ?
+	`, now, since.Unix(), id, now, since.Unix()).Scan(&seconds)
+	if err != nil {
+		return 0, err
+	}
+
+	return time.Duration(seconds.Int64) * time.Second, nil
+}
+
+type CountryCount struct {
+	Country string
+	Count   int
+}
+
+// Countries lists the countries of ranked players, the most populated first.
+func (this *PlayerRepo) Countries() ([]CountryCount, error) {
+	rows, err := this.Database.Query(`
+		SELECT p.country, COUNT(*) AS count
+		FROM players as p
+		WHERE p.rank IS NOT NULL AND p.country != ''
+		GROUP BY p.country
+		ORDER BY count DESC, p.country ASC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	countries := make([]CountryCount, 0)
+
+	for rows.Next() {
+		var c CountryCount
+		err := rows.Scan(&c.Country, &c.Count)
+		if err != nil {
+			return nil, err
+		}
+
+		countries = append(countries, c)
+	}
+
+	return countries, nil
+}
+
+func (this *PlayerRepo) ListByCountry(
+	country string, offset int, limit int,
+) ([]DbPlayer, error) {
+	rows, err := this.Database.Query(fmt.Sprintf(`
+		SELECT %s
+		FROM players as p
+		WHERE p.rank IS NOT NULL AND p.country = ?
+		ORDER BY p.rank ASC
+		LIMIT ? OFFSET ?
+	`, this.getPlayerFields("p.")), country, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	players := make([]DbPlayer, 0, 0)
+
+	for rows.Next() {
+		p, err := this.scanPlayer(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		players = append(players, p)
+	}
+
+	return players, nil
+}
+
+// migrateCountries rewrites countries stored as raw flag image sources, from
+// before the crawler normalized them, into ISO codes.
+func migrateCountries(db *sql.DB) error {
+	rows, err := db.Query(`
+		SELECT id, country
+		FROM players
+		WHERE country IS NOT NULL AND length(country) != 2 AND country != ''
+	`)
+	if err != nil {
+		return err
+	}
+
+	countries := make(map[PlayerId]string)
+	for rows.Next() {
+		var id PlayerId
+		var src string
+		err := rows.Scan(&id, &src)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+
+		countries[id] = ParseCountry(src)
+	}
+	rows.Close()
+
+	if len(countries) == 0 {
+		return nil
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for id, country := range countries {
+		_, err := tx.Exec(
+			`UPDATE players SET country = ? WHERE id = ?`, country, id,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
 func CreatePlayerRepo(db *sql.DB) (*PlayerRepo, error) {
 	createPlayersStatsTable := `CREATE TABLE IF NOT EXISTS players (
 		id INTEGER PRIMARY KEY AUTOINCREMENT,
@@ -330,5 +639,56 @@ func CreatePlayerRepo(db *sql.DB) (*PlayerRepo, error) {
 		return nil, err
 	}
 
+	createPlayerStatsTable := `
+		CREATE TABLE IF NOT EXISTS player_stats (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			player_id INTEGER NOT NULL,
+			time INTEGER NOT NULL,
+			rank INTEGER,
+			score INTEGER,
+			kills INTEGER,
+			deaths INTEGER,
+			accuracy INTEGER,
+			FOREIGN KEY (player_id) REFERENCES players(id) ON DELETE RESTRICT
+		);`
+	_, err = db.Exec(createPlayerStatsTable)
+	if err != nil {
+		return nil, err
+	}
+
+	createPlayerStatsTimeIndex := `
+		CREATE INDEX IF NOT EXISTS idx_player_stats_time
+		ON player_stats(player_id, time)
+	`
+	_, err = db.Exec(createPlayerStatsTimeIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	createPopulationTable := `
+		CREATE TABLE IF NOT EXISTS population (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			time INTEGER NOT NULL,
+			count INTEGER NOT NULL
+		);`
+	_, err = db.Exec(createPopulationTable)
+	if err != nil {
+		return nil, err
+	}
+
+	createPopulationTimeIndex := `
+		CREATE INDEX IF NOT EXISTS idx_population_time
+		ON population(time)
+	`
+	_, err = db.Exec(createPopulationTimeIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	err = migrateCountries(db)
+	if err != nil {
+		return nil, err
+	}
+
 	return &PlayerRepo{Database: db}, nil
 }
@@ -1,7 +1,9 @@
 package core
 
 import (
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/thekhanj/csdmpro/db"
 )
@@ -93,6 +95,15 @@ func TestPlayerRepoOnline(t *testing.T) {
 		t.Fatal("number of online players must be 1")
 	}
 
+	sessions, err := repo.OnlineSessions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 1 || sessions[0].DbPlayer.ID != playerId ||
+		time.Since(sessions[0].Since) > time.Minute {
+		t.Fatalf("unexpected online sessions %+v", sessions)
+	}
+
 	err = repo.MarkOffline(p.ID)
 	if err != nil {
 		t.Fatal(err)
@@ -106,3 +117,245 @@ func TestPlayerRepoOnline(t *testing.T) {
 		t.Fatal("player should not be online after removing it from database")
 	}
 }
+
+func TestPlayerRepoCountries(t *testing.T) {
+	f := db.FakeDbFactory{}
+	db, err := f.Init()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Deinit()
+
+	repo, err := CreatePlayerRepo(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ranks := []int{1, 2, 3}
+	countries := []string{"/images/flags/ir.png", "/images/flags/ir.png", "/images/flags/de.png"}
+	for i := range ranks {
+		_, err := repo.AddPlayer(Player{
+			Name:    fmt.Sprintf("player-%d", i),
+			Country: countries[i],
+			Rank:    &ranks[i],
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	repo, err = CreatePlayerRepo(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cs, err := repo.Countries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cs) != 2 || cs[0].Country != "IR" || cs[0].Count != 2 {
+		t.Fatalf("unexpected countries after migration: %v", cs)
+	}
+
+	players, err := repo.ListByCountry("DE", 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(players) != 1 || players[0].Player.Name != "player-2" {
+		t.Fatalf("expected player-2 to be the only german player")
+	}
+}
+
+func TestPlayerRepoLeaderboard(t *testing.T) {
+	f := db.FakeDbFactory{}
+	db, err := f.Init()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Deinit()
+
+	repo, err := CreatePlayerRepo(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ranks := []int{1, 2, 3}
+	players := []Player{
+		{Name: "veteran", Rank: &ranks[0], Score: 900, Kills: 1000, Deaths: 500},
+		{Name: "rookie", Rank: &ranks[1], Score: 100, Kills: 10, Deaths: 1},
+		{Name: "grinder", Rank: &ranks[2], Score: 500, Kills: 600, Deaths: 600},
+	}
+	ids := make([]PlayerId, len(players))
+	for i, p := range players {
+		ids[i], err = repo.AddPlayer(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries, err := repo.Leaderboard(MetricKD, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected rookie to be filtered out, got %d entries", len(entries))
+	}
+	if entries[0].DbPlayer.Player.Name != "veteran" || entries[0].Value != 2 {
+		t.Fatalf("expected veteran to lead with k/d of 2")
+	}
+
+	yesterday := StartOfDay(time.Now()).Add(-time.Hour).Unix()
+	_, err = db.Exec(`UPDATE player_stats SET time = ?`, yesterday)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	grinder := players[2]
+	grinder.Score += 300
+	err = repo.UpdatePlayer(ids[2], grinder)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err = repo.Leaderboard(MetricScoreToday, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].DbPlayer.ID != ids[2] {
+		t.Fatalf("expected grinder to be the only one gaining score today")
+	}
+	if entries[0].Value != 300 {
+		t.Fatalf("expected grinder to gain 300 score, got %f", entries[0].Value)
+	}
+
+	_, err = repo.Leaderboard(Metric("nonsense"), 0, 10)
+	if err != ERR_UNKNOWN_METRIC {
+		t.Fatalf("expected unknown metric error, got %v", err)
+	}
+}
+
+func TestPlayerRepoCompare(t *testing.T) {
+	f := db.FakeDbFactory{}
+	db, err := f.Init()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Deinit()
+
+	repo, err := CreatePlayerRepo(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ranks := []int{5, 2}
+	a, err := repo.AddPlayer(Player{
+		Name: "climber", Rank: &ranks[0], Score: 100, Kills: 50, Deaths: 10,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := repo.AddPlayer(Player{
+		Name: "camper", Rank: &ranks[1], Score: 200, Kills: 20, Deaths: 20,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	weekAgo := time.Now().Add(-time.Hour * 24 * 7)
+	_, err = db.Exec(`UPDATE player_stats SET time = ?`, weekAgo.Unix()-60)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newRank := 1
+	err = repo.UpdatePlayer(a, Player{
+		Name: "climber", Rank: &newRank, Score: 300, Kills: 60, Deaths: 10,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = db.Exec(
+		`INSERT INTO onlines (player_id, start_time, end_time) VALUES (?, ?, ?)`,
+		b, time.Now().Add(-time.Hour).Unix(), time.Now().Unix(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := repo.Compare(a, b, weekAgo)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c.Players[0].RankChange != 4 {
+		t.Fatalf("expected climber to climb 4 places, got %d", c.Players[0].RankChange)
+	}
+	if c.Players[1].Playtime != time.Hour {
+		t.Fatalf("expected camper to play for an hour, got %s", c.Players[1].Playtime)
+	}
+
+	leaders := map[string]int{}
+	for _, m := range c.Metrics() {
+		leaders[m.Name] = m.Leader()
+	}
+
+	if leaders[ComparedRank] != 0 {
+		t.Fatal("expected climber to lead in rank")
+	}
+	if leaders[ComparedPlaytime] != 1 {
+		t.Fatal("expected camper to lead in playtime")
+	}
+	if leaders[ComparedAccuracy] != -1 {
+		t.Fatal("expected a tie in accuracy")
+	}
+}
+
+func TestPlayerRepoSearchByName(t *testing.T) {
+	f := db.FakeDbFactory{}
+	db, err := f.Init()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Deinit()
+
+	repo, err := CreatePlayerRepo(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"thekhanj", "s1mple", "100%_aim", `back\slash`} {
+		_, err := repo.AddPlayer(Player{Name: name, Country: "iran"})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{"khan", []string{"thekhanj"}},
+		{"%", []string{"100%_aim"}},
+		{"_", []string{"100%_aim"}},
+		{"%_", []string{"100%_aim"}},
+		{`\`, []string{`back\slash`}},
+		{"s_mple", []string{}},
+	}
+
+	for _, test := range tests {
+		players, err := repo.SearchByName(test.query, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		names := make([]string, 0, len(players))
+		for _, p := range players {
+			names = append(names, p.Player.Name)
+		}
+
+		if fmt.Sprint(names) != fmt.Sprint(test.expected) {
+			t.Fatalf("expected %q to find %v, got %v", test.query, test.expected, names)
+		}
+	}
+}
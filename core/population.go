@@ -0,0 +1,179 @@
+package core
+
+import "time"
+
+func (this *PlayerRepo) RecordPopulation(count int) error {
+	insertSQL := `INSERT INTO population (time, count) VALUES (?, ?)`
+	_, err := this.Database.Exec(insertSQL, time.Now().Unix(), count)
+	return err
+}
+
+type PopulationSample struct {
+	Time  time.Time
+	Count int
+}
+
+func (this *PlayerRepo) PopulationSamples(
+	since time.Time,
+) ([]PopulationSample, error) {
+	rows, err := this.Database.Query(`
+		SELECT time, count
+		FROM population
+		WHERE time >= ?
+		ORDER BY time ASC
+	`, since.Unix())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	samples := make([]PopulationSample, 0)
+
+	for rows.Next() {
+		var s PopulationSample
+		var t int64
+
+		err := rows.Scan(&t, &s.Count)
+		if err != nil {
+			return nil, err
+		}
+
+		s.Time = time.Unix(t, 0)
+		samples = append(samples, s)
+	}
+
+	return samples, nil
+}
+
+// AllSessions lists the online sessions of every player that were still
+// going on at since or started after it.
+func (this *PlayerRepo) AllSessions(since time.Time) ([]Session, error) {
+	rows, err := this.Database.Query(`
+		SELECT o.player_id, o.start_time, o.end_time
+		FROM onlines AS o
+		WHERE o.end_time IS NULL OR o.end_time > ?
+		ORDER BY o.start_time ASC
+	`, since.Unix())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return this.scanSessions(rows)
+}
+
+type PopulationStats struct {
+	// HourlyAverage is the average number of online players in each hour of
+	// the day.
+	HourlyAverage     [24]float64
+	AverageConcurrent float64
+	Peak              PopulationSample
+	// DailyUniques counts distinct players seen online on each day, the
+	// oldest day first.
+	DailyUniques []int
+}
+
+// BestHour is the hour of the day with the most players online on average.
+func (this *PopulationStats) BestHour() int {
+	best := 0
+	for hour, avg := range this.HourlyAverage {
+		if avg > this.HourlyAverage[best] {
+			best = hour
+		}
+	}
+
+	return best
+}
+
+// PeakHours lists the hours of the day in which the average number of online
+// players is at least ratio of the best hour's.
+func (this *PopulationStats) PeakHours(ratio float64) []int {
+	threshold := this.HourlyAverage[this.BestHour()] * ratio
+
+	hours := make([]int, 0)
+	for hour, avg := range this.HourlyAverage {
+		if avg > 0 && avg >= threshold {
+			hours = append(hours, hour)
+		}
+	}
+
+	return hours
+}
+
+func (this *PlayerRepo) PopulationStats(
+	days int, loc *time.Location,
+) (PopulationStats, error) {
+	now := time.Now()
+	since := StartOfDay(now.In(loc)).AddDate(0, 0, -(days - 1))
+
+	samples, err := this.PopulationSamples(since)
+	if err != nil {
+		return PopulationStats{}, err
+	}
+
+	sessions, err := this.AllSessions(since)
+	if err != nil {
+		return PopulationStats{}, err
+	}
+
+	return BuildPopulationStats(samples, sessions, days, now, loc), nil
+}
+
+func BuildPopulationStats(
+	samples []PopulationSample, sessions []Session,
+	days int, now time.Time, loc *time.Location,
+) PopulationStats {
+	var stats PopulationStats
+
+	var hourlySum [24]int
+	var hourlyCount [24]int
+	total := 0
+
+	for _, s := range samples {
+		hour := s.Time.In(loc).Hour()
+		hourlySum[hour] += s.Count
+		hourlyCount[hour]++
+		total += s.Count
+
+		if s.Count > stats.Peak.Count {
+			stats.Peak = s
+		}
+	}
+
+	for hour := range hourlySum {
+		if hourlyCount[hour] != 0 {
+			stats.HourlyAverage[hour] =
+				float64(hourlySum[hour]) / float64(hourlyCount[hour])
+		}
+	}
+	if len(samples) != 0 {
+		stats.AverageConcurrent = float64(total) / float64(len(samples))
+	}
+
+	first := StartOfDay(now.In(loc)).AddDate(0, 0, -(days - 1))
+	uniques := make([]map[PlayerId]bool, days)
+	for day := range uniques {
+		uniques[day] = make(map[PlayerId]bool)
+	}
+
+	for _, s := range sessions {
+		start := s.Start.In(loc)
+		end := s.EndOr(now).In(loc)
+
+		for day := 0; day < days; day++ {
+			dayStart := first.AddDate(0, 0, day)
+			dayEnd := dayStart.AddDate(0, 0, 1)
+
+			if start.Before(dayEnd) && end.After(dayStart) {
+				uniques[day][s.PlayerId] = true
+			}
+		}
+	}
+
+	stats.DailyUniques = make([]int, days)
+	for day, players := range uniques {
+		stats.DailyUniques[day] = len(players)
+	}
+
+	return stats
+}
@@ -0,0 +1,43 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBuildPopulationStats(t *testing.T) {
+	loc := time.UTC
+	now := time.Date(2025, 4, 10, 23, 0, 0, 0, loc)
+
+	samples := []PopulationSample{
+		{Time: time.Date(2025, 4, 9, 21, 0, 0, 0, loc), Count: 10},
+		{Time: time.Date(2025, 4, 9, 21, 30, 0, 0, loc), Count: 20},
+		{Time: time.Date(2025, 4, 10, 9, 0, 0, 0, loc), Count: 3},
+		{Time: time.Date(2025, 4, 10, 21, 0, 0, 0, loc), Count: 12},
+	}
+
+	end := time.Date(2025, 4, 9, 22, 0, 0, 0, loc)
+	sessions := []Session{
+		{PlayerId: 1, Start: time.Date(2025, 4, 9, 20, 0, 0, 0, loc), End: &end},
+		{PlayerId: 2, Start: time.Date(2025, 4, 9, 21, 0, 0, 0, loc), End: &end},
+		{PlayerId: 1, Start: time.Date(2025, 4, 10, 21, 0, 0, 0, loc)},
+	}
+
+	stats := BuildPopulationStats(samples, sessions, 2, now, loc)
+
+	if stats.Peak.Count != 20 {
+		t.Fatalf("expected peak of 20, got %d", stats.Peak.Count)
+	}
+	if stats.HourlyAverage[21] != 14 {
+		t.Fatalf("expected 14 players on average at 21, got %f", stats.HourlyAverage[21])
+	}
+	if stats.BestHour() != 21 {
+		t.Fatalf("expected 21 to be the best hour, got %d", stats.BestHour())
+	}
+	if stats.AverageConcurrent != 11.25 {
+		t.Fatalf("expected 11.25 average players, got %f", stats.AverageConcurrent)
+	}
+	if stats.DailyUniques[0] != 2 || stats.DailyUniques[1] != 1 {
+		t.Fatalf("unexpected daily unique players %v", stats.DailyUniques)
+	}
+}
@@ -0,0 +1,196 @@
+package core
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+var ERR_SESSION_NOT_FOUND error = errors.New("session not found")
+
+type Session struct {
+	PlayerId PlayerId
+	Start    time.Time
+	// End is nil while the session is still going on.
+	End *time.Time
+}
+
+// EndOr returns the end of the session, or t if it's still going on.
+func (this Session) EndOr(t time.Time) time.Time {
+	if this.End == nil {
+		return t
+	}
+
+	return *this.End
+}
+
+func (this Session) Duration() time.Duration {
+	return this.EndOr(time.Now()).Sub(this.Start)
+}
+
+// Sessions lists the online sessions of a player that were still going on
+// at since or started after it, oldest first.
+func (this *PlayerRepo) Sessions(
+	id PlayerId, since time.Time,
+) ([]Session, error) {
+	rows, err := this.Database.Query(`
+		SELECT o.player_id, o.start_time, o.end_time
+		FROM onlines AS o
+		WHERE o.player_id = ? AND (o.end_time IS NULL OR o.end_time > ?)
+		ORDER BY o.start_time ASC
+	`, id, since.Unix())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return this.scanSessions(rows)
+}
+
+// LastSession returns the latest session of a player, ended or not.
+func (this *PlayerRepo) LastSession(id PlayerId) (Session, error) {
+	rows, err := this.Database.Query(`
+		SELECT o.player_id, o.start_time, o.end_time
+		FROM onlines AS o
+		WHERE o.player_id = ?
+		ORDER BY o.start_time DESC, o.id DESC
+		LIMIT 1
+	`, id)
+	if err != nil {
+		return Session{}, err
+	}
+	defer rows.Close()
+
+	sessions, err := this.scanSessions(rows)
+	if err != nil {
+		return Session{}, err
+	}
+	if len(sessions) == 0 {
+		return Session{}, ERR_SESSION_NOT_FOUND
+	}
+
+	return sessions[0], nil
+}
+
+// SessionReport is how a player's stats changed over a session.
+type SessionReport struct {
+	Session Session
+	// Before is the player's stats as the session started.
+	Before Player
+	After  Player
+}
+
+func (this SessionReport) ScoreGain() int {
+	return this.After.Score - this.Before.Score
+}
+
+func (this SessionReport) KillsGain() int {
+	return this.After.Kills - this.Before.Kills
+}
+
+// RankChange is how many places the player climbed, negative when dropped.
+// It's not known unless the player was ranked on both ends.
+func (this SessionReport) RankChange() (int, bool) {
+	if this.Before.Rank == nil || this.After.Rank == nil {
+		return 0, false
+	}
+
+	return *this.Before.Rank - *this.After.Rank, true
+}
+
+// LastSessionReport reports the player's latest session. The stats are
+// compared up to now rather than the session's end, as they're crawled
+// separately and may be updated only after the player gets offline.
+func (this *PlayerRepo) LastSessionReport(id PlayerId) (SessionReport, error) {
+	session, err := this.LastSession(id)
+	if err != nil {
+		return SessionReport{}, err
+	}
+
+	p, err := this.GetPlayer(id)
+	if err != nil {
+		return SessionReport{}, err
+	}
+
+	history, err := this.StatsHistory(id, session.Start)
+	if err != nil {
+		return SessionReport{}, err
+	}
+
+	report := SessionReport{
+		Session: session,
+		Before:  p.Player,
+		After:   p.Player,
+	}
+	if len(history) != 0 && !history[0].Time.After(session.Start) {
+		report.Before = history[0].Player
+	}
+
+	return report, nil
+}
+
+func (this *PlayerRepo) scanSessions(rows *sql.Rows) ([]Session, error) {
+	sessions := make([]Session, 0)
+
+	for rows.Next() {
+		var s Session
+		var start int64
+		var end sql.NullInt64
+
+		err := rows.Scan(&s.PlayerId, &start, &end)
+		if err != nil {
+			return nil, err
+		}
+
+		s.Start = time.Unix(start, 0)
+		if end.Valid {
+			e := time.Unix(end.Int64, 0)
+			s.End = &e
+		}
+
+		sessions = append(sessions, s)
+	}
+
+	return sessions, nil
+}
+
+// DailyPlaytime splits the sessions into the days of loc, returning the time
+// spent online on each of the given number of days ending today.
+func DailyPlaytime(
+	sessions []Session, days int, now time.Time, loc *time.Location,
+) []time.Duration {
+	ret := make([]time.Duration, days)
+	first := StartOfDay(now.In(loc)).AddDate(0, 0, -(days - 1))
+
+	for _, s := range sessions {
+		start := s.Start.In(loc)
+		end := s.EndOr(now).In(loc)
+
+		for day := 0; day < days; day++ {
+			dayStart := first.AddDate(0, 0, day)
+			dayEnd := dayStart.AddDate(0, 0, 1)
+
+			from := maxTime(start, dayStart)
+			to := minTime(end, dayEnd)
+			if to.After(from) {
+				ret[day] += to.Sub(from)
+			}
+		}
+	}
+
+	return ret
+}
+
+func maxTime(a time.Time, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+func minTime(a time.Time, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
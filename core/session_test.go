@@ -0,0 +1,92 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/thekhanj/csdmpro/db"
+)
+
+func TestDailyPlaytime(t *testing.T) {
+	loc := time.UTC
+	now := time.Date(2025, 4, 10, 12, 0, 0, 0, loc)
+
+	end := time.Date(2025, 4, 9, 1, 0, 0, 0, loc)
+	sessions := []Session{
+		// crosses midnight, an hour on each of the 8th and the 9th
+		{Start: time.Date(2025, 4, 8, 23, 0, 0, 0, loc), End: &end},
+		// still online
+		{Start: time.Date(2025, 4, 10, 11, 30, 0, 0, loc)},
+	}
+
+	daily := DailyPlaytime(sessions, 3, now, loc)
+	expected := []time.Duration{time.Hour, time.Hour, time.Minute * 30}
+
+	for i := range expected {
+		if daily[i] != expected[i] {
+			t.Fatalf("day %d: expected %s, got %s", i, expected[i], daily[i])
+		}
+	}
+}
+
+func TestPlayerRepoLastSessionReport(t *testing.T) {
+	f := db.FakeDbFactory{}
+	db, err := f.Init()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Deinit()
+
+	repo, err := CreatePlayerRepo(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rank := 5
+	id, err := repo.AddPlayer(Player{Name: "thekhanj", Rank: &rank})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = repo.LastSessionReport(id)
+	if err != ERR_SESSION_NOT_FOUND {
+		t.Fatalf("expected no session before getting online, got %v", err)
+	}
+
+	now := time.Now()
+	_, err = db.Exec(`
+		INSERT INTO player_stats
+			(player_id, time, rank, score, kills, deaths, accuracy)
+		VALUES (?, ?, 5, 100, 10, 5, 30);
+		INSERT INTO onlines (player_id, start_time, end_time) VALUES (?, ?, ?);
+	`, id, now.Add(-2*time.Hour).Unix(), id, now.Add(-time.Hour).Unix(), now.Unix())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newRank := 2
+	err = repo.UpdatePlayer(id, Player{
+		Name: "thekhanj", Rank: &newRank, Score: 160, Kills: 25, Deaths: 8,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := repo.LastSessionReport(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.Session.Duration() != time.Hour {
+		t.Fatalf("expected an hour long session, got %s", report.Session.Duration())
+	}
+	if report.ScoreGain() != 60 || report.KillsGain() != 15 {
+		t.Fatalf(
+			"expected +60 score and +15 kills, got %+d and %+d",
+			report.ScoreGain(), report.KillsGain(),
+		)
+	}
+	if change, ok := report.RankChange(); !ok || change != 3 {
+		t.Fatalf("expected climbing 3 places, got %d", change)
+	}
+}
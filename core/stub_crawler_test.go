@@ -15,6 +15,16 @@ type internalPlayer struct {
 	player   Player
 }
 
+// copy copies the player, the observer reads the rank while players are
+// being reranked.
+func (this *internalPlayer) copy() Player {
+	p := this.player
+	rank := *p.Rank
+	p.Rank = &rank
+
+	return p
+}
+
 type StubCrawler struct {
 	mutex   sync.Mutex
 	players map[string]*internalPlayer
@@ -98,7 +108,7 @@ func (this *StubCrawler) Stats(page int) ([]Player, error) {
 	ret := make([]Player, 0)
 
 	for _, p := range this.players {
-		ret = append(ret, p.player)
+		ret = append(ret, p.copy())
 	}
 
 	sort.Slice(ret, func(i, j int) bool {
@@ -123,7 +133,7 @@ func (this *StubCrawler) Online() ([]Player, error) {
 
 	for _, p := range this.players {
 		if p.isOnline {
-			ret = append(ret, p.player)
+			ret = append(ret, p.copy())
 		}
 	}
 
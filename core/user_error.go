@@ -0,0 +1,32 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+)
+
+// UserError is an error telling users what they did wrong, it's shown to them
+// in their language by the text of its key, formatted with its args. Any
+// other error is a failure of the bot.
+type UserError struct {
+	Key  string
+	Args []any
+}
+
+func (this *UserError) Error() string {
+	if len(this.Args) == 0 {
+		return this.Key
+	}
+
+	return fmt.Sprintf("%s %v", this.Key, this.Args)
+}
+
+func NewUserError(key string, args ...any) error {
+	return &UserError{key, args}
+}
+
+// IsUserError tells whether err, or any error it wraps, is a UserError.
+func IsUserError(err error) bool {
+	var userErr *UserError
+	return errors.As(err, &userErr)
+}
@@ -2,18 +2,20 @@ package db
 
 import (
 	"database/sql"
-	"log"
 
 	"github.com/google/wire"
 	_ "github.com/mattn/go-sqlite3"
+	"github.com/thekhanj/csdmpro/logging"
 )
 
+var dbLog = logging.Component("db")
+
 type Database *sql.DB
 
 func ProvideDb() Database {
 	db, err := OpenDb("database.db")
 	if err != nil {
-		log.Fatal(err)
+		logging.Fatal(dbLog, "failed opening database", "err", err)
 	}
 	return db
 }
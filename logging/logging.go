@@ -0,0 +1,172 @@
+// Package logging sets up the structured logs of the bot. Every component
+// logs through its own logger, tagged with the component's name, whose level
+// can be changed while running.
+package logging
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+var ERR_INVALID_FORMAT error = errors.New("log format must be text or json")
+
+const (
+	FORMAT_TEXT = "text"
+	FORMAT_JSON = "json"
+)
+
+// STD_LEVELS are the levels of the standard log's lines starting with the
+// prefixes. tgool's routing and tgbotapi's debug lines are chatter, any other
+// line is logged at warn, as that's how tgbotapi reports failing to reach
+// telegram.
+var STD_LEVELS = []struct {
+	Prefix string
+	Level  slog.Level
+}{
+	{"tgool: ", slog.LevelDebug},
+	{"Endpoint: ", slog.LevelDebug},
+	{"Stopping the update receiver", slog.LevelInfo},
+}
+
+// LEVELS are the levels that make sense to switch between.
+var LEVELS = []slog.Level{
+	slog.LevelDebug, slog.LevelInfo, slog.LevelWarn, slog.LevelError,
+}
+
+var level = new(slog.LevelVar)
+
+// Setup writes the logs to w in the format, logging whatever is at least of
+// the level. Logs of the standard log package, which the libraries use, are
+// logged at the levels of STD_LEVELS.
+func Setup(w io.Writer, format string, lvl slog.Level) error {
+	opts := &slog.HandlerOptions{Level: level}
+
+	var handler slog.Handler
+	switch format {
+	case FORMAT_TEXT, "":
+		handler = slog.NewTextHandler(w, opts)
+	case FORMAT_JSON:
+		handler = slog.NewJSONHandler(w, opts)
+	default:
+		return ERR_INVALID_FORMAT
+	}
+
+	level.Set(lvl)
+	slog.SetDefault(slog.New(handler))
+
+	log.SetFlags(0)
+	log.SetOutput(&stdWriter{Component("std")})
+
+	return nil
+}
+
+// SetupFromEnv sets up the logs using LOG_FORMAT and LOG_LEVEL, logging
+// text at the info level by default.
+func SetupFromEnv() error {
+	lvl := slog.LevelInfo
+	if s := os.Getenv("LOG_LEVEL"); s != "" {
+		var err error
+		lvl, err = ParseLevel(s)
+		if err != nil {
+			return err
+		}
+	}
+
+	return Setup(os.Stderr, os.Getenv("LOG_FORMAT"), lvl)
+}
+
+func ParseLevel(s string) (slog.Level, error) {
+	var lvl slog.Level
+	err := lvl.UnmarshalText([]byte(s))
+
+	return lvl, err
+}
+
+func Level() slog.Level {
+	return level.Level()
+}
+
+func SetLevel(lvl slog.Level) {
+	level.Set(lvl)
+}
+
+// Component is the logger of a component of the bot. It logs through
+// whatever is set up at the time of logging, so it's fine to make one
+// before Setup.
+func Component(name string) *slog.Logger {
+	return slog.New(&componentHandler{}).With("component", name)
+}
+
+// Fatal logs the error and exits.
+func Fatal(logger *slog.Logger, msg string, args ...any) {
+	logger.Error(msg, args...)
+	os.Exit(1)
+}
+
+// componentHandler hands the records to the default handler, along with the
+// attributes and groups it got on the way.
+type componentHandler struct {
+	ops []func(slog.Handler) slog.Handler
+}
+
+func (this *componentHandler) handler() slog.Handler {
+	h := slog.Default().Handler()
+	for _, op := range this.ops {
+		h = op(h)
+	}
+
+	return h
+}
+
+func (this *componentHandler) Enabled(ctx context.Context, lvl slog.Level) bool {
+	return slog.Default().Handler().Enabled(ctx, lvl)
+}
+
+func (this *componentHandler) Handle(ctx context.Context, r slog.Record) error {
+	return this.handler().Handle(ctx, r)
+}
+
+func (this *componentHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return this.with(func(h slog.Handler) slog.Handler { return h.WithAttrs(attrs) })
+}
+
+func (this *componentHandler) WithGroup(name string) slog.Handler {
+	return this.with(func(h slog.Handler) slog.Handler { return h.WithGroup(name) })
+}
+
+func (this *componentHandler) with(
+	op func(slog.Handler) slog.Handler,
+) slog.Handler {
+	ops := make([]func(slog.Handler) slog.Handler, 0, len(this.ops)+1)
+	ops = append(ops, this.ops...)
+	ops = append(ops, op)
+
+	return &componentHandler{ops}
+}
+
+// stdWriter logs the lines of the standard log package.
+type stdWriter struct {
+	logger *slog.Logger
+}
+
+func (this *stdWriter) Write(p []byte) (int, error) {
+	msg := strings.TrimSpace(string(p))
+	this.logger.Log(context.Background(), stdLevel(msg), msg)
+
+	return len(p), nil
+}
+
+func stdLevel(msg string) slog.Level {
+	for _, l := range STD_LEVELS {
+		if strings.HasPrefix(msg, l.Prefix) {
+			return l.Level
+		}
+	}
+
+	return slog.LevelWarn
+}
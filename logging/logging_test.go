@@ -0,0 +1,119 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+)
+
+// setup sets up the logs into the returned buffer, undoing it once the test
+// is done.
+func setup(t *testing.T, format string, lvl slog.Level) *bytes.Buffer {
+	defaultLogger := slog.Default()
+	t.Cleanup(func() {
+		slog.SetDefault(defaultLogger)
+		log.SetOutput(os.Stderr)
+		log.SetFlags(log.LstdFlags)
+	})
+
+	buf := &bytes.Buffer{}
+	err := Setup(buf, format, lvl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return buf
+}
+
+func TestComponentLogsThroughLaterSetup(t *testing.T) {
+	logger := Component("notifier").With("chat_id", 42)
+
+	buf := setup(t, FORMAT_JSON, slog.LevelInfo)
+
+	logger.Info("sent", "player", "s1mple")
+
+	var record map[string]any
+	err := json.Unmarshal(buf.Bytes(), &record)
+	if err != nil {
+		t.Fatalf("expected a json record, got %q", buf.String())
+	}
+
+	expected := map[string]any{
+		"level":     "INFO",
+		"msg":       "sent",
+		"component": "notifier",
+		"chat_id":   float64(42),
+		"player":    "s1mple",
+	}
+	for k, v := range expected {
+		if record[k] != v {
+			t.Fatalf("expected %s to be %v, got %v", k, v, record[k])
+		}
+	}
+}
+
+func TestSetLevel(t *testing.T) {
+	buf := setup(t, FORMAT_TEXT, slog.LevelWarn)
+	logger := Component("observer")
+
+	logger.Info("hidden")
+	if buf.Len() != 0 {
+		t.Fatalf("expected info to be hidden at warn, got %q", buf.String())
+	}
+
+	SetLevel(slog.LevelDebug)
+	if Level() != slog.LevelDebug {
+		t.Fatalf("expected the level to be debug, got %s", Level())
+	}
+
+	logger.Debug("shown")
+	if !strings.Contains(buf.String(), "msg=shown") {
+		t.Fatalf("expected debug to be shown, got %q", buf.String())
+	}
+}
+
+func TestStdLogLevels(t *testing.T) {
+	buf := setup(t, FORMAT_TEXT, slog.LevelInfo)
+
+	log.Println("tgool: handling new message")
+	if buf.Len() != 0 {
+		t.Fatalf("expected tgool's chatter to be hidden, got %q", buf.String())
+	}
+
+	log.Println("Failed to get updates, retrying in 3 seconds...")
+	if !strings.Contains(buf.String(), "level=WARN msg=\"Failed to get updates") {
+		t.Fatalf("expected tgbotapi's failures at warn, got %q", buf.String())
+	}
+
+	buf.Reset()
+	SetLevel(slog.LevelDebug)
+
+	log.Println("tgool: handling new message")
+	if !strings.Contains(buf.String(), "level=DEBUG") ||
+		!strings.Contains(buf.String(), "component=std") {
+		t.Fatalf("expected tgool's chatter at debug, got %q", buf.String())
+	}
+}
+
+func TestSetupValidates(t *testing.T) {
+	err := Setup(&bytes.Buffer{}, "xml", slog.LevelInfo)
+	if err != ERR_INVALID_FORMAT {
+		t.Fatalf("expected %v, got %v", ERR_INVALID_FORMAT, err)
+	}
+
+	for _, s := range []string{"debug", "INFO", "warn", "error"} {
+		_, err := ParseLevel(s)
+		if err != nil {
+			t.Fatalf("expected %s to be a level, got %v", s, err)
+		}
+	}
+
+	_, err = ParseLevel("loud")
+	if err == nil {
+		t.Fatal("expected loud not to be a level")
+	}
+}
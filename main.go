@@ -7,9 +7,22 @@ import (
 	"os/signal"
 	"sync"
 	"syscall"
+	_ "time/tzdata"
+
+	"github.com/thekhanj/csdmpro/logging"
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		cli(os.Args[1:])
+	}
+
+	// logging isn't set up yet, so the standard log reports its errors
+	err := logging.SetupFromEnv()
+	if err != nil {
+		log.Fatalf("app: %s", err)
+	}
+
 	app := WireBuild()
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -30,7 +43,7 @@ func main() {
 
 	<-stop
 
-	log.Println("app: stopping...")
+	appLog.Info("stopping")
 	cancel()
 
 	wg.Wait()
@@ -0,0 +1,134 @@
+package tg
+
+import (
+	"context"
+	"sync"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/thekhanj/csdmpro/core"
+	"github.com/thekhanj/csdmpro/logging"
+	"github.com/thekhanj/csdmpro/tg/format"
+	"github.com/thekhanj/csdmpro/tg/service"
+)
+
+var alerterLog = logging.Component("alerter")
+
+// The alerter's queues. A whole stats crawl's updates fit in ALERTER_QUEUE,
+// so the observer doesn't wait for the alerts to be checked, nor for the
+// fired ones to be sent.
+const (
+	ALERTER_QUEUE  = 1000
+	ALERTER_OUTBOX = 100
+)
+
+// Alerter evaluates the chats' threshold alerts whenever a player gets added
+// or their stats get updated. The updates are queued for a worker checking the alerts,
+// which hands the fired ones to another sending them.
+type Alerter struct {
+	updated chan core.PlayerId
+
+	observer     *core.Observer
+	playerRepo   *core.PlayerRepo
+	alertService *service.AlertService
+	locales      *service.LocaleService
+	chats        *service.ChatService
+	bot          *tgbotapi.BotAPI
+	wg           sync.WaitGroup
+}
+
+func (this *Alerter) Start(ctx context.Context) {
+	alerterLog.Info("started")
+	defer alerterLog.Info("stopped")
+
+	// subscribed before going on, stop unsubscribes it
+	this.updated = this.observer.Bus.Sub(
+		core.AddedPlayerTopic, core.UpdatedPlayerTopic,
+	)
+
+	// each is closed once its sender is done, unsubscribing closes updated
+	queue := make(chan core.PlayerId, ALERTER_QUEUE)
+	outbox := make(chan tgbotapi.MessageConfig, ALERTER_OUTBOX)
+
+	this.wg.Add(3)
+
+	go func() {
+		defer this.wg.Done()
+		defer close(queue)
+
+		for playerId := range this.updated {
+			queue <- playerId
+		}
+	}()
+	go func() {
+		defer this.wg.Done()
+		defer close(outbox)
+
+		for playerId := range queue {
+			this.check(playerId, outbox)
+		}
+	}()
+	go func() {
+		defer this.wg.Done()
+
+		for msg := range outbox {
+			this.chats.Send(this.bot, msg.ChatID, msg)
+		}
+	}()
+
+	<-ctx.Done()
+	this.stop()
+}
+
+func (this *Alerter) stop() {
+	alerterLog.Info("stopping")
+
+	go this.observer.Bus.Unsub(this.updated)
+
+	this.wg.Wait()
+}
+
+// check checks the alerts of the player, handing the fired ones to outbox.
+func (this *Alerter) check(
+	playerId core.PlayerId, outbox chan<- tgbotapi.MessageConfig,
+) {
+	player, err := this.playerRepo.GetPlayer(playerId)
+	if err != nil {
+		alerterLog.Error("failed getting player", "player_id", playerId, "err", err)
+		return
+	}
+
+	fired, err := this.alertService.Check(player)
+	if err != nil {
+		alerterLog.Error("failed checking alerts", "player", player.Player.Name, "err", err)
+		return
+	}
+
+	for _, f := range fired {
+		l, err := this.locales.ForChat(f.Alert.ChatId)
+		if err != nil {
+			alerterLog.Error("failed getting locale", "chat_id", f.Alert.ChatId, "err", err)
+			continue
+		}
+
+		condition, err := this.alertService.Describe(l, f.Alert)
+		if err != nil {
+			alerterLog.Error("failed describing alert", "alert_id", f.Alert.ID, "err", err)
+			continue
+		}
+
+		msg := l.T(
+			"alerter.fired",
+			core.CountryFlag(player.Player.Country),
+			format.Name(player.Player.Name),
+			l.T(service.ALERT_METRIC_NAMES[f.Alert.Metric]),
+			service.FormatAlertValue(l, f.Alert.Metric, f.Value),
+			condition,
+		)
+		alerterLog.Info(
+			"alert fired",
+			"alert_id", f.Alert.ID, "chat_id", f.Alert.ChatId, "player", player.Player.Name,
+		)
+
+		outbox <- tgbotapi.NewMessage(f.Alert.ChatId, msg)
+	}
+}
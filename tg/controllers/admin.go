@@ -0,0 +1,634 @@
+package controllers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/thekhanj/csdmpro/core"
+	"github.com/thekhanj/csdmpro/logging"
+	"github.com/thekhanj/csdmpro/tg/format"
+	"github.com/thekhanj/csdmpro/tg/repo"
+	"github.com/thekhanj/tgool"
+)
+
+// Failures shown in the admin panel, more would go over telegram's message
+// length.
+const (
+	RECENT_FAILURES    = 10
+	MAX_FAILURE_LENGTH = 200
+)
+
+// AdminController is the bot operator's panel, reachable only from the
+// admin chats (see AdminMiddleware).
+type AdminController struct {
+	BilakhRepo  *repo.BilakhRepo
+	ChatRepo    *repo.ChatRepo
+	UsageRepo   *repo.UsageRepo
+	FailureRepo *repo.FailureRepo
+	Observer    *core.Observer
+}
+
+func (this *AdminController) AddRoutes(b *tgool.RouterBuilder) {
+	b.SetPrefixRoute("/admin").
+		AddMethod("", "Index").
+		AddMethod("/bilakhs", "BilakhsIndex").
+		AddMethod("/bilakhs/add", "AddBilakh").WithBody().
+		AddMethod("/bilakhs/a/delete/:chatId", "RemoveBilakh").
+		AddMethod("/crawler", "CrawlerIndex").
+		AddMethod("/crawler/a/crawl", "Crawl").
+		AddMethod("/crawler/a/crawl/:loop", "CrawlLoop").
+		AddMethod("/crawler/a/pause/:loop", "PauseLoop").
+		AddMethod("/crawler/a/resume/:loop", "ResumeLoop").
+		AddMethod("/crawler/interval/:loop", "SetLoopInterval").WithBody().
+		AddMethod("/usage", "Usage").
+		AddMethod("/failures", "Failures").
+		AddMethod("/failures/a/clear", "ClearFailures").
+		AddMethod("/logging", "Logging").
+		AddMethod("/logging/a/level/:level", "SetLogLevel")
+}
+
+func (this *AdminController) Index(
+	ctx tgool.Context,
+) (tgbotapi.Chattable, error) {
+	msg := tgbotapi.NewMessage(ctx.GetChatId(), "🛠️ Admin Panel")
+
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				"🕷️ Crawler",
+				"/admin/crawler",
+			),
+			tgbotapi.NewInlineKeyboardButtonData(
+				"📈 Usage",
+				"/admin/usage",
+			),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				"👍 Bilakhs",
+				"/admin/bilakhs",
+			),
+			tgbotapi.NewInlineKeyboardButtonData(
+				"📢 Broadcast",
+				"/admin/broadcast",
+			),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				"⚠️ Failures",
+				"/admin/failures",
+			),
+			tgbotapi.NewInlineKeyboardButtonData(
+				"📜 Logging",
+				"/admin/logging",
+			),
+		),
+	)
+
+	return reply(ctx, msg), nil
+}
+
+func (this *AdminController) BilakhsIndex(
+	ctx tgool.Context,
+) (tgbotapi.Chattable, error) {
+	now := time.Now()
+
+	bilakhs, err := this.BilakhRepo.List(now)
+	if err != nil {
+		return nil, err
+	}
+
+	txt := "👍 Bilakhed Chats\n\nTap a chat to forgive it. ⏳ marks the ones bilakhed for a while for going too fast."
+	if len(bilakhs) == 0 {
+		txt = "👍 Bilakhed Chats\n\n🤷 Nobody is bilakhed."
+	}
+
+	msg := tgbotapi.NewMessage(ctx.GetChatId(), txt)
+
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0, len(bilakhs)+2)
+
+	for _, b := range bilakhs {
+		title := "🚫 " + this.chatName(b.ChatId)
+		if b.IsTemporary() {
+			title = fmt.Sprintf(
+				"⏳ %s, %s left",
+				this.chatName(b.ChatId), format.Duration(b.Until.Sub(now)),
+			)
+		}
+
+		rows = append(rows,
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(
+					title,
+					fmt.Sprintf("/admin/bilakhs/a/delete/%d", b.ChatId),
+				),
+			),
+		)
+	}
+
+	rows = append(rows,
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				"➕ Bilakh a Chat",
+				"/admin/bilakhs/add",
+			),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				"🔙 Back",
+				"/admin",
+			),
+		),
+	)
+
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+
+	return reply(ctx, msg), nil
+}
+
+// AddBilakh prompts for a chat id when entered through a button and
+// bilakhs the chat once the id is sent.
+func (this *AdminController) AddBilakh(
+	ctx tgool.Context,
+) (tgbotapi.Chattable, error) {
+	if ctx.Update().Message == nil {
+		return this.prompt(
+			ctx, "⌨️ Send me the id of the chat to bilakh.", "/admin/bilakhs",
+		), nil
+	}
+
+	chatId, err := strconv.ParseInt(
+		strings.TrimSpace(ctx.Update().Message.Text), 10, 64,
+	)
+	if err != nil {
+		return this.prompt(
+			ctx, "❌ That's not a chat id, try again.", "/admin/bilakhs",
+		), nil
+	}
+
+	err = this.BilakhRepo.Add(chatId)
+	if err != nil {
+		return nil, err
+	}
+
+	requestLog(ctx).Info("bilakhed chat", "target_chat_id", chatId)
+
+	ctx.Redirect("/admin/bilakhs")
+
+	return this.BilakhsIndex(ctx)
+}
+
+func (this *AdminController) RemoveBilakh(
+	ctx tgool.Context,
+) (tgbotapi.Chattable, error) {
+	chatId, err := strconv.ParseInt(ctx.Params().ByName("chatId"), 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	err = this.BilakhRepo.Remove(chatId)
+	if err != nil {
+		return nil, err
+	}
+
+	requestLog(ctx).Info("forgave chat", "target_chat_id", chatId)
+
+	ctx.Bot().Request(
+		tgbotapi.NewCallback(
+			ctx.Update().CallbackQuery.ID,
+			fmt.Sprintf("chat %d forgiven", chatId),
+		),
+	)
+
+	ctx.Redirect("/admin/bilakhs")
+
+	return this.BilakhsIndex(ctx)
+}
+
+func (this *AdminController) CrawlerIndex(
+	ctx tgool.Context,
+) (tgbotapi.Chattable, error) {
+	status := this.Observer.Status()
+	now := time.Now()
+
+	txt := "🕷️ Crawler\n\n" +
+		this.crawlStatus("Onlines", status.Onlines, now) + "\n\n" +
+		this.crawlStatus("Stats", status.Stats, now)
+
+	msg := tgbotapi.NewMessage(ctx.GetChatId(), txt)
+
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		this.loopRow("Onlines", core.OnlinesLoop, status.Onlines),
+		this.loopRow("Stats", core.StatsLoop, status.Stats),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				"⚡ Crawl Now",
+				"/admin/crawler/a/crawl",
+			),
+			tgbotapi.NewInlineKeyboardButtonData(
+				"🔄 Refresh",
+				"/admin/crawler",
+			),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				"🔙 Back",
+				"/admin",
+			),
+		),
+	)
+
+	return reply(ctx, msg), nil
+}
+
+// loopRow has the buttons controlling a loop of the observer.
+func (this *AdminController) loopRow(
+	title string, loop core.ObserverLoop, s core.CrawlStatus,
+) []tgbotapi.InlineKeyboardButton {
+	pause := tgbotapi.NewInlineKeyboardButtonData(
+		"⏸️ "+title,
+		fmt.Sprintf("/admin/crawler/a/pause/%s", loop),
+	)
+	if s.Paused {
+		pause = tgbotapi.NewInlineKeyboardButtonData(
+			"▶️ "+title,
+			fmt.Sprintf("/admin/crawler/a/resume/%s", loop),
+		)
+	}
+
+	return tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(
+			"⚡ "+title,
+			fmt.Sprintf("/admin/crawler/a/crawl/%s", loop),
+		),
+		pause,
+		tgbotapi.NewInlineKeyboardButtonData(
+			"⏱️ "+title,
+			fmt.Sprintf("/admin/crawler/interval/%s", loop),
+		),
+	)
+}
+
+func (this *AdminController) Crawl(
+	ctx tgool.Context,
+) (tgbotapi.Chattable, error) {
+	this.Observer.CrawlNow()
+
+	requestLog(ctx).Info("forced a crawl")
+
+	ctx.Bot().Request(
+		tgbotapi.NewCallback(ctx.Update().CallbackQuery.ID, "crawling now"),
+	)
+
+	ctx.Redirect("/admin/crawler")
+
+	return this.CrawlerIndex(ctx)
+}
+
+func (this *AdminController) CrawlLoop(
+	ctx tgool.Context,
+) (tgbotapi.Chattable, error) {
+	return this.controlLoop(ctx, this.Observer.Crawl, "crawling %s now")
+}
+
+func (this *AdminController) PauseLoop(
+	ctx tgool.Context,
+) (tgbotapi.Chattable, error) {
+	return this.controlLoop(ctx, this.Observer.Pause, "%s paused")
+}
+
+func (this *AdminController) ResumeLoop(
+	ctx tgool.Context,
+) (tgbotapi.Chattable, error) {
+	return this.controlLoop(ctx, this.Observer.Resume, "%s resumed")
+}
+
+// controlLoop does the control to the loop of the route, answering the
+// pressed button with the answer.
+func (this *AdminController) controlLoop(
+	ctx tgool.Context, control func(core.ObserverLoop) error, answer string,
+) (tgbotapi.Chattable, error) {
+	loop, err := core.ParseObserverLoop(ctx.Params().ByName("loop"))
+	if err != nil {
+		return nil, err
+	}
+
+	err = control(loop)
+	if err != nil {
+		return nil, err
+	}
+
+	requestLog(ctx).Info("controlled the observer", "loop", loop)
+
+	ctx.Bot().Request(
+		tgbotapi.NewCallback(
+			ctx.Update().CallbackQuery.ID, fmt.Sprintf(answer, loop),
+		),
+	)
+
+	ctx.Redirect("/admin/crawler")
+
+	return this.CrawlerIndex(ctx)
+}
+
+// SetLoopInterval prompts for an interval when entered through a button and
+// sets the loop's interval once it's sent.
+func (this *AdminController) SetLoopInterval(
+	ctx tgool.Context,
+) (tgbotapi.Chattable, error) {
+	loop, err := core.ParseObserverLoop(ctx.Params().ByName("loop"))
+	if err != nil {
+		return nil, err
+	}
+
+	if ctx.Update().Message == nil {
+		return this.prompt(
+			ctx,
+			fmt.Sprintf(
+				"⌨️ Send me how often to crawl the %s, e.g. 90s, 5m or 1h.", loop,
+			),
+			"/admin/crawler",
+		), nil
+	}
+
+	interval, err := time.ParseDuration(
+		strings.TrimSpace(ctx.Update().Message.Text),
+	)
+	if err != nil {
+		return this.prompt(
+			ctx, "❌ That's not an interval, try again.", "/admin/crawler",
+		), nil
+	}
+
+	err = this.Observer.SetInterval(loop, interval)
+	if err == core.ERR_INVALID_OBSERVER_INTERVAL {
+		return this.prompt(
+			ctx, "❌ The "+err.Error()+", try again.", "/admin/crawler",
+		), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	requestLog(ctx).Info(
+		"set the observer's interval", "loop", loop, "interval", interval,
+	)
+
+	ctx.Redirect("/admin/crawler")
+
+	return this.CrawlerIndex(ctx)
+}
+
+func (this *AdminController) Usage(
+	ctx tgool.Context,
+) (tgbotapi.Chattable, error) {
+	s, err := this.UsageRepo.Stats(time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	txt := fmt.Sprintf(`📈 Usage
+
+💬 Chats: %d, %d active this week, %d blocked the bot
+👁️ Watchlists: %d, watching %d players
+🧩 Watch rules: %d
+🔔 Alerts: %d
+📡 Live boards: %d
+👍 Bilakhed chats: %d
+
+🎮 Players tracked: %d
+🟢 Online now: %d`,
+		s.Chats, s.ActiveChats, s.BlockedChats,
+		s.Watchlists, s.WatchedPlayers, s.WatchRules, s.Alerts,
+		s.LiveBoards, s.Bilakhs, s.Players, s.Onlines,
+	)
+
+	msg := tgbotapi.NewMessage(ctx.GetChatId(), txt)
+
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				"🔄 Refresh",
+				"/admin/usage",
+			),
+			tgbotapi.NewInlineKeyboardButtonData(
+				"🔙 Back",
+				"/admin",
+			),
+		),
+	)
+
+	return reply(ctx, msg), nil
+}
+
+// Failures shows the latest requests the controllers failed to handle.
+func (this *AdminController) Failures(
+	ctx tgool.Context,
+) (tgbotapi.Chattable, error) {
+	now := time.Now()
+
+	count, err := this.FailureRepo.CountSince(now.Add(-24 * time.Hour))
+	if err != nil {
+		return nil, err
+	}
+
+	failures, err := this.FailureRepo.Recent(RECENT_FAILURES)
+	if err != nil {
+		return nil, err
+	}
+
+	txt := fmt.Sprintf("⚠️ Failures\n\n%d in the last 24 hours.", count)
+	if len(failures) == 0 {
+		txt += "\n\n🎉 Nothing failed."
+	}
+
+	for _, f := range failures {
+		if errMsg := []rune(f.Err); len(errMsg) > MAX_FAILURE_LENGTH {
+			f.Err = string(errMsg[:MAX_FAILURE_LENGTH]) + "…"
+		}
+
+		txt += fmt.Sprintf(
+			"\n\n#%d, %s ago, took %s\n💬 %s\n🔗 %s\n%s",
+			f.ID,
+			format.Duration(now.Sub(f.At)),
+			f.Latency,
+			this.chatName(f.ChatId),
+			f.Route,
+			f.Err,
+		)
+	}
+
+	msg := tgbotapi.NewMessage(ctx.GetChatId(), txt)
+
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				"🔄 Refresh",
+				"/admin/failures",
+			),
+			tgbotapi.NewInlineKeyboardButtonData(
+				"🗑️ Clear",
+				"/admin/failures/a/clear",
+			),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				"🔙 Back",
+				"/admin",
+			),
+		),
+	)
+
+	return reply(ctx, msg), nil
+}
+
+func (this *AdminController) ClearFailures(
+	ctx tgool.Context,
+) (tgbotapi.Chattable, error) {
+	err := this.FailureRepo.Clear()
+	if err != nil {
+		return nil, err
+	}
+
+	requestLog(ctx).Info("cleared the failures")
+
+	ctx.Redirect("/admin/failures")
+
+	return this.Failures(ctx)
+}
+
+func (this *AdminController) Logging(
+	ctx tgool.Context,
+) (tgbotapi.Chattable, error) {
+	current := logging.Level()
+
+	txt := fmt.Sprintf(
+		"📜 Logging\n\nLogging at %s and above. Tap a level to log at it until the bot restarts, LOG_LEVEL sets it for good.",
+		current,
+	)
+
+	msg := tgbotapi.NewMessage(ctx.GetChatId(), txt)
+
+	levels := make([]tgbotapi.InlineKeyboardButton, 0, len(logging.LEVELS))
+	for _, level := range logging.LEVELS {
+		title := level.String()
+		if level == current {
+			title = "✅ " + title
+		}
+
+		levels = append(levels, tgbotapi.NewInlineKeyboardButtonData(
+			title,
+			"/admin/logging/a/level/"+strings.ToLower(level.String()),
+		))
+	}
+
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		levels,
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				"🔙 Back",
+				"/admin",
+			),
+		),
+	)
+
+	return reply(ctx, msg), nil
+}
+
+func (this *AdminController) SetLogLevel(
+	ctx tgool.Context,
+) (tgbotapi.Chattable, error) {
+	level, err := logging.ParseLevel(ctx.Params().ByName("level"))
+	if err != nil {
+		return nil, err
+	}
+
+	logging.SetLevel(level)
+
+	// logged at warn so it shows up at any level but error
+	requestLog(ctx).Warn("set the log level", "level", level)
+
+	ctx.Bot().Request(
+		tgbotapi.NewCallback(
+			ctx.Update().CallbackQuery.ID,
+			fmt.Sprintf("logging at %s", level),
+		),
+	)
+
+	ctx.Redirect("/admin/logging")
+
+	return this.Logging(ctx)
+}
+
+// chatName names the chat by its id, along with its title if known.
+func (this *AdminController) chatName(chatId int64) string {
+	chat, err := this.ChatRepo.Get(chatId)
+	if err != nil || chat.Name() == "" {
+		return strconv.FormatInt(chatId, 10)
+	}
+
+	return fmt.Sprintf("%s (%d)", chat.Name(), chatId)
+}
+
+func (this *AdminController) crawlStatus(
+	title string, s core.CrawlStatus, now time.Time,
+) string {
+	every := "every " + formatInterval(s.Interval)
+	if s.Paused {
+		every = "⏸️ paused"
+	}
+
+	if s.At.IsZero() {
+		return fmt.Sprintf("⏳ %s: not crawled yet\n%s", title, every)
+	}
+
+	txt := fmt.Sprintf(
+		"%s: %s ago, took %s\n%s",
+		title,
+		now.Sub(s.At).Round(time.Second),
+		s.Duration.Round(time.Millisecond),
+		every,
+	)
+
+	if s.Err != nil {
+		return "🔴 " + txt + "\n" + s.Err.Error()
+	}
+
+	return "🟢 " + txt
+}
+
+// formatInterval formats the interval like time.Duration does, without the
+// zero minutes and seconds at its end.
+func formatInterval(d time.Duration) string {
+	txt := d.String()
+	if strings.HasSuffix(txt, "m0s") {
+		txt = strings.TrimSuffix(txt, "0s")
+	}
+	if strings.HasSuffix(txt, "h0m") {
+		txt = strings.TrimSuffix(txt, "0m")
+	}
+
+	return txt
+}
+
+func (this *AdminController) prompt(
+	ctx tgool.Context, txt string, back string,
+) tgbotapi.Chattable {
+	msg := tgbotapi.NewMessage(ctx.GetChatId(), txt)
+
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				"🔙 Back",
+				back,
+			),
+		),
+	)
+
+	return reply(ctx, msg)
+}
+
+var _ tgool.Controller = (*AdminController)(nil)
@@ -0,0 +1,441 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/thekhanj/csdmpro/core"
+	"github.com/thekhanj/csdmpro/tg/i18n"
+	"github.com/thekhanj/csdmpro/tg/repo"
+	"github.com/thekhanj/csdmpro/tg/service"
+	"github.com/thekhanj/tgool"
+)
+
+// Used in place of a player id for alerts on anyone.
+const ALERT_ANYONE = "any"
+
+var ERR_UNKNOWN_ALERT_METRIC error = errors.New("unknown alert metric")
+var ERR_UNKNOWN_ALERT_OP error = errors.New("unknown alert operator")
+
+type AlertsController struct {
+	PlayerRepo       *core.PlayerRepo
+	AlertRepo        *repo.AlertRepo
+	AlertService     *service.AlertService
+	WatchlistService *service.WatchlistService
+	Locales          *service.LocaleService
+	Permissions      *service.PermissionService
+}
+
+func (this *AlertsController) AddRoutes(b *tgool.RouterBuilder) {
+	b.SetPrefixRoute("/alerts").
+		AddMethod("", "Index").
+		AddMethod("/new", "PickPlayer").
+		AddMethod("/new/:player", "PickMetric").
+		AddMethod("/new/:player/:metric", "PickOp").
+		AddMethod("/new/:player/:metric/:op", "EnterThreshold").WithBody().
+		AddMethod("/delete/:alertId", "Delete")
+}
+
+func (this *AlertsController) Index(
+	ctx tgool.Context,
+) (tgbotapi.Chattable, error) {
+	chatId := ctx.GetChatId()
+
+	l, err := localizer(ctx, this.Locales)
+	if err != nil {
+		return nil, err
+	}
+
+	alerts, err := this.AlertRepo.List(chatId)
+	if err != nil {
+		return nil, err
+	}
+
+	txt := l.T("alerts.title")
+
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0)
+
+	txt += "\n\n"
+	if len(alerts) == 0 {
+		txt += l.T("alerts.empty")
+	} else {
+		txt += l.T("alerts.tap_to_remove")
+	}
+
+	for _, alert := range alerts {
+		condition, err := this.AlertService.Describe(l, alert)
+		if err != nil {
+			return nil, err
+		}
+
+		rows = append(rows,
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(
+					"❌ "+condition,
+					fmt.Sprintf("/alerts/delete/%d", alert.ID),
+				),
+			),
+		)
+	}
+
+	rows = append(rows,
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				l.T("alerts.new"),
+				"/alerts/new",
+			),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				l.T("common.back"),
+				"/start",
+			),
+		),
+	)
+
+	msg := tgbotapi.NewMessage(chatId, txt)
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+
+	return reply(ctx, msg), nil
+}
+
+func (this *AlertsController) PickPlayer(
+	ctx tgool.Context,
+) (tgbotapi.Chattable, error) {
+	chatId := ctx.GetChatId()
+
+	l, err := localizer(ctx, this.Locales)
+	if err != nil {
+		return nil, err
+	}
+
+	tps, err := this.WatchlistService.GetTracking(chatId)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := tgbotapi.NewMessage(chatId, l.T("alerts.pick_player"))
+
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0)
+
+	rows = append(rows,
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				l.T("alerts.anyone"),
+				fmt.Sprintf("/alerts/new/%s", ALERT_ANYONE),
+			),
+		),
+	)
+
+	for i := 0; i < len(tps); i += 2 {
+		row := tgbotapi.NewInlineKeyboardRow()
+
+		for _, tp := range tps[i:min(i+2, len(tps))] {
+			p := tp.DbPlayer
+			row = append(row,
+				tgbotapi.NewInlineKeyboardButtonData(
+					fmt.Sprintf(
+						"%s %s", core.CountryFlag(p.Player.Country), p.Player.Name,
+					),
+					fmt.Sprintf("/alerts/new/%d", p.ID),
+				),
+			)
+		}
+
+		rows = append(rows, row)
+	}
+
+	rows = append(rows,
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				l.T("common.back"),
+				"/alerts",
+			),
+		),
+	)
+
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+
+	return reply(ctx, msg), nil
+}
+
+// alertMetricTitles are the keys of the alert metrics' buttons.
+var alertMetricTitles = map[repo.AlertMetric]string{
+	repo.AlertRank:     "alerts.rank",
+	repo.AlertScore:    "alerts.score",
+	repo.AlertKills:    "alerts.kills",
+	repo.AlertAccuracy: "alerts.accuracy",
+	repo.AlertKD:       "alerts.kd",
+}
+
+func (this *AlertsController) PickMetric(
+	ctx tgool.Context,
+) (tgbotapi.Chattable, error) {
+	l, err := localizer(ctx, this.Locales)
+	if err != nil {
+		return nil, err
+	}
+
+	player := ctx.Params().ByName("player")
+
+	who, err := this.describePlayer(l, player)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := tgbotapi.NewMessage(ctx.GetChatId(), l.T("alerts.pick_metric", who))
+
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0)
+
+	for _, metric := range repo.AlertMetrics {
+		rows = append(rows,
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(
+					l.T(alertMetricTitles[metric]),
+					fmt.Sprintf("/alerts/new/%s/%s", player, metric),
+				),
+			),
+		)
+	}
+
+	rows = append(rows,
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				l.T("common.back"),
+				"/alerts/new",
+			),
+		),
+	)
+
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+
+	return reply(ctx, msg), nil
+}
+
+func (this *AlertsController) PickOp(
+	ctx tgool.Context,
+) (tgbotapi.Chattable, error) {
+	l, err := localizer(ctx, this.Locales)
+	if err != nil {
+		return nil, err
+	}
+
+	player := ctx.Params().ByName("player")
+
+	who, err := this.describePlayer(l, player)
+	if err != nil {
+		return nil, err
+	}
+
+	metric, err := this.getMetricParam(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	txt := l.T("alerts.pick_op", who, l.T(service.ALERT_METRIC_NAMES[metric]))
+	if metric == repo.AlertRank {
+		txt += "\n\n" + l.T("alerts.rank_note")
+	}
+
+	msg := tgbotapi.NewMessage(ctx.GetChatId(), txt)
+
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				l.T("alerts.at_least"),
+				fmt.Sprintf("/alerts/new/%s/%s/%s", player, metric, repo.AlertAtLeast),
+			),
+			tgbotapi.NewInlineKeyboardButtonData(
+				l.T("alerts.at_most"),
+				fmt.Sprintf("/alerts/new/%s/%s/%s", player, metric, repo.AlertAtMost),
+			),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				l.T("common.back"),
+				fmt.Sprintf("/alerts/new/%s", player),
+			),
+		),
+	)
+
+	return reply(ctx, msg), nil
+}
+
+// EnterThreshold prompts for the threshold when entered through a button and
+// creates the alert once it's sent.
+func (this *AlertsController) EnterThreshold(
+	ctx tgool.Context,
+) (tgbotapi.Chattable, error) {
+	chatId := ctx.GetChatId()
+	player := ctx.Params().ByName("player")
+
+	l, err := localizer(ctx, this.Locales)
+	if err != nil {
+		return nil, err
+	}
+
+	metric, err := this.getMetricParam(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	op := repo.AlertOp(ctx.Params().ByName("op"))
+	if op != repo.AlertAtLeast && op != repo.AlertAtMost {
+		return nil, ERR_UNKNOWN_ALERT_OP
+	}
+
+	backKeyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				l.T("common.back"),
+				fmt.Sprintf("/alerts/new/%s/%s", player, metric),
+			),
+		),
+	)
+
+	if ctx.Update().Message == nil {
+		msg := tgbotapi.NewMessage(chatId, l.T("alerts.enter_threshold"))
+		msg.ReplyMarkup = backKeyboard
+
+		return reply(ctx, msg), nil
+	}
+
+	err = checkCanEdit(ctx, this.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	threshold, err := this.parseThreshold(ctx.Update().Message.Text)
+	if err != nil {
+		msg := tgbotapi.NewMessage(chatId, l.T("alerts.invalid_threshold"))
+		msg.ReplyMarkup = backKeyboard
+
+		return reply(ctx, msg), nil
+	}
+
+	alert := repo.Alert{
+		ChatId:    chatId,
+		Metric:    metric,
+		Op:        op,
+		Threshold: threshold,
+	}
+
+	if player != ALERT_ANYONE {
+		p, err := this.getPlayer(player)
+		if err != nil {
+			return nil, err
+		}
+
+		alert.PlayerId = &p.ID
+	}
+
+	_, err = this.AlertService.Create(alert)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx.Redirect("/alerts")
+
+	return this.Index(ctx)
+}
+
+func (this *AlertsController) Delete(
+	ctx tgool.Context,
+) (tgbotapi.Chattable, error) {
+	l, err := localizer(ctx, this.Locales)
+	if err != nil {
+		return nil, err
+	}
+
+	alertId, err := strconv.ParseInt(ctx.Params().ByName("alertId"), 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkCanEdit(ctx, this.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	err = this.AlertRepo.Remove(ctx.GetChatId(), alertId)
+	if err != nil {
+		return nil, err
+	}
+
+	if ctx.Update().CallbackQuery != nil {
+		ctx.Bot().Request(
+			tgbotapi.NewCallback(
+				ctx.Update().CallbackQuery.ID, l.T("alerts.removed"),
+			),
+		)
+	}
+
+	ctx.Redirect("/alerts")
+
+	return this.Index(ctx)
+}
+
+// parseThreshold reads numbers like 30, 2.5, 30% or 100k.
+func (this *AlertsController) parseThreshold(txt string) (float64, error) {
+	txt = strings.ToLower(strings.TrimSpace(txt))
+	txt = strings.TrimSuffix(txt, "%")
+	txt = strings.ReplaceAll(txt, ",", "")
+
+	multiplier := 1.0
+	if strings.HasSuffix(txt, "k") {
+		multiplier = 1000
+		txt = strings.TrimSuffix(txt, "k")
+	} else if strings.HasSuffix(txt, "m") {
+		multiplier = 1000000
+		txt = strings.TrimSuffix(txt, "m")
+	}
+
+	value, err := strconv.ParseFloat(txt, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	return value * multiplier, nil
+}
+
+func (this *AlertsController) describePlayer(
+	l i18n.Localizer, player string,
+) (string, error) {
+	if player == ALERT_ANYONE {
+		return l.T("alerts.anyone_name"), nil
+	}
+
+	p, err := this.getPlayer(player)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf(
+		"%s %s", core.CountryFlag(p.Player.Country), p.Player.Name,
+	), nil
+}
+
+func (this *AlertsController) getPlayer(player string) (core.DbPlayer, error) {
+	playerId, err := strconv.Atoi(player)
+	if err != nil {
+		return core.DbPlayer{}, err
+	}
+
+	return this.PlayerRepo.GetPlayer(core.PlayerId(playerId))
+}
+
+func (this *AlertsController) getMetricParam(
+	ctx tgool.Context,
+) (repo.AlertMetric, error) {
+	metric := repo.AlertMetric(ctx.Params().ByName("metric"))
+	if _, ok := alertMetricTitles[metric]; !ok {
+		return "", ERR_UNKNOWN_ALERT_METRIC
+	}
+
+	return metric, nil
+}
+
+var _ tgool.Controller = (*AlertsController)(nil)
@@ -4,10 +4,13 @@ import (
 	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/thekhanj/csdmpro/tg/service"
 	"github.com/thekhanj/tgool"
 )
 
-type BilakhController struct{}
+type BilakhController struct {
+	Locales *service.LocaleService
+}
 
 func (this *BilakhController) AddRoutes(b *tgool.RouterBuilder) {
 	b.SetPrefixRoute("/start").
@@ -31,36 +34,39 @@ func (this *BilakhController) Index(
 ) (tgbotapi.Chattable, error) {
 	chatId := ctx.GetChatId()
 
-	txt := `Surprise! 🎁
+	l, err := localizer(ctx, this.Locales)
+	if err != nil {
+		return nil, err
+	}
 
-You’ve received a certified Bilakh™ from the universe. 👍`
+	txt := l.T("bilakh.surprise")
 
 	msg := tgbotapi.NewMessage(chatId, txt)
 
 	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData(
-				"🫡 Accept your fate",
+				l.T("bilakh.accept_your_fate"),
 				"/bilakh/accept-your-fate",
 			),
 			tgbotapi.NewInlineKeyboardButtonData(
-				"🤨 Who sent this?!",
+				l.T("bilakh.who_sent_this"),
 				"/bilakh/who-sent-this",
 			),
 		),
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData(
-				"🔁 Return the Bilakh",
+				l.T("bilakh.return_the_bilakh"),
 				"/bilakh/return-the-bilakh",
 			),
 			tgbotapi.NewInlineKeyboardButtonData(
-				"💥 Show me more!",
+				l.T("bilakh.show_me_more"),
 				"/bilakh/show-me-more",
 			),
 		),
 	)
 
-	return msg, nil
+	return reply(ctx, msg), nil
 }
 
 func (this *BilakhController) AcceptYourFate(
@@ -68,24 +74,29 @@ func (this *BilakhController) AcceptYourFate(
 ) (tgbotapi.Chattable, error) {
 	chatId := ctx.GetChatId()
 
-	txt := `Wise choice. Resistance is futile. 👏`
+	l, err := localizer(ctx, this.Locales)
+	if err != nil {
+		return nil, err
+	}
+
+	txt := l.T("bilakh.wise_choice")
 
 	msg := tgbotapi.NewMessage(chatId, txt)
 
 	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData(
-				"🔁 Yes, double it",
+				l.T("bilakh.yes_double_it"),
 				"/bilakh/yes-double-it",
 			),
 			tgbotapi.NewInlineKeyboardButtonData(
-				"🙈 No thanks",
+				l.T("bilakh.no_thanks"),
 				"/bilakh",
 			),
 		),
 	)
 
-	return msg, nil
+	return reply(ctx, msg), nil
 }
 
 func (this *BilakhController) YesDoubleIt(
@@ -93,20 +104,25 @@ func (this *BilakhController) YesDoubleIt(
 ) (tgbotapi.Chattable, error) {
 	chatId := ctx.GetChatId()
 
-	txt := "Here's a double Bilakh for you honey. 👍👍❤️"
+	l, err := localizer(ctx, this.Locales)
+	if err != nil {
+		return nil, err
+	}
+
+	txt := l.T("bilakh.double")
 
 	msg := tgbotapi.NewMessage(chatId, txt)
 
 	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData(
-				"🥰 Appretiated",
+				l.T("bilakh.appreciated"),
 				"/bilakh",
 			),
 		),
 	)
 
-	return msg, nil
+	return reply(ctx, msg), nil
 }
 
 func (this *BilakhController) WhoSentThis(
@@ -114,26 +130,31 @@ func (this *BilakhController) WhoSentThis(
 ) (tgbotapi.Chattable, error) {
 	chatId := ctx.GetChatId()
 
-	txt := `Sorry, that info is classified. 🕵️`
+	l, err := localizer(ctx, this.Locales)
+	if err != nil {
+		return nil, err
+	}
+
+	txt := l.T("bilakh.classified")
 
 	msg := tgbotapi.NewMessage(chatId, txt)
 
 	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData(
-				"👍 Send me one more then",
+				l.T("bilakh.send_me_one_more"),
 				"/bilakh/send-me-one-more-then",
 			),
 		),
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData(
-				"😭 Don't embarrase me anymore!",
+				l.T("bilakh.dont_embarrass_me"),
 				"/bilakh",
 			),
 		),
 	)
 
-	return msg, nil
+	return reply(ctx, msg), nil
 }
 
 func (this *BilakhController) SendMeOneMoreThen(
@@ -141,20 +162,25 @@ func (this *BilakhController) SendMeOneMoreThen(
 ) (tgbotapi.Chattable, error) {
 	chatId := ctx.GetChatId()
 
-	txt := `Here's one more Bilakh for you dear. ❤️👍`
+	l, err := localizer(ctx, this.Locales)
+	if err != nil {
+		return nil, err
+	}
+
+	txt := l.T("bilakh.one_more")
 
 	msg := tgbotapi.NewMessage(chatId, txt)
 
 	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData(
-				"❤️ Thanks",
+				l.T("bilakh.thanks"),
 				"/bilakh",
 			),
 		),
 	)
 
-	return msg, nil
+	return reply(ctx, msg), nil
 }
 
 func (this *BilakhController) ReturnTheBilakh(
@@ -162,20 +188,25 @@ func (this *BilakhController) ReturnTheBilakh(
 ) (tgbotapi.Chattable, error) {
 	chatId := ctx.GetChatId()
 
-	txt := "Sorry, this bilakh is non-refundable — it's handcrafted just for you. 🎁"
+	l, err := localizer(ctx, this.Locales)
+	if err != nil {
+		return nil, err
+	}
+
+	txt := l.T("bilakh.non_refundable")
 
 	msg := tgbotapi.NewMessage(chatId, txt)
 
 	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData(
-				"❤️🥹 Thanks then",
+				l.T("bilakh.thanks_then"),
 				"/bilakh",
 			),
 		),
 	)
 
-	return msg, nil
+	return reply(ctx, msg), nil
 }
 
 func (this *BilakhController) ShowMeMore(
@@ -183,38 +214,43 @@ func (this *BilakhController) ShowMeMore(
 ) (tgbotapi.Chattable, error) {
 	chatId := ctx.GetChatId()
 
-	txt := `You’re now entering Bilakh Heaven. 🥳`
+	l, err := localizer(ctx, this.Locales)
+	if err != nil {
+		return nil, err
+	}
+
+	txt := l.T("bilakh.heaven")
 
 	msg := tgbotapi.NewMessage(chatId, txt)
 
 	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData(
-				"🔥 Bilakh with fireworks",
+				l.T("bilakh.with_fireworks"),
 				"/bilakh/bilakh-with-fireworks",
 			),
 		),
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData(
-				"🎵 Bilakh with dramatic music",
+				l.T("bilakh.with_dramatic_music"),
 				"/bilakh/bilakh-with-dramatic-music",
 			),
 		),
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData(
-				"😱 Bilakh with screaming goat",
+				l.T("bilakh.with_screaming_goat"),
 				"/bilakh/bilakh-with-screaming-goat",
 			),
 		),
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData(
-				"🐢 Bilakh in slow motion",
+				l.T("bilakh.in_slow_motion"),
 				"/bilakh/bilakh-in-slow-motion",
 			),
 		),
 	)
 
-	return msg, nil
+	return reply(ctx, msg), nil
 }
 
 func (this *BilakhController) BilakhWithFireworks(
@@ -222,20 +258,25 @@ func (this *BilakhController) BilakhWithFireworks(
 ) (tgbotapi.Chattable, error) {
 	chatId := ctx.GetChatId()
 
-	txt := `Here's a Bilakh with fireworks for you. 👍🔥🧨🎆`
+	l, err := localizer(ctx, this.Locales)
+	if err != nil {
+		return nil, err
+	}
+
+	txt := l.T("bilakh.fireworks")
 
 	msg := tgbotapi.NewMessage(chatId, txt)
 
 	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData(
-				"🔥😍 Coooool",
+				l.T("bilakh.cool"),
 				"/bilakh",
 			),
 		),
 	)
 
-	return msg, nil
+	return reply(ctx, msg), nil
 }
 
 func (this *BilakhController) BilakhWithDramaticMusic(
@@ -243,20 +284,25 @@ func (this *BilakhController) BilakhWithDramaticMusic(
 ) (tgbotapi.Chattable, error) {
 	chatId := ctx.GetChatId()
 
-	txt := `Here's a Bilakh with some music for you. 👍🎵🎹🎷🎸🎺`
+	l, err := localizer(ctx, this.Locales)
+	if err != nil {
+		return nil, err
+	}
+
+	txt := l.T("bilakh.music")
 
 	msg := tgbotapi.NewMessage(chatId, txt)
 
 	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData(
-				"☺️😍 I love it",
+				l.T("bilakh.love_it"),
 				"/bilakh",
 			),
 		),
 	)
 
-	return msg, nil
+	return reply(ctx, msg), nil
 }
 
 func (this *BilakhController) BialkhWithScreamingGoat(
@@ -264,20 +310,25 @@ func (this *BilakhController) BialkhWithScreamingGoat(
 ) (tgbotapi.Chattable, error) {
 	chatId := ctx.GetChatId()
 
-	txt := "Here's a Bilakh with an screaming goat for you. 👍🐐😱"
+	l, err := localizer(ctx, this.Locales)
+	if err != nil {
+		return nil, err
+	}
+
+	txt := l.T("bilakh.goat")
 
 	msg := tgbotapi.NewMessage(chatId, txt)
 
 	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData(
-				"🐑 Beeeee",
+				l.T("bilakh.beee"),
 				"/bilakh",
 			),
 		),
 	)
 
-	return msg, nil
+	return reply(ctx, msg), nil
 }
 
 func (this *BilakhController) BilakhInSlowMotion(
@@ -285,18 +336,23 @@ func (this *BilakhController) BilakhInSlowMotion(
 ) (tgbotapi.Chattable, error) {
 	chatId := ctx.GetChatId()
 
-	txt1 := "Here's a Biiii..."
+	l, err := localizer(ctx, this.Locales)
+	if err != nil {
+		return nil, err
+	}
+
+	txt1 := l.T("bilakh.slow_motion_1")
 	msg1 := tgbotapi.NewMessage(chatId, txt1)
 	ctx.Bot().Send(msg1)
 
 	time.Sleep(time.Second * 2)
-	txt2 := "...llllaaaakhhhhh for you. 👍🐢"
+	txt2 := l.T("bilakh.slow_motion_2")
 	msg2 := tgbotapi.NewMessage(chatId, txt2)
 
 	msg2.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData(
-				"🐌 Than... ...kksss",
+				l.T("bilakh.thanks_slowly"),
 				"/bilakh",
 			),
 		),
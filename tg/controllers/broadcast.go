@@ -0,0 +1,286 @@
+package controllers
+
+import (
+	"fmt"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/thekhanj/csdmpro/core"
+	"github.com/thekhanj/csdmpro/tg/repo"
+	"github.com/thekhanj/csdmpro/tg/service"
+	"github.com/thekhanj/tgool"
+)
+
+var ERR_NO_BROADCAST_RUNNING error = core.NewUserError("errors.no_broadcast_running")
+
+var AUDIENCE_TITLES = map[repo.Audience]string{
+	repo.AudienceAll:      "🌐 Everyone",
+	repo.AudienceWatchers: "👁️ Watching Players",
+	repo.AudiencePrivate:  "👤 Private Chats",
+	repo.AudienceGroups:   "👥 Groups",
+	repo.AudienceActive:   "🔥 Active This Week",
+}
+
+// BroadcastController lets the admins announce a message to the chats,
+// after previewing it and picking who gets it. It lives under /admin so
+// it's guarded the same way.
+type BroadcastController struct {
+	ChatRepo *repo.ChatRepo
+	Service  *service.BroadcastService
+}
+
+func (this *BroadcastController) AddRoutes(b *tgool.RouterBuilder) {
+	b.SetPrefixRoute("/admin/broadcast").
+		AddMethod("", "Index").WithBody().
+		AddMethod("/a/audience/:audience", "SetAudience").
+		AddMethod("/a/send", "Send").
+		AddMethod("/a/stop", "Stop").
+		AddMethod("/a/cancel", "Cancel")
+}
+
+// Index prompts for the message when entered through a button, and
+// previews it once it's sent. Sending another message replaces it.
+func (this *BroadcastController) Index(
+	ctx tgool.Context,
+) (tgbotapi.Chattable, error) {
+	chatId := ctx.GetChatId()
+
+	if ctx.Update().Message == nil {
+		txt := "📢 Send me the message to broadcast. Any kind of message works, it's sent as is with its formatting."
+		if this.Service.IsRunning() {
+			txt = "⏳ A broadcast is running, wait for it to finish before starting another."
+		}
+
+		msg := tgbotapi.NewMessage(chatId, txt)
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(
+					"🔙 Back",
+					"/admin",
+				),
+			),
+		)
+
+		return reply(ctx, msg), nil
+	}
+
+	draft := service.BroadcastDraft{
+		FromChatId: chatId,
+		MessageId:  ctx.Update().Message.MessageID,
+		Audience:   repo.AudienceAll,
+	}
+	this.Service.SetDraft(chatId, draft)
+
+	_, err := ctx.Bot().Send(
+		tgbotapi.NewCopyMessage(chatId, draft.FromChatId, draft.MessageId),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return this.preview(ctx, draft)
+}
+
+func (this *BroadcastController) SetAudience(
+	ctx tgool.Context,
+) (tgbotapi.Chattable, error) {
+	chatId := ctx.GetChatId()
+
+	audience, err := repo.ParseAudience(ctx.Params().ByName("audience"))
+	if err != nil {
+		return nil, err
+	}
+
+	draft, err := this.Service.Draft(chatId)
+	if err != nil {
+		return nil, err
+	}
+
+	draft.Audience = audience
+	this.Service.SetDraft(chatId, draft)
+
+	ctx.Redirect("/admin/broadcast")
+
+	return this.preview(ctx, draft)
+}
+
+func (this *BroadcastController) Send(
+	ctx tgool.Context,
+) (tgbotapi.Chattable, error) {
+	chatId := ctx.GetChatId()
+	bot := ctx.Bot()
+
+	progress := tgbotapi.NewMessage(chatId, "📢 Broadcasting...")
+	progress.ReplyMarkup = this.stopKeyboard()
+
+	sent, err := bot.Send(progress)
+	if err != nil {
+		return nil, err
+	}
+	messageId := sent.MessageID
+
+	err = this.Service.Start(bot, chatId, func(p service.BroadcastProgress) {
+		edit := tgbotapi.NewEditMessageText(chatId, messageId, this.progress(p))
+		if !p.Done {
+			markup := this.stopKeyboard()
+			edit.ReplyMarkup = &markup
+		}
+
+		_, err := bot.Send(edit)
+		if err != nil {
+			controllersLog.Warn(
+				"failed reporting broadcast progress", "chat_id", chatId, "err", err,
+			)
+		}
+	})
+	if err != nil {
+		bot.Request(tgbotapi.NewDeleteMessage(chatId, messageId))
+		return nil, err
+	}
+
+	msg := tgbotapi.NewMessage(chatId, "📢 Broadcast started, its progress is below.")
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				"🔙 Back",
+				"/admin",
+			),
+		),
+	)
+
+	return reply(ctx, msg), nil
+}
+
+func (this *BroadcastController) Stop(
+	ctx tgool.Context,
+) (tgbotapi.Chattable, error) {
+	p, ok := this.Service.Stop()
+	if !ok {
+		return nil, ERR_NO_BROADCAST_RUNNING
+	}
+
+	msg := tgbotapi.NewMessage(ctx.GetChatId(), this.progress(p))
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				"🔙 Back",
+				"/admin",
+			),
+		),
+	)
+
+	return reply(ctx, msg), nil
+}
+
+func (this *BroadcastController) Cancel(
+	ctx tgool.Context,
+) (tgbotapi.Chattable, error) {
+	chatId := ctx.GetChatId()
+
+	this.Service.DiscardDraft(chatId)
+
+	msg := tgbotapi.NewMessage(chatId, "🗑️ Broadcast discarded.")
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				"🔙 Back",
+				"/admin",
+			),
+		),
+	)
+
+	return reply(ctx, msg), nil
+}
+
+func (this *BroadcastController) preview(
+	ctx tgool.Context, draft service.BroadcastDraft,
+) (tgbotapi.Chattable, error) {
+	chats, err := this.ChatRepo.Audience(draft.Audience, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	msg := tgbotapi.NewMessage(ctx.GetChatId(), fmt.Sprintf(
+		"☝️ That's how the broadcast looks, send another message to replace it.\n\n%s: %d chats",
+		AUDIENCE_TITLES[draft.Audience], len(chats),
+	))
+
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0)
+
+	for i := 0; i < len(repo.AUDIENCES); i += 2 {
+		row := tgbotapi.NewInlineKeyboardRow()
+
+		for _, a := range repo.AUDIENCES[i:min(i+2, len(repo.AUDIENCES))] {
+			title := AUDIENCE_TITLES[a]
+			if a == draft.Audience {
+				title = "✅ " + title
+			}
+
+			row = append(row,
+				tgbotapi.NewInlineKeyboardButtonData(
+					title,
+					"/admin/broadcast/a/audience/"+string(a),
+				),
+			)
+		}
+
+		rows = append(rows, row)
+	}
+
+	rows = append(rows,
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				"📢 Send",
+				"/admin/broadcast/a/send",
+			),
+			tgbotapi.NewInlineKeyboardButtonData(
+				"🗑️ Discard",
+				"/admin/broadcast/a/cancel",
+			),
+		),
+	)
+
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+
+	return reply(ctx, msg), nil
+}
+
+func (this *BroadcastController) progress(p service.BroadcastProgress) string {
+	title := "📢 Broadcasting to " + AUDIENCE_TITLES[p.Audience]
+	if p.Stopped {
+		title = "⏹️ Broadcast stopped"
+	} else if p.Done {
+		title = fmt.Sprintf(
+			"✅ Broadcast finished in %s", time.Since(p.Started).Round(time.Second),
+		)
+	}
+
+	txt := fmt.Sprintf(
+		"%s\n\n📨 Sent: %d/%d\n❌ Failed: %d", title, p.Sent, p.Total, p.Failed,
+	)
+
+	if len(p.Failures) != 0 {
+		txt += "\n"
+	}
+	for _, f := range p.Failures {
+		txt += fmt.Sprintf("\n%d: %s", f.ChatId, f.Err)
+	}
+	if p.Failed > len(p.Failures) {
+		txt += fmt.Sprintf("\n…and %d more", p.Failed-len(p.Failures))
+	}
+
+	return txt
+}
+
+func (this *BroadcastController) stopKeyboard() tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				"⏹️ Stop",
+				"/admin/broadcast/a/stop",
+			),
+		),
+	)
+}
+
+var _ tgool.Controller = (*BroadcastController)(nil)
@@ -0,0 +1,361 @@
+package controllers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/thekhanj/csdmpro/core"
+	"github.com/thekhanj/csdmpro/tg/i18n"
+	"github.com/thekhanj/csdmpro/tg/service"
+	"github.com/thekhanj/tgool"
+)
+
+var ERR_COMPARE_SAME_PLAYER error = core.NewUserError("errors.compare_same_player")
+
+const COMPARE_PERIOD = time.Hour * 24 * 7
+
+type CompareController struct {
+	PlayerRepo *core.PlayerRepo
+	Service    *service.WatchlistService
+	Locales    *service.LocaleService
+}
+
+func (this *CompareController) AddRoutes(b *tgool.RouterBuilder) {
+	b.SetPrefixRoute("/compare").
+		AddMethod("", "Index").
+		AddMethod("/search", "SearchFirst").WithBody().
+		AddMethod("/with/:first", "PickSecond").
+		AddMethod("/with/:first/search", "SearchSecond").WithBody().
+		AddMethod("/result/:first/:second", "Result")
+}
+
+func (this *CompareController) Index(
+	ctx tgool.Context,
+) (tgbotapi.Chattable, error) {
+	l, err := localizer(ctx, this.Locales)
+	if err != nil {
+		return nil, err
+	}
+
+	tps, err := this.Service.GetTracking(ctx.GetChatId())
+	if err != nil {
+		return nil, err
+	}
+
+	txt := l.T("compare.pick_first")
+
+	players := make([]core.DbPlayer, 0, len(tps))
+	for _, tp := range tps {
+		players = append(players, tp.DbPlayer)
+	}
+
+	return this.pickMessage(
+		ctx, l, txt, players, "/compare/with/%d", "/compare/search", "/start",
+	), nil
+}
+
+func (this *CompareController) SearchFirst(
+	ctx tgool.Context,
+) (tgbotapi.Chattable, error) {
+	return this.search(
+		ctx, "compare.search_first", 0, "/compare/with/%d", "/compare/search", "/compare",
+	)
+}
+
+func (this *CompareController) PickSecond(
+	ctx tgool.Context,
+) (tgbotapi.Chattable, error) {
+	l, err := localizer(ctx, this.Locales)
+	if err != nil {
+		return nil, err
+	}
+
+	first, err := this.getPlayerParam(ctx, "first")
+	if err != nil {
+		return nil, err
+	}
+
+	tps, err := this.Service.GetTracking(ctx.GetChatId())
+	if err != nil {
+		return nil, err
+	}
+
+	players := make([]core.DbPlayer, 0, len(tps))
+	for _, tp := range tps {
+		if tp.DbPlayer.ID != first.ID {
+			players = append(players, tp.DbPlayer)
+		}
+	}
+
+	txt := l.T(
+		"compare.pick_second",
+		core.CountryFlag(first.Player.Country), first.Player.Name,
+	)
+
+	return this.pickMessage(
+		ctx, l, txt, players,
+		fmt.Sprintf("/compare/result/%d/%%d", first.ID),
+		fmt.Sprintf("/compare/with/%d/search", first.ID),
+		"/compare",
+	), nil
+}
+
+func (this *CompareController) SearchSecond(
+	ctx tgool.Context,
+) (tgbotapi.Chattable, error) {
+	first, err := this.getPlayerParam(ctx, "first")
+	if err != nil {
+		return nil, err
+	}
+
+	return this.search(
+		ctx, "compare.search_second", first.ID,
+		fmt.Sprintf("/compare/result/%d/%%d", first.ID),
+		fmt.Sprintf("/compare/with/%d/search", first.ID),
+		fmt.Sprintf("/compare/with/%d", first.ID),
+	)
+}
+
+func (this *CompareController) Result(
+	ctx tgool.Context,
+) (tgbotapi.Chattable, error) {
+	l, err := localizer(ctx, this.Locales)
+	if err != nil {
+		return nil, err
+	}
+
+	first, err := this.getPlayerParam(ctx, "first")
+	if err != nil {
+		return nil, err
+	}
+	second, err := this.getPlayerParam(ctx, "second")
+	if err != nil {
+		return nil, err
+	}
+	if first.ID == second.ID {
+		return nil, ERR_COMPARE_SAME_PLAYER
+	}
+
+	c, err := this.PlayerRepo.Compare(
+		first.ID, second.ID, time.Now().Add(-COMPARE_PERIOD),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	names := [2]string{}
+	for i, p := range c.Players {
+		names[i] = fmt.Sprintf(
+			"%s %s",
+			core.CountryFlag(p.DbPlayer.Player.Country), p.DbPlayer.Player.Name,
+		)
+	}
+
+	txt := l.T("compare.title", names[0], names[1]) + "\n\n"
+
+	leads := [2]int{}
+	metrics := c.Metrics()
+	for _, m := range metrics {
+		values := [2]string{
+			this.formatValue(l, m.Name, m.Values[0]),
+			this.formatValue(l, m.Name, m.Values[1]),
+		}
+
+		leader := m.Leader()
+		if leader != -1 {
+			values[leader] += " 👑"
+			leads[leader]++
+		}
+
+		txt += l.T(
+			"compare.metric", l.T(comparedTitles[m.Name]), values[0], values[1],
+		) + "\n"
+	}
+
+	txt += "\n"
+	switch {
+	case leads[0] > leads[1]:
+		txt += l.T("compare.leads", names[0], leads[0], len(metrics))
+	case leads[1] > leads[0]:
+		txt += l.T("compare.leads", names[1], leads[1], len(metrics))
+	default:
+		txt += l.T("compare.tie")
+	}
+
+	msg := tgbotapi.NewMessage(ctx.GetChatId(), txt)
+
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				l.T("compare.swap"),
+				fmt.Sprintf("/compare/result/%d/%d", second.ID, first.ID),
+			),
+			tgbotapi.NewInlineKeyboardButtonData(
+				l.T("common.refresh"),
+				fmt.Sprintf("/compare/result/%d/%d", first.ID, second.ID),
+			),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				l.T("common.back"),
+				"/compare",
+			),
+		),
+	)
+
+	return reply(ctx, msg), nil
+}
+
+// comparedTitles are the keys of the compared metrics' texts.
+var comparedTitles = map[string]string{
+	core.ComparedRank:       "compare.rank",
+	core.ComparedScore:      "compare.score",
+	core.ComparedKills:      "compare.kills",
+	core.ComparedDeaths:     "compare.deaths",
+	core.ComparedKD:         "compare.kd",
+	core.ComparedAccuracy:   "compare.accuracy",
+	core.ComparedBestRank:   "compare.best_rank",
+	core.ComparedRankChange: "compare.rank_change",
+	core.ComparedPlaytime:   "compare.playtime",
+}
+
+func (this *CompareController) formatValue(
+	l i18n.Localizer, name string, value float64,
+) string {
+	switch name {
+	case core.ComparedRank, core.ComparedBestRank:
+		if value >= float64(^uint32(0)) {
+			return "-"
+		}
+		return l.Digits(fmt.Sprintf("#%d", int(value)))
+	case core.ComparedKD:
+		return l.Digits(fmt.Sprintf("%.2f", value))
+	case core.ComparedAccuracy:
+		return l.Digits(fmt.Sprintf("%d%%", int(value)))
+	case core.ComparedRankChange:
+		return l.Digits(fmt.Sprintf("%+d", int(value)))
+	case core.ComparedPlaytime:
+		return l.Duration(time.Duration(value) * time.Second)
+	default:
+		return l.Number(int(value))
+	}
+}
+
+// search prompts for a name with the prompt text when entered through a
+// button and lists the matching players once the name is sent, leaving out
+// the except player, the one already picked.
+func (this *CompareController) search(
+	ctx tgool.Context,
+	prompt string, except core.PlayerId,
+	pickRoute string, searchRoute string, backRoute string,
+) (tgbotapi.Chattable, error) {
+	chatId := ctx.GetChatId()
+
+	l, err := localizer(ctx, this.Locales)
+	if err != nil {
+		return nil, err
+	}
+
+	if ctx.Update().Message == nil {
+		msg := tgbotapi.NewMessage(chatId, l.T(prompt))
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(
+					l.T("common.back"),
+					backRoute,
+				),
+			),
+		)
+
+		return reply(ctx, msg), nil
+	}
+
+	query := strings.TrimSpace(ctx.Update().Message.Text)
+
+	found, err := this.PlayerRepo.SearchByName(query, 20)
+	if err != nil {
+		return nil, err
+	}
+
+	players := make([]core.DbPlayer, 0, len(found))
+	for _, p := range found {
+		if p.ID != except {
+			players = append(players, p)
+		}
+	}
+
+	txt := l.T("compare.results", query)
+	if len(players) == 0 {
+		txt += "\n\n" + l.T("compare.no_results")
+	} else {
+		txt += "\n\n" + l.T("compare.pick_result")
+	}
+
+	return this.pickMessage(
+		ctx, l, txt, players, pickRoute, searchRoute, backRoute,
+	), nil
+}
+
+// pickMessage lists players to pick from, where pickRoute is formatted with
+// the picked player's id.
+func (this *CompareController) pickMessage(
+	ctx tgool.Context, l i18n.Localizer,
+	txt string, players []core.DbPlayer,
+	pickRoute string, searchRoute string, backRoute string,
+) tgbotapi.MessageConfig {
+	msg := tgbotapi.NewMessage(ctx.GetChatId(), txt)
+
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0)
+
+	for i := 0; i < len(players); i += 2 {
+		row := tgbotapi.NewInlineKeyboardRow()
+
+		for _, p := range players[i:min(i+2, len(players))] {
+			row = append(row,
+				tgbotapi.NewInlineKeyboardButtonData(
+					fmt.Sprintf(
+						"%s %s", core.CountryFlag(p.Player.Country), p.Player.Name,
+					),
+					fmt.Sprintf(pickRoute, p.ID),
+				),
+			)
+		}
+
+		rows = append(rows, row)
+	}
+
+	rows = append(rows,
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				l.T("compare.search"),
+				searchRoute,
+			),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				l.T("common.back"),
+				backRoute,
+			),
+		),
+	)
+
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+
+	return msg
+}
+
+func (this *CompareController) getPlayerParam(
+	ctx tgool.Context, name string,
+) (core.DbPlayer, error) {
+	playerId, err := strconv.Atoi(ctx.Params().ByName(name))
+	if err != nil {
+		return core.DbPlayer{}, err
+	}
+
+	return this.PlayerRepo.GetPlayer(core.PlayerId(playerId))
+}
+
+var _ tgool.Controller = (*CompareController)(nil)
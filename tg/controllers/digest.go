@@ -0,0 +1,335 @@
+package controllers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/thekhanj/csdmpro/tg/repo"
+	"github.com/thekhanj/csdmpro/tg/service"
+	"github.com/thekhanj/tgool"
+)
+
+// Timezones offered as buttons, any other one can be sent by name.
+var COMMON_TIMEZONES = []string{
+	"Asia/Tehran", "Asia/Dubai", "Asia/Istanbul", "Europe/Berlin",
+	"Europe/London", "Europe/Moscow", "America/New_York", "UTC",
+}
+
+type DigestController struct {
+	SettingsRepo  *repo.SettingsRepo
+	DigestService *service.DigestService
+	Locales       *service.LocaleService
+	Permissions   *service.PermissionService
+}
+
+func (this *DigestController) AddRoutes(b *tgool.RouterBuilder) {
+	b.SetPrefixRoute("/digest").
+		AddMethod("", "Index").
+		AddMethod("/frequency/:frequency", "SetFrequency").
+		AddMethod("/time/:minutes", "SetTime").
+		AddMethod("/timezones", "TimezonesIndex").
+		AddMethod("/timezones/:index", "SetCommonTimezone").
+		AddMethod("/timezone", "SetTimezone").WithBody().
+		AddMethod("/preview", "Preview")
+}
+
+func (this *DigestController) Index(
+	ctx tgool.Context,
+) (tgbotapi.Chattable, error) {
+	chatId := ctx.GetChatId()
+
+	l, err := localizer(ctx, this.Locales)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := this.SettingsRepo.Get(chatId)
+	if err != nil {
+		return nil, err
+	}
+
+	var frequency string
+	switch s.Digest {
+	case repo.DigestDaily:
+		frequency = l.T("digest.every_day")
+	case repo.DigestWeekly:
+		frequency = l.T("digest.every_monday")
+	default:
+		frequency = l.T("digest.off")
+	}
+
+	txt := l.T(
+		"digest.title",
+		service.DIGEST_TOP_N,
+		frequency,
+		l.Digits(this.formatTime(s.DigestTime)),
+		s.Timezone,
+	)
+
+	msg := tgbotapi.NewMessage(chatId, txt)
+
+	frequencyButton := func(f repo.DigestFrequency, title string) tgbotapi.InlineKeyboardButton {
+		if s.Digest == f {
+			title = "✅ " + title
+		}
+
+		return tgbotapi.NewInlineKeyboardButtonData(
+			title, fmt.Sprintf("/digest/frequency/%s", f),
+		)
+	}
+
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			frequencyButton(repo.DigestOff, l.T("digest.off_button")),
+			frequencyButton(repo.DigestDaily, l.T("digest.daily")),
+			frequencyButton(repo.DigestWeekly, l.T("digest.weekly")),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				l.T("digest.earlier"),
+				fmt.Sprintf("/digest/time/%d", (s.DigestTime+23*60)%(24*60)),
+			),
+			tgbotapi.NewInlineKeyboardButtonData(
+				l.T("digest.later"),
+				fmt.Sprintf("/digest/time/%d", (s.DigestTime+60)%(24*60)),
+			),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				l.T("digest.change_timezone"),
+				"/digest/timezones",
+			),
+			tgbotapi.NewInlineKeyboardButtonData(
+				l.T("digest.preview"),
+				"/digest/preview",
+			),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				l.T("common.back"),
+				"/start",
+			),
+		),
+	)
+
+	return reply(ctx, msg), nil
+}
+
+func (this *DigestController) SetFrequency(
+	ctx tgool.Context,
+) (tgbotapi.Chattable, error) {
+	frequency := repo.DigestFrequency(ctx.Params().ByName("frequency"))
+	switch frequency {
+	case repo.DigestOff, repo.DigestDaily, repo.DigestWeekly:
+	default:
+		return nil, fmt.Errorf("unknown digest frequency: %s", frequency)
+	}
+
+	err := checkCanEdit(ctx, this.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	err = this.SettingsRepo.SetDigest(ctx.GetChatId(), frequency)
+	if err != nil {
+		return nil, err
+	}
+
+	return this.Index(ctx)
+}
+
+func (this *DigestController) SetTime(
+	ctx tgool.Context,
+) (tgbotapi.Chattable, error) {
+	minutes, err := strconv.Atoi(ctx.Params().ByName("minutes"))
+	if err != nil {
+		return nil, err
+	}
+	if minutes < 0 || minutes >= 24*60 {
+		return nil, fmt.Errorf("invalid digest time: %d", minutes)
+	}
+
+	err = checkCanEdit(ctx, this.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	err = this.SettingsRepo.SetDigestTime(ctx.GetChatId(), minutes)
+	if err != nil {
+		return nil, err
+	}
+
+	return this.Index(ctx)
+}
+
+func (this *DigestController) TimezonesIndex(
+	ctx tgool.Context,
+) (tgbotapi.Chattable, error) {
+	l, err := localizer(ctx, this.Locales)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := tgbotapi.NewMessage(ctx.GetChatId(), l.T("digest.pick_timezone"))
+
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0)
+
+	for i := 0; i < len(COMMON_TIMEZONES); i += 2 {
+		row := tgbotapi.NewInlineKeyboardRow()
+
+		for j := i; j < min(i+2, len(COMMON_TIMEZONES)); j++ {
+			row = append(row,
+				tgbotapi.NewInlineKeyboardButtonData(
+					COMMON_TIMEZONES[j],
+					fmt.Sprintf("/digest/timezones/%d", j),
+				),
+			)
+		}
+
+		rows = append(rows, row)
+	}
+
+	rows = append(rows,
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				l.T("digest.type_timezone"),
+				"/digest/timezone",
+			),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				l.T("common.back"),
+				"/digest",
+			),
+		),
+	)
+
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+
+	return reply(ctx, msg), nil
+}
+
+func (this *DigestController) SetCommonTimezone(
+	ctx tgool.Context,
+) (tgbotapi.Chattable, error) {
+	index, err := strconv.Atoi(ctx.Params().ByName("index"))
+	if err != nil {
+		return nil, err
+	}
+	if index < 0 || index >= len(COMMON_TIMEZONES) {
+		return nil, fmt.Errorf("invalid timezone index: %d", index)
+	}
+
+	err = checkCanEdit(ctx, this.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	err = this.SettingsRepo.SetTimezone(ctx.GetChatId(), COMMON_TIMEZONES[index])
+	if err != nil {
+		return nil, err
+	}
+
+	return this.Index(ctx)
+}
+
+// SetTimezone prompts for a timezone name when entered through a button and
+// saves it once sent.
+func (this *DigestController) SetTimezone(
+	ctx tgool.Context,
+) (tgbotapi.Chattable, error) {
+	chatId := ctx.GetChatId()
+
+	l, err := localizer(ctx, this.Locales)
+	if err != nil {
+		return nil, err
+	}
+
+	backKeyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				l.T("common.back"),
+				"/digest/timezones",
+			),
+		),
+	)
+
+	if ctx.Update().Message == nil {
+		msg := tgbotapi.NewMessage(chatId, l.T("digest.enter_timezone"))
+		msg.ReplyMarkup = backKeyboard
+
+		return reply(ctx, msg), nil
+	}
+
+	name := strings.TrimSpace(ctx.Update().Message.Text)
+
+	// "Local" would silently mean the server's timezone
+	_, err = time.LoadLocation(name)
+	if err != nil || name == "" || name == "Local" {
+		msg := tgbotapi.NewMessage(chatId, l.T("digest.unknown_timezone", name))
+		msg.ReplyMarkup = backKeyboard
+
+		return reply(ctx, msg), nil
+	}
+
+	err = checkCanEdit(ctx, this.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	err = this.SettingsRepo.SetTimezone(chatId, name)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx.Redirect("/digest")
+
+	return this.Index(ctx)
+}
+
+func (this *DigestController) Preview(
+	ctx tgool.Context,
+) (tgbotapi.Chattable, error) {
+	chatId := ctx.GetChatId()
+
+	l, err := localizer(ctx, this.Locales)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := this.SettingsRepo.Get(chatId)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.Digest == repo.DigestOff {
+		s.Digest = repo.DigestDaily
+	}
+
+	d, err := this.DigestService.Build(s, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	msg := tgbotapi.NewMessage(chatId, d.Text(l, s.Location()))
+
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				l.T("common.back"),
+				"/digest",
+			),
+		),
+	)
+
+	return reply(ctx, msg), nil
+}
+
+func (this *DigestController) formatTime(minutes int) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+var _ tgool.Controller = (*DigestController)(nil)
@@ -0,0 +1,90 @@
+package controllers
+
+import (
+	"errors"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/thekhanj/csdmpro/tg/i18n"
+	"github.com/thekhanj/csdmpro/tg/service"
+	"github.com/thekhanj/tgool"
+)
+
+var ERR_UNSUPPORTED_LANGUAGE error = errors.New("unsupported language")
+
+type LanguageController struct {
+	Locales     *service.LocaleService
+	Permissions *service.PermissionService
+}
+
+func (this *LanguageController) AddRoutes(b *tgool.RouterBuilder) {
+	b.SetPrefixRoute("/language").
+		AddMethod("", "Index").
+		AddMethod("/:lang", "SetLanguage")
+}
+
+func (this *LanguageController) Index(
+	ctx tgool.Context,
+) (tgbotapi.Chattable, error) {
+	l, err := localizer(ctx, this.Locales)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := tgbotapi.NewMessage(ctx.GetChatId(), l.T("language.title"))
+
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0, len(i18n.LANGS)+1)
+
+	for _, lang := range i18n.LANGS {
+		title := lang.Title()
+		if lang == l.Lang() {
+			title = "✅ " + title
+		}
+
+		rows = append(rows,
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(
+					title,
+					"/language/"+string(lang),
+				),
+			),
+		)
+	}
+
+	rows = append(rows,
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				l.T("common.back"),
+				"/start",
+			),
+		),
+	)
+
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+
+	return reply(ctx, msg), nil
+}
+
+func (this *LanguageController) SetLanguage(
+	ctx tgool.Context,
+) (tgbotapi.Chattable, error) {
+	lang, ok := i18n.ParseLang(ctx.Params().ByName("lang"))
+	if !ok {
+		return nil, ERR_UNSUPPORTED_LANGUAGE
+	}
+
+	err := checkCanEdit(ctx, this.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	err = this.Locales.SetLanguage(ctx.GetChatId(), lang)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx.Redirect("/language")
+
+	return this.Index(ctx)
+}
+
+var _ tgool.Controller = (*LanguageController)(nil)
@@ -0,0 +1,194 @@
+package controllers
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/thekhanj/csdmpro/core"
+	"github.com/thekhanj/csdmpro/tg/i18n"
+	"github.com/thekhanj/csdmpro/tg/service"
+	"github.com/thekhanj/tgool"
+)
+
+type LeaderboardsController struct {
+	PlayerRepo *core.PlayerRepo
+	Locales    *service.LocaleService
+}
+
+func (this *LeaderboardsController) AddRoutes(b *tgool.RouterBuilder) {
+	b.SetPrefixRoute("/leaderboards").
+		AddMethod("", "Index").
+		AddMethod("/:metric/:page", "MetricIndex")
+}
+
+// metricTitles and metricDescriptions are the keys of the metrics' texts.
+var metricTitles = map[core.Metric]string{
+	core.MetricKD:         "leaderboards.kd",
+	core.MetricAccuracy:   "leaderboards.accuracy",
+	core.MetricKills:      "leaderboards.kills",
+	core.MetricScoreToday: "leaderboards.score_today",
+	core.MetricScoreWeek:  "leaderboards.score_week",
+	core.MetricPlaytime:   "leaderboards.playtime",
+}
+
+var metricDescriptions = map[core.Metric]string{
+	core.MetricKD:         "leaderboards.kd_description",
+	core.MetricAccuracy:   "leaderboards.accuracy_description",
+	core.MetricKills:      "leaderboards.kills_description",
+	core.MetricScoreToday: "leaderboards.score_today_description",
+	core.MetricScoreWeek:  "leaderboards.score_week_description",
+	core.MetricPlaytime:   "leaderboards.playtime_description",
+}
+
+// metricDescriptionArgs are the arguments of the metrics' descriptions.
+var metricDescriptionArgs = map[core.Metric][]any{
+	core.MetricKD:       {core.LEADERBOARD_KD_MIN_KILLS},
+	core.MetricAccuracy: {core.LEADERBOARD_ACCURACY_MIN_KILLS},
+}
+
+func (this *LeaderboardsController) Index(
+	ctx tgool.Context,
+) (tgbotapi.Chattable, error) {
+	l, err := localizer(ctx, this.Locales)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := tgbotapi.NewMessage(ctx.GetChatId(), l.T("leaderboards.title"))
+
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0)
+
+	for i := 0; i < len(core.Metrics); i += 2 {
+		row := tgbotapi.NewInlineKeyboardRow()
+
+		for _, metric := range core.Metrics[i:min(i+2, len(core.Metrics))] {
+			row = append(row,
+				tgbotapi.NewInlineKeyboardButtonData(
+					l.T(metricTitles[metric]),
+					fmt.Sprintf("/leaderboards/%s/0", metric),
+				),
+			)
+		}
+
+		rows = append(rows, row)
+	}
+
+	rows = append(rows,
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				l.T("common.back"),
+				"/start",
+			),
+		),
+	)
+
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+
+	return reply(ctx, msg), nil
+}
+
+func (this *LeaderboardsController) MetricIndex(
+	ctx tgool.Context,
+) (tgbotapi.Chattable, error) {
+	l, err := localizer(ctx, this.Locales)
+	if err != nil {
+		return nil, err
+	}
+
+	metric := core.Metric(ctx.Params().ByName("metric"))
+	page, err := strconv.Atoi(ctx.Params().ByName("page"))
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := this.PlayerRepo.Leaderboard(metric, page*20, 20+1)
+	if err != nil {
+		return nil, err
+	}
+
+	txt := fmt.Sprintf(
+		"%s\n%s\n\n",
+		l.T(metricTitles[metric]),
+		l.T(metricDescriptions[metric], metricDescriptionArgs[metric]...),
+	)
+	if len(entries) == 0 {
+		txt += l.T("leaderboards.empty")
+	}
+
+	for i, e := range entries[:min(len(entries), 20)] {
+		txt += fmt.Sprintf(
+			"%s. %s %s — %s\n",
+			l.Number(page*20+i+1),
+			core.CountryFlag(e.DbPlayer.Player.Country),
+			e.DbPlayer.Player.Name,
+			this.formatValue(l, metric, e.Value),
+		)
+	}
+
+	msg := tgbotapi.NewMessage(ctx.GetChatId(), txt)
+
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0)
+
+	paginationButtons := []tgbotapi.InlineKeyboardButton{}
+	if page != 0 {
+		paginationButtons = append(
+			paginationButtons, tgbotapi.NewInlineKeyboardButtonData(
+				l.T("common.previous_page"),
+				fmt.Sprintf("/leaderboards/%s/%d", metric, page-1),
+			),
+		)
+	}
+
+	if len(entries) == 20+1 {
+		paginationButtons = append(
+			paginationButtons, tgbotapi.NewInlineKeyboardButtonData(
+				l.T("common.next_page"),
+				fmt.Sprintf("/leaderboards/%s/%d", metric, page+1),
+			),
+		)
+	}
+
+	if len(paginationButtons) != 0 {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(paginationButtons...))
+	}
+
+	rows = append(rows,
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				l.T("common.refresh_list"),
+				fmt.Sprintf("/leaderboards/%s/%d", metric, page),
+			),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				l.T("common.back"),
+				"/leaderboards",
+			),
+		),
+	)
+
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+
+	return reply(ctx, msg), nil
+}
+
+func (this *LeaderboardsController) formatValue(
+	l i18n.Localizer, metric core.Metric, value float64,
+) string {
+	switch metric {
+	case core.MetricKD:
+		return l.Digits(fmt.Sprintf("%.2f", value))
+	case core.MetricAccuracy:
+		return l.Digits(fmt.Sprintf("%d%%", int(value)))
+	case core.MetricScoreToday, core.MetricScoreWeek:
+		return l.Digits(fmt.Sprintf("+%d", int(value)))
+	case core.MetricPlaytime:
+		return l.Duration(time.Duration(value) * time.Second)
+	default:
+		return l.Number(int(value))
+	}
+}
+
+var _ tgool.Controller = (*LeaderboardsController)(nil)
@@ -0,0 +1,14 @@
+package controllers
+
+import (
+	"github.com/thekhanj/csdmpro/tg/i18n"
+	"github.com/thekhanj/csdmpro/tg/service"
+	"github.com/thekhanj/tgool"
+)
+
+// localizer localizes into the language of the chat the update came from.
+func localizer(
+	ctx tgool.Context, locales *service.LocaleService,
+) (i18n.Localizer, error) {
+	return locales.ForUser(ctx.GetChatId(), ctx.GetFrom())
+}
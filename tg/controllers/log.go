@@ -0,0 +1,31 @@
+package controllers
+
+import (
+	"log/slog"
+	"strings"
+
+	"github.com/thekhanj/csdmpro/logging"
+	"github.com/thekhanj/tgool"
+)
+
+var controllersLog = logging.Component("controllers")
+
+// requestLog logs along with the chat and the route of the request.
+func requestLog(ctx tgool.Context) *slog.Logger {
+	return controllersLog.With("chat_id", ctx.GetChatId(), "route", Route(ctx))
+}
+
+// Route names the route of the request. Text sent to an input route is
+// named after the route, as it's whatever the user typed.
+func Route(ctx tgool.Context) string {
+	if ctx.Update().CallbackQuery != nil {
+		return ctx.GetRoute()
+	}
+
+	route := ctx.GetRoute()
+	if strings.HasPrefix(route, "/") {
+		return strings.Fields(route)[0]
+	}
+
+	return ctx.ChatsState().GetChat(ctx.GetChatId()).GetPath() + " (input)"
+}
@@ -2,32 +2,47 @@ package controllers
 
 import (
 	"fmt"
+	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/thekhanj/csdmpro/core"
+	"github.com/thekhanj/csdmpro/tg/i18n"
+	"github.com/thekhanj/csdmpro/tg/repo"
+	"github.com/thekhanj/csdmpro/tg/service"
 	"github.com/thekhanj/tgool"
 )
 
 type OnlinesController struct {
-	PlayerRepo *core.PlayerRepo
+	PlayerRepo       *core.PlayerRepo
+	LiveBoardRepo    *repo.LiveBoardRepo
+	LiveBoardService *service.LiveBoardService
+	Permissions      *service.PermissionService
+	Locales          *service.LocaleService
 }
 
 func (this *OnlinesController) AddRoutes(b *tgool.RouterBuilder) {
 	b.SetPrefixRoute("/onlines/").
-		AddMethod("", "Index")
+		AddMethod("", "Index").
+		AddMethod("live", "StartLiveBoard").
+		AddMethod("live/stop", "StopLiveBoard")
 }
 
 func (this *OnlinesController) Index(
 	ctx tgool.Context,
 ) (tgbotapi.Chattable, error) {
+	l, err := localizer(ctx, this.Locales)
+	if err != nil {
+		return nil, err
+	}
+
 	players, err := this.PlayerRepo.Onlines()
 	if err != nil {
 		return nil, err
 	}
 
-	txt := "📊 Live player stats from the battlefield — updated in real-time."
+	txt := l.T("onlines.title")
 	if len(players) == 0 {
-		txt += "\n\nThere are no players online!"
+		txt += "\n\n" + l.T("onlines.empty")
 	}
 
 	msg := tgbotapi.NewMessage(ctx.GetChatId(), txt)
@@ -39,13 +54,17 @@ func (this *OnlinesController) Index(
 	) []tgbotapi.InlineKeyboardButton {
 		row := tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData(
-				fmt.Sprintf("🟢 %s", p1.Player.Name),
+				fmt.Sprintf(
+					"🟢 %s %s", core.CountryFlag(p1.Player.Country), p1.Player.Name,
+				),
 				fmt.Sprintf("/players/%d", p1.ID),
 			),
 		)
 		if p2 != nil {
 			row = append(row, tgbotapi.NewInlineKeyboardButtonData(
-				fmt.Sprintf("🟢 %s", p2.Player.Name),
+				fmt.Sprintf(
+					"🟢 %s %s", core.CountryFlag(p2.Player.Country), p2.Player.Name,
+				),
 				fmt.Sprintf("/players/%d", p2.ID),
 			),
 			)
@@ -66,16 +85,34 @@ func (this *OnlinesController) Index(
 		rows = append(rows, row)
 	}
 
+	_, err = this.LiveBoardRepo.Get(ctx.GetChatId())
+	hasBoard := err == nil
+	if err != nil && err != repo.ERR_LIVE_BOARD_NOT_FOUND {
+		return nil, err
+	}
+
+	liveBoardButton := tgbotapi.NewInlineKeyboardButtonData(
+		l.T("onlines.live_board"),
+		"/onlines/live",
+	)
+	if hasBoard {
+		liveBoardButton = tgbotapi.NewInlineKeyboardButtonData(
+			l.T("onlines.stop_live_board"),
+			"/onlines/live/stop",
+		)
+	}
+
 	rows = append(rows,
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData(
-				"🔄 Refresh List",
+				l.T("common.refresh_list"),
 				"/onlines",
 			),
+			liveBoardButton,
 		),
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData(
-				"🔙 Back",
+				l.T("common.back"),
 				"/start",
 			),
 		),
@@ -83,7 +120,136 @@ func (this *OnlinesController) Index(
 
 	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
 
-	return msg, nil
+	return reply(ctx, msg), nil
+}
+
+// StartLiveBoard sends and pins a message that's kept updated with the online
+// players, replacing the chat's previous live board if any.
+func (this *OnlinesController) StartLiveBoard(
+	ctx tgool.Context,
+) (tgbotapi.Chattable, error) {
+	chatId := ctx.GetChatId()
+
+	l, err := localizer(ctx, this.Locales)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkCanEdit(ctx, this.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	txt, err := this.LiveBoardService.Render(time.Now(), l)
+	if err != nil {
+		return nil, err
+	}
+
+	err = this.stopLiveBoard(ctx, l)
+	if err != nil {
+		return nil, err
+	}
+
+	board, err := ctx.Bot().Send(tgbotapi.NewMessage(chatId, txt))
+	if err != nil {
+		return nil, err
+	}
+
+	err = this.LiveBoardRepo.Set(repo.LiveBoard{
+		ChatId:    chatId,
+		MessageId: board.MessageID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	answer := l.T("onlines.live_board_started")
+
+	_, err = ctx.Bot().Request(tgbotapi.PinChatMessageConfig{
+		ChatID:              chatId,
+		MessageID:           board.MessageID,
+		DisableNotification: true,
+	})
+	if err != nil {
+		requestLog(ctx).Warn("failed pinning live board", "err", err)
+		answer = l.T("onlines.live_board_started_unpinned")
+	}
+
+	ctx.Bot().Request(
+		tgbotapi.NewCallback(ctx.Update().CallbackQuery.ID, answer),
+	)
+
+	ctx.Redirect("/onlines")
+
+	return this.Index(ctx)
+}
+
+func (this *OnlinesController) StopLiveBoard(
+	ctx tgool.Context,
+) (tgbotapi.Chattable, error) {
+	l, err := localizer(ctx, this.Locales)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkCanEdit(ctx, this.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	err = this.stopLiveBoard(ctx, l)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx.Bot().Request(
+		tgbotapi.NewCallback(
+			ctx.Update().CallbackQuery.ID, l.T("onlines.live_board_stopped"),
+		),
+	)
+
+	ctx.Redirect("/onlines")
+
+	return this.Index(ctx)
+}
+
+// stopLiveBoard unpins the chat's live board and leaves it as the last
+// snapshot, it's fine for the chat not to have one.
+func (this *OnlinesController) stopLiveBoard(
+	ctx tgool.Context, l i18n.Localizer,
+) error {
+	chatId := ctx.GetChatId()
+
+	board, err := this.LiveBoardRepo.Get(chatId)
+	if err == repo.ERR_LIVE_BOARD_NOT_FOUND {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	err = this.LiveBoardRepo.Remove(chatId)
+	if err != nil {
+		return err
+	}
+
+	// the board may be deleted or unpinned already, nothing to do about it
+	ctx.Bot().Request(tgbotapi.UnpinChatMessageConfig{
+		ChatID:    chatId,
+		MessageID: board.MessageId,
+	})
+	ctx.Bot().Request(tgbotapi.NewEditMessageReplyMarkup(
+		chatId, board.MessageId, tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(
+					l.T("onlines.live_board_ended"),
+					"/onlines",
+				),
+			),
+		),
+	))
+
+	return nil
 }
 
 var _ tgool.Controller = (*OnlinesController)(nil)
@@ -0,0 +1,41 @@
+package controllers
+
+import (
+	"github.com/thekhanj/csdmpro/tg/service"
+	"github.com/thekhanj/tgool"
+)
+
+// checkCanEdit fails unless the user is allowed to change the chat's shared
+// watchlist and settings, which in groups is up to the admins.
+func checkCanEdit(
+	ctx tgool.Context, permissions *service.PermissionService,
+) error {
+	canEdit, err := permissions.CanEdit(
+		ctx.Bot(), ctx.GetMessage(), ctx.GetFrom(),
+	)
+	if err != nil {
+		return err
+	}
+	if !canEdit {
+		return service.ERR_ADMINS_ONLY
+	}
+
+	return nil
+}
+
+// checkIsAdmin fails unless the user is an admin of the chat.
+func checkIsAdmin(
+	ctx tgool.Context, permissions *service.PermissionService,
+) error {
+	isAdmin, err := permissions.IsAdmin(
+		ctx.Bot(), ctx.GetMessage(), ctx.GetFrom(),
+	)
+	if err != nil {
+		return err
+	}
+	if !isAdmin {
+		return service.ERR_ADMINS_ONLY
+	}
+
+	return nil
+}
@@ -0,0 +1,34 @@
+package controllers
+
+import (
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/thekhanj/tgool"
+)
+
+// reply turns msg into an edit of the message whose inline button was
+// pressed, so navigating the menus doesn't flood the chat with stale ones.
+// Commands and text sent by the user are answered with a new message, and so
+// are buttons of photos and documents, which have no text to edit.
+func reply(ctx tgool.Context, msg tgbotapi.MessageConfig) tgbotapi.Chattable {
+	query := ctx.Update().CallbackQuery
+	if query == nil || query.Message == nil || query.Message.Text == "" {
+		return msg
+	}
+
+	markup, isInline := msg.ReplyMarkup.(tgbotapi.InlineKeyboardMarkup)
+	if msg.ReplyMarkup != nil && !isInline {
+		return msg
+	}
+
+	edit := tgbotapi.NewEditMessageText(
+		query.Message.Chat.ID, query.Message.MessageID, msg.Text,
+	)
+	edit.ParseMode = msg.ParseMode
+	edit.Entities = msg.Entities
+	edit.DisableWebPagePreview = msg.DisableWebPagePreview
+	if isInline {
+		edit.ReplyMarkup = &markup
+	}
+
+	return edit
+}
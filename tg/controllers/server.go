@@ -0,0 +1,202 @@
+package controllers
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/thekhanj/csdmpro/chart"
+	"github.com/thekhanj/csdmpro/core"
+	"github.com/thekhanj/csdmpro/tg/repo"
+	"github.com/thekhanj/csdmpro/tg/service"
+	"github.com/thekhanj/tgool"
+)
+
+const (
+	POPULATION_DAYS       = 7
+	POPULATION_PEAK_RATIO = 0.8
+)
+
+type ServerController struct {
+	PlayerRepo   *core.PlayerRepo
+	SettingsRepo *repo.SettingsRepo
+	Locales      *service.LocaleService
+}
+
+func (this *ServerController) AddRoutes(b *tgool.RouterBuilder) {
+	b.SetPrefixRoute("/server").
+		AddMethod("", "Index").
+		AddMethod("/chart", "Chart")
+}
+
+func (this *ServerController) Index(
+	ctx tgool.Context,
+) (tgbotapi.Chattable, error) {
+	l, err := localizer(ctx, this.Locales)
+	if err != nil {
+		return nil, err
+	}
+
+	settings, err := this.SettingsRepo.Get(ctx.GetChatId())
+	if err != nil {
+		return nil, err
+	}
+
+	loc := settings.Location()
+	stats, err := this.PlayerRepo.PopulationStats(POPULATION_DAYS, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	onlines, err := this.PlayerRepo.Onlines()
+	if err != nil {
+		return nil, err
+	}
+
+	txt := l.T("server.title", len(onlines), stats.AverageConcurrent)
+
+	if stats.Peak.Count != 0 {
+		peak := stats.Peak.Time.In(loc)
+		txt += "\n" + l.T(
+			"server.peak",
+			stats.Peak.Count, l.Day(peak)+l.Digits(peak.Format(" 15:04")),
+		)
+
+		best := stats.BestHour()
+		txt += "\n" + l.T(
+			"server.busiest_hours",
+			l.Digits(formatHourRanges(stats.PeakHours(POPULATION_PEAK_RATIO))),
+		)
+		txt += "\n" + l.T(
+			"server.best_time",
+			l.Digits(formatHourRanges([]int{best})), stats.HourlyAverage[best],
+		)
+	}
+
+	txt += "\n\n" + l.T("server.daily_uniques") + "\n"
+	first := core.StartOfDay(time.Now().In(loc)).
+		AddDate(0, 0, -(POPULATION_DAYS - 1))
+	for day, count := range stats.DailyUniques {
+		txt += fmt.Sprintf(
+			"%s: %s\n", l.Day(first.AddDate(0, 0, day)), l.Number(count),
+		)
+	}
+
+	txt += "\n" + l.T(
+		"server.period",
+		POPULATION_DAYS, l.Digits(time.Now().In(loc).Format("-07:00")),
+	)
+
+	msg := tgbotapi.NewMessage(ctx.GetChatId(), txt)
+
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				l.T("server.hourly_chart"),
+				"/server/chart",
+			),
+			tgbotapi.NewInlineKeyboardButtonData(
+				l.T("common.refresh"),
+				"/server",
+			),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				l.T("common.back"),
+				"/start",
+			),
+		),
+	)
+
+	return reply(ctx, msg), nil
+}
+
+func (this *ServerController) Chart(
+	ctx tgool.Context,
+) (tgbotapi.Chattable, error) {
+	chatId := ctx.GetChatId()
+
+	l, err := localizer(ctx, this.Locales)
+	if err != nil {
+		return nil, err
+	}
+
+	settings, err := this.SettingsRepo.Get(chatId)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := this.PlayerRepo.PopulationStats(
+		POPULATION_DAYS, settings.Location(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	bars := make([]chart.Bar, 0, 24)
+	for hour, avg := range stats.HourlyAverage {
+		bars = append(bars, chart.Bar{
+			Label: fmt.Sprintf("%02d", hour),
+			Value: avg,
+		})
+	}
+
+	data, err := chart.Bars(bars, chart.BarOptions{
+		Title: "Average online players per hour",
+		FormatY: func(v float64) string {
+			return fmt.Sprintf("%.1f", v)
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	photo := tgbotapi.NewPhoto(chatId, tgbotapi.FileBytes{
+		Name:  "population.png",
+		Bytes: data,
+	})
+	photo.Caption = l.T("server.chart_caption", POPULATION_DAYS)
+	photo.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				l.T("common.back"),
+				"/server",
+			),
+		),
+	)
+
+	return photo, nil
+}
+
+// formatHourRanges joins consecutive hours into ranges.
+//
+//	Example: [20 21 22 9] -> 09:00–10:00, 20:00–23:00
+func formatHourRanges(hours []int) string {
+	if len(hours) == 0 {
+		return "-"
+	}
+
+	var isIncluded [24]bool
+	for _, hour := range hours {
+		isIncluded[hour] = true
+	}
+
+	ranges := make([]string, 0)
+	for hour := 0; hour < 24; hour++ {
+		if !isIncluded[hour] {
+			continue
+		}
+
+		from := hour
+		for hour+1 < 24 && isIncluded[hour+1] {
+			hour++
+		}
+
+		ranges = append(ranges, fmt.Sprintf("%02d:00–%02d:00", from, (hour+1)%24))
+	}
+
+	return strings.Join(ranges, ", ")
+}
+
+var _ tgool.Controller = (*ServerController)(nil)
@@ -2,10 +2,13 @@ package controllers
 
 import (
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/thekhanj/csdmpro/tg/service"
 	"github.com/thekhanj/tgool"
 )
 
-type StartController struct{}
+type StartController struct {
+	Locales *service.LocaleService
+}
 
 func (this *StartController) AddRoutes(b *tgool.RouterBuilder) {
 	b.SetPrefixRoute("/start").
@@ -15,35 +18,63 @@ func (this *StartController) AddRoutes(b *tgool.RouterBuilder) {
 func (this *StartController) Index(
 	ctx tgool.Context,
 ) (tgbotapi.Chattable, error) {
-	msg := tgbotapi.NewMessage(
-		ctx.GetChatId(),
-		`👋 Welcome to the csdmpro Bot!
-
-Track your performance, keep an eye on your watchlist, or check who's online — all in one place.
+	l, err := localizer(ctx, this.Locales)
+	if err != nil {
+		return nil, err
+	}
 
-Choose a page to get started 👇`,
-	)
+	msg := tgbotapi.NewMessage(ctx.GetChatId(), l.T("start.welcome"))
 
 	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData(
-				"📊 Stats",
+				l.T("start.stats"),
 				"/stats/0",
 			),
 			tgbotapi.NewInlineKeyboardButtonData(
-				"🟢 Online Players",
+				l.T("start.onlines"),
 				"/onlines",
 			),
 		),
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData(
-				"👁️ Watchlist",
+				l.T("start.watchlist"),
 				"/watchlist",
 			),
+			tgbotapi.NewInlineKeyboardButtonData(
+				l.T("start.leaderboards"),
+				"/leaderboards",
+			),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				l.T("start.compare"),
+				"/compare",
+			),
+			tgbotapi.NewInlineKeyboardButtonData(
+				l.T("start.server"),
+				"/server",
+			),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				l.T("start.digest"),
+				"/digest",
+			),
+			tgbotapi.NewInlineKeyboardButtonData(
+				l.T("start.alerts"),
+				"/alerts",
+			),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				l.T("start.language"),
+				"/language",
+			),
 		),
 	)
 
-	return msg, nil
+	return reply(ctx, msg), nil
 }
 
 var _ tgool.Controller = (*StartController)(nil)
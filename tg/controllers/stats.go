@@ -1,28 +1,48 @@
 package controllers
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
+	"strings"
+	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/thekhanj/csdmpro/chart"
 	"github.com/thekhanj/csdmpro/core"
+	"github.com/thekhanj/csdmpro/tg/i18n"
+	"github.com/thekhanj/csdmpro/tg/repo"
+	"github.com/thekhanj/csdmpro/tg/service"
 	"github.com/thekhanj/tgool"
 )
 
 type StatsController struct {
-	PlayerRepo *core.PlayerRepo
+	PlayerRepo   *core.PlayerRepo
+	SettingsRepo *repo.SettingsRepo
+	Locales      *service.LocaleService
 }
 
 func (this *StatsController) AddRoutes(b *tgool.RouterBuilder) {
 	b.SetPrefixRoute("/stats/:page").
 		AddMethod("", "Index").
+		SetPrefixRoute("/countries/:page").
+		AddMethod("", "CountriesIndex").
+		SetPrefixRoute("/country/:country/:page").
+		AddMethod("", "CountryIndex").
 		SetPrefixRoute("/players").
-		AddMethod("/:playerId", "PlayerIndex")
+		AddMethod("/:playerId", "PlayerIndex").
+		AddMethod("/:playerId/charts/:chart", "PlayerChart").
+		AddMethod("/:playerId/activity", "PlayerActivity")
 }
 
 func (this *StatsController) Index(
 	ctx tgool.Context,
 ) (tgbotapi.Chattable, error) {
+	l, err := localizer(ctx, this.Locales)
+	if err != nil {
+		return nil, err
+	}
+
 	page, err := strconv.Atoi(ctx.Params().ByName("page"))
 	if err != nil {
 		return nil, err
@@ -33,11 +53,165 @@ func (this *StatsController) Index(
 		return nil, err
 	}
 
+	msg := tgbotapi.NewMessage(ctx.GetChatId(), l.T("onlines.title"))
+
+	rows := this.getPlayersKeyboard(l, players, page, "/stats/%d")
+
+	rows = append(rows,
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				l.T("stats.by_country"),
+				"/countries/0",
+			),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				l.T("common.refresh_list"),
+				"/stats/0",
+			),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				l.T("common.back"),
+				"/start",
+			),
+		),
+	)
+
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+
+	return reply(ctx, msg), nil
+}
+
+func (this *StatsController) CountriesIndex(
+	ctx tgool.Context,
+) (tgbotapi.Chattable, error) {
+	l, err := localizer(ctx, this.Locales)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := strconv.Atoi(ctx.Params().ByName("page"))
+	if err != nil {
+		return nil, err
+	}
+
+	countries, err := this.PlayerRepo.Countries()
+	if err != nil {
+		return nil, err
+	}
+
+	msg := tgbotapi.NewMessage(ctx.GetChatId(), l.T("stats.pick_country"))
+
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0)
+
+	from := min(page*20, len(countries))
+	to := min(from+20, len(countries))
+	pageCountries := countries[from:to]
+
+	for i := 0; i < len(pageCountries); i += 2 {
+		row := tgbotapi.NewInlineKeyboardRow()
+
+		for _, c := range pageCountries[i:min(i+2, len(pageCountries))] {
+			row = append(row,
+				tgbotapi.NewInlineKeyboardButtonData(
+					fmt.Sprintf(
+						"%s %s (%s)",
+						core.CountryFlag(c.Country), c.Country, l.Number(c.Count),
+					),
+					fmt.Sprintf("/country/%s/0", c.Country),
+				),
+			)
+		}
+
+		rows = append(rows, row)
+	}
+
+	paginationButtons := []tgbotapi.InlineKeyboardButton{}
+	if page != 0 {
+		paginationButtons = append(
+			paginationButtons, tgbotapi.NewInlineKeyboardButtonData(
+				l.T("common.previous_page"),
+				fmt.Sprintf("/countries/%d", page-1),
+			),
+		)
+	}
+
+	if to < len(countries) {
+		paginationButtons = append(
+			paginationButtons, tgbotapi.NewInlineKeyboardButtonData(
+				l.T("common.next_page"),
+				fmt.Sprintf("/countries/%d", page+1),
+			),
+		)
+	}
+
+	if len(paginationButtons) != 0 {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(paginationButtons...))
+	}
+
+	rows = append(rows,
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				l.T("common.back"),
+				"/stats/0",
+			),
+		),
+	)
+
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+
+	return reply(ctx, msg), nil
+}
+
+func (this *StatsController) CountryIndex(
+	ctx tgool.Context,
+) (tgbotapi.Chattable, error) {
+	l, err := localizer(ctx, this.Locales)
+	if err != nil {
+		return nil, err
+	}
+
+	country := ctx.Params().ByName("country")
+	page, err := strconv.Atoi(ctx.Params().ByName("page"))
+	if err != nil {
+		return nil, err
+	}
+
+	players, err := this.PlayerRepo.ListByCountry(country, page*20, 20+1)
+	if err != nil {
+		return nil, err
+	}
+
 	msg := tgbotapi.NewMessage(
 		ctx.GetChatId(),
-		"📊 Live player stats from the battlefield — updated in real-time.",
+		l.T("stats.country_title", core.CountryFlag(country), country),
+	)
+
+	rows := this.getPlayersKeyboard(
+		l, players, page, "/country/"+country+"/%d",
 	)
 
+	rows = append(rows,
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				l.T("common.back"),
+				"/countries/0",
+			),
+		),
+	)
+
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+
+	return reply(ctx, msg), nil
+}
+
+// getPlayersKeyboard lays out a page of players, fetched with one extra
+// player to tell whether a next page exists, followed by the pagination
+// buttons pointing to pageRoute.
+func (this *StatsController) getPlayersKeyboard(
+	l i18n.Localizer, players []core.DbPlayer, page int, pageRoute string,
+) [][]tgbotapi.InlineKeyboardButton {
 	rows := make([][]tgbotapi.InlineKeyboardButton, 0)
 
 	getTwoPlayerKeyboard := func(
@@ -49,7 +223,10 @@ func (this *StatsController) Index(
 		}
 		row := tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData(
-				fmt.Sprintf("(%d) %s", r1, p1.Player.Name),
+				fmt.Sprintf(
+					"(%s) %s %s",
+					l.Number(r1), core.CountryFlag(p1.Player.Country), p1.Player.Name,
+				),
 				fmt.Sprintf("/players/%d", p1.ID),
 			),
 		)
@@ -62,7 +239,10 @@ func (this *StatsController) Index(
 
 			row = append(row,
 				tgbotapi.NewInlineKeyboardButtonData(
-					fmt.Sprintf("(%d) %s", r2, p2.Player.Name),
+					fmt.Sprintf(
+						"(%s) %s %s",
+						l.Number(r2), core.CountryFlag(p2.Player.Country), p2.Player.Name,
+					),
 					fmt.Sprintf("/players/%d", p2.ID),
 				),
 			)
@@ -71,10 +251,11 @@ func (this *StatsController) Index(
 		return row
 	}
 
-	for i := 0; i < len(players)-1; i += 2 {
+	count := min(len(players), 20)
+	for i := 0; i < count; i += 2 {
 		p1 := &players[i]
 		var p2 *core.DbPlayer = nil
-		if i+1 < len(players) {
+		if i+1 < count {
 			p2 = &players[i+1]
 		}
 
@@ -86,18 +267,17 @@ func (this *StatsController) Index(
 	if page != 0 {
 		paginationButtons = append(
 			paginationButtons, tgbotapi.NewInlineKeyboardButtonData(
-				fmt.Sprintf("⬅️ Previous Page"),
-				fmt.Sprintf("/stats/%d", page-1),
+				l.T("common.previous_page"),
+				fmt.Sprintf(pageRoute, page-1),
 			),
 		)
 	}
 
 	if len(players) == 20+1 {
-		fmt.Printf("/watchlist/add-players/%d", page+1)
 		paginationButtons = append(
 			paginationButtons, tgbotapi.NewInlineKeyboardButtonData(
-				fmt.Sprintf("Next Page ➡️"),
-				fmt.Sprintf("/stats/%d", page+1),
+				l.T("common.next_page"),
+				fmt.Sprintf(pageRoute, page+1),
 			),
 		)
 	}
@@ -106,29 +286,17 @@ func (this *StatsController) Index(
 		rows = append(rows, tgbotapi.NewInlineKeyboardRow(paginationButtons...))
 	}
 
-	rows = append(rows,
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData(
-				"🔄 Refresh List",
-				"/stats/0",
-			),
-		),
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData(
-				"🔙 Back",
-				"/start",
-			),
-		),
-	)
-
-	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
-
-	return msg, nil
+	return rows
 }
 
 func (this *StatsController) PlayerIndex(
 	ctx tgool.Context,
 ) (tgbotapi.Chattable, error) {
+	l, err := localizer(ctx, this.Locales)
+	if err != nil {
+		return nil, err
+	}
+
 	playerId, err := strconv.Atoi(ctx.Params().ByName("playerId"))
 	if err != nil {
 		return nil, err
@@ -145,16 +313,10 @@ func (this *StatsController) PlayerIndex(
 	}
 
 	msg := tgbotapi.NewMessage(ctx.GetChatId(),
-		fmt.Sprintf(
-			`🎮 Player %s Stats
-
-🌍 Country: %s
-🏅 Rank: #%d
-📈 Score: %d
-🔫 Kills: %d
-💀 Deaths: %d
-🎯 Accuracy: %d%%`,
+		l.T(
+			"stats.player",
 			p.Player.Name,
+			core.CountryFlag(p.Player.Country),
 			p.Player.Country,
 			rank,
 			p.Player.Score,
@@ -167,19 +329,313 @@ func (this *StatsController) PlayerIndex(
 	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData(
-				"🔄 Refresh",
+				l.T("common.refresh"),
 				fmt.Sprintf("/players/%d", playerId),
 			),
+			tgbotapi.NewInlineKeyboardButtonData(
+				l.T("stats.compare"),
+				fmt.Sprintf("/compare/with/%d", playerId),
+			),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				l.T("stats.rank_chart"),
+				fmt.Sprintf("/players/%d/charts/rank", playerId),
+			),
+			tgbotapi.NewInlineKeyboardButtonData(
+				l.T("stats.score_chart"),
+				fmt.Sprintf("/players/%d/charts/score", playerId),
+			),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				l.T("stats.playtime_chart"),
+				fmt.Sprintf("/players/%d/charts/playtime", playerId),
+			),
+			tgbotapi.NewInlineKeyboardButtonData(
+				l.T("stats.activity"),
+				fmt.Sprintf("/players/%d/activity", playerId),
+			),
 		),
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData(
-				"🔙 Back",
+				l.T("common.back"),
 				"/stats/0",
 			),
 		),
 	)
 
-	return msg, nil
+	return reply(ctx, msg), nil
+}
+
+const (
+	CHART_HISTORY_PERIOD = time.Hour * 24 * 30
+	CHART_PLAYTIME_DAYS  = 14
+)
+
+func (this *StatsController) PlayerChart(
+	ctx tgool.Context,
+) (tgbotapi.Chattable, error) {
+	chatId := ctx.GetChatId()
+
+	l, err := localizer(ctx, this.Locales)
+	if err != nil {
+		return nil, err
+	}
+
+	playerId, err := strconv.Atoi(ctx.Params().ByName("playerId"))
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := this.PlayerRepo.GetPlayer(core.PlayerId(playerId))
+	if err != nil {
+		return nil, err
+	}
+
+	var data []byte
+	var caption string
+
+	switch ctx.Params().ByName("chart") {
+	case "rank":
+		data, err = this.historyChart(p, true)
+		caption = l.T("stats.rank_chart_caption", p.Player.Name)
+	case "score":
+		data, err = this.historyChart(p, false)
+		caption = l.T("stats.score_chart_caption", p.Player.Name)
+	case "playtime":
+		var settings repo.ChatSettings
+		settings, err = this.SettingsRepo.Get(chatId)
+		if err != nil {
+			return nil, err
+		}
+
+		data, err = this.playtimeChart(p, settings.Location())
+		caption = l.T(
+			"stats.playtime_chart_caption", p.Player.Name, CHART_PLAYTIME_DAYS,
+		)
+	default:
+		return nil, ERR_UNKNOWN_CHART
+	}
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				l.T("common.back"),
+				fmt.Sprintf("/players/%d", playerId),
+			),
+		),
+	)
+
+	if err == chart.ERR_NO_DATA {
+		msg := tgbotapi.NewMessage(chatId, l.T("stats.no_chart_data"))
+		msg.ReplyMarkup = keyboard
+
+		return reply(ctx, msg), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	photo := tgbotapi.NewPhoto(chatId, tgbotapi.FileBytes{
+		Name:  "chart.png",
+		Bytes: data,
+	})
+	photo.Caption = caption
+	photo.ReplyMarkup = keyboard
+
+	return photo, nil
+}
+
+var ERR_UNKNOWN_CHART error = errors.New("unknown chart")
+
+func (this *StatsController) historyChart(
+	p core.DbPlayer, rank bool,
+) ([]byte, error) {
+	history, err := this.PlayerRepo.StatsHistory(
+		p.ID, time.Now().Add(-CHART_HISTORY_PERIOD),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	points := make([]chart.Point, 0, len(history))
+	for _, s := range history {
+		if !rank {
+			points = append(points, chart.Point{
+				Time: s.Time, Value: float64(s.Player.Score),
+			})
+		} else if s.Player.Rank != nil {
+			points = append(points, chart.Point{
+				Time: s.Time, Value: float64(*s.Player.Rank),
+			})
+		}
+	}
+
+	// the history only changes when stats do, stretch the last known value
+	// up to now
+	if len(points) != 0 {
+		last := points[len(points)-1]
+		points = append(points, chart.Point{Time: time.Now(), Value: last.Value})
+	}
+
+	if rank {
+		return chart.Line(points, chart.LineOptions{
+			Title:   fmt.Sprintf("Rank of %s", p.Player.Name),
+			InvertY: true,
+			FormatY: func(v float64) string {
+				return fmt.Sprintf("#%d", int(v))
+			},
+		})
+	}
+
+	return chart.Line(points, chart.LineOptions{
+		Title: fmt.Sprintf("Score of %s", p.Player.Name),
+	})
+}
+
+func (this *StatsController) playtimeChart(
+	p core.DbPlayer, loc *time.Location,
+) ([]byte, error) {
+	now := time.Now().In(loc)
+	first := core.StartOfDay(now).AddDate(0, 0, -(CHART_PLAYTIME_DAYS - 1))
+
+	sessions, err := this.PlayerRepo.Sessions(p.ID, first)
+	if err != nil {
+		return nil, err
+	}
+
+	daily := core.DailyPlaytime(sessions, CHART_PLAYTIME_DAYS, now, loc)
+
+	bars := make([]chart.Bar, 0, len(daily))
+	for i, d := range daily {
+		bars = append(bars, chart.Bar{
+			Label: first.AddDate(0, 0, i).Format("01/02"),
+			Value: d.Minutes(),
+		})
+	}
+
+	return chart.Bars(bars, chart.BarOptions{
+		Title: fmt.Sprintf("Daily playtime of %s (minutes)", p.Player.Name),
+	})
+}
+
+const ACTIVITY_PERIOD = time.Hour * 24 * 28
+
+func (this *StatsController) PlayerActivity(
+	ctx tgool.Context,
+) (tgbotapi.Chattable, error) {
+	chatId := ctx.GetChatId()
+
+	l, err := localizer(ctx, this.Locales)
+	if err != nil {
+		return nil, err
+	}
+
+	playerId, err := strconv.Atoi(ctx.Params().ByName("playerId"))
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := this.PlayerRepo.GetPlayer(core.PlayerId(playerId))
+	if err != nil {
+		return nil, err
+	}
+
+	settings, err := this.SettingsRepo.Get(chatId)
+	if err != nil {
+		return nil, err
+	}
+
+	loc := settings.Location()
+	h, err := this.PlayerRepo.ActivityHeatmap(
+		p.ID, time.Now().Add(-ACTIVITY_PERIOD), loc,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				l.T("common.back"),
+				fmt.Sprintf("/players/%d", playerId),
+			),
+		),
+	)
+
+	slots := h.UsualSlots(3)
+	if len(slots) == 0 {
+		msg := tgbotapi.NewMessage(
+			chatId,
+			l.T("stats.no_activity", p.Player.Name),
+		)
+		msg.ReplyMarkup = keyboard
+
+		return reply(ctx, msg), nil
+	}
+
+	usually := make([]string, 0, len(slots))
+	for _, slot := range slots {
+		usually = append(usually, formatActivitySlot(l, slot))
+	}
+
+	values := make([][]float64, 0, 7)
+	rowLabels := make([]string, 0, 7)
+	for i := 1; i <= 7; i++ {
+		day := time.Weekday(i % 7)
+
+		row := make([]float64, 24)
+		for hour, d := range h[day] {
+			row[hour] = d.Minutes()
+		}
+
+		values = append(values, row)
+		rowLabels = append(rowLabels, l.Weekday(day))
+	}
+
+	colLabels := make([]string, 24)
+	for hour := 0; hour < 24; hour += 3 {
+		colLabels[hour] = fmt.Sprintf("%02d", hour)
+	}
+
+	data, err := chart.Heatmap(values, chart.HeatmapOptions{
+		Title:        fmt.Sprintf("Activity of %s", p.Player.Name),
+		RowLabels:    rowLabels,
+		ColumnLabels: colLabels,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	photo := tgbotapi.NewPhoto(chatId, tgbotapi.FileBytes{
+		Name:  "activity.png",
+		Bytes: data,
+	})
+	photo.Caption = l.T(
+		"stats.activity_caption",
+		p.Player.Name,
+		strings.Join(usually, ", "),
+		l.Digits(time.Now().In(loc).Format("-07:00")),
+	)
+	photo.ReplyMarkup = keyboard
+
+	return photo, nil
 }
 
 var _ tgool.Controller = (*StatsController)(nil)
+
+// formatActivitySlot writes the slot with the days and hours of the language.
+func formatActivitySlot(l i18n.Localizer, slot core.ActivitySlot) string {
+	days := make([]string, 0, len(slot.Days))
+	for _, d := range slot.Days {
+		days = append(days, l.Weekday(d))
+	}
+
+	return fmt.Sprintf(
+		"%s %s–%s",
+		strings.Join(days, "/"),
+		l.Digits(fmt.Sprintf("%02d:00", slot.From)),
+		l.Digits(fmt.Sprintf("%02d:00", slot.To%24)),
+	)
+}
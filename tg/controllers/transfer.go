@@ -0,0 +1,232 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/thekhanj/csdmpro/tg/i18n"
+	"github.com/thekhanj/csdmpro/tg/service"
+	"github.com/thekhanj/tgool"
+)
+
+const MAX_IMPORT_FILE_SIZE = 256 * 1024
+
+// Unmatched names listed in the import report, the rest are just counted.
+const MAX_REPORTED_UNMATCHED = 50
+
+type TransferController struct {
+	Service     *service.TransferService
+	Locales     *service.LocaleService
+	Permissions *service.PermissionService
+}
+
+func (this *TransferController) AddRoutes(b *tgool.RouterBuilder) {
+	b.SetPrefixRoute("/export").
+		AddMethod("", "ExportIndex").
+		AddMethod("/:format", "Export").
+		SetPrefixRoute("/import").
+		AddMethod("", "Import").WithBody()
+}
+
+func (this *TransferController) ExportIndex(
+	ctx tgool.Context,
+) (tgbotapi.Chattable, error) {
+	l, err := localizer(ctx, this.Locales)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := tgbotapi.NewMessage(ctx.GetChatId(), l.T("transfer.export_title"))
+
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				"📄 JSON",
+				fmt.Sprintf("/export/%s", service.ExportJSON),
+			),
+			tgbotapi.NewInlineKeyboardButtonData(
+				"📊 CSV",
+				fmt.Sprintf("/export/%s", service.ExportCSV),
+			),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				l.T("common.back"),
+				"/watchlist",
+			),
+		),
+	)
+
+	return reply(ctx, msg), nil
+}
+
+func (this *TransferController) Export(
+	ctx tgool.Context,
+) (tgbotapi.Chattable, error) {
+	chatId := ctx.GetChatId()
+	format := service.ExportFormat(ctx.Params().ByName("format"))
+
+	l, err := localizer(ctx, this.Locales)
+	if err != nil {
+		return nil, err
+	}
+
+	e, err := this.Service.Export(chatId)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := this.Service.Encode(e, format)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := tgbotapi.NewDocument(chatId, tgbotapi.FileBytes{
+		Name:  fmt.Sprintf("watchlist-%s.%s", time.Now().Format("2006-01-02"), format),
+		Bytes: data,
+	})
+	doc.Caption = l.T("transfer.export_caption")
+
+	return doc, nil
+}
+
+// Import prompts for a file when entered through a button or the command, and
+// imports it once it's sent.
+func (this *TransferController) Import(
+	ctx tgool.Context,
+) (tgbotapi.Chattable, error) {
+	chatId := ctx.GetChatId()
+	msg := ctx.Update().Message
+
+	l, err := localizer(ctx, this.Locales)
+	if err != nil {
+		return nil, err
+	}
+
+	if msg == nil || (msg.Document == nil && strings.HasPrefix(msg.Text, "/")) {
+		return this.prompt(ctx, l, l.T("transfer.import_title")), nil
+	}
+
+	if msg.Document == nil {
+		return this.prompt(ctx, l, l.T("transfer.send_file")), nil
+	}
+
+	if msg.Document.FileSize > MAX_IMPORT_FILE_SIZE {
+		return this.prompt(ctx, l, l.T("transfer.too_big_file")), nil
+	}
+
+	err = checkCanEdit(ctx, this.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := this.download(ctx.Bot(), msg.Document.FileID)
+	if err != nil {
+		return nil, err
+	}
+
+	e, err := this.Service.Decode(data)
+	if errors.Is(err, service.ERR_INVALID_IMPORT) {
+		return this.prompt(ctx, l, l.T("transfer.invalid_file")), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := this.Service.Import(chatId, e)
+	if errors.Is(err, service.ERR_TOO_BIG_IMPORT) {
+		return this.prompt(
+			ctx, l, l.T("transfer.too_many_players", service.MAX_IMPORT_PLAYERS),
+		), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ctx.Redirect("/watchlist")
+
+	txt := l.T("transfer.import_done", report.Added, report.AlreadyWatched)
+
+	if len(report.CreatedLists) != 0 {
+		txt += "\n" + l.T(
+			"transfer.created_lists", strings.Join(report.CreatedLists, ", "),
+		)
+	}
+	if len(report.SkippedLists) != 0 {
+		txt += "\n" + l.T(
+			"transfer.skipped_lists", strings.Join(report.SkippedLists, ", "),
+		)
+	}
+	if len(report.Unmatched) != 0 {
+		unmatched := report.Unmatched[:min(len(report.Unmatched), MAX_REPORTED_UNMATCHED)]
+
+		txt += "\n\n" + l.T("transfer.unmatched", strings.Join(unmatched, "\n"))
+		if len(report.Unmatched) > len(unmatched) {
+			txt += "\n" + l.T(
+				"transfer.more_unmatched", len(report.Unmatched)-len(unmatched),
+			)
+		}
+	}
+
+	done := tgbotapi.NewMessage(chatId, txt)
+	done.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				l.T("start.watchlist"),
+				"/watchlist",
+			),
+		),
+	)
+
+	return done, nil
+}
+
+func (this *TransferController) download(
+	bot *tgbotapi.BotAPI, fileId string,
+) ([]byte, error) {
+	url, err := bot.GetFileDirectURL(fileId)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := bot.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed downloading file: %s", res.Status)
+	}
+
+	return io.ReadAll(io.LimitReader(res.Body, MAX_IMPORT_FILE_SIZE))
+}
+
+func (this *TransferController) prompt(
+	ctx tgool.Context, l i18n.Localizer, txt string,
+) tgbotapi.Chattable {
+	msg := tgbotapi.NewMessage(ctx.GetChatId(), txt)
+
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				l.T("common.back"),
+				"/watchlist",
+			),
+		),
+	)
+
+	return reply(ctx, msg)
+}
+
+var _ tgool.Controller = (*TransferController)(nil)
@@ -0,0 +1,296 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/thekhanj/csdmpro/tg/format"
+	"github.com/thekhanj/csdmpro/tg/i18n"
+	"github.com/thekhanj/csdmpro/tg/repo"
+	"github.com/thekhanj/csdmpro/tg/service"
+	"github.com/thekhanj/tgool"
+)
+
+var WATCH_TOP_CHOICES = []int{5, 10, 20, 50, 100}
+
+// WatchRulesController manages the rules of the selected watchlist, which
+// watch players by name pattern or rank instead of one by one.
+type WatchRulesController struct {
+	WatchlistRepo *repo.WatchlistRepo
+	Locales       *service.LocaleService
+	Permissions   *service.PermissionService
+}
+
+func (this *WatchRulesController) AddRoutes(b *tgool.RouterBuilder) {
+	b.SetPrefixRoute("/watch-rules").
+		AddMethod("", "Index").
+		AddMethod("pattern", "AddPattern").WithBody().
+		AddMethod("top", "TopIndex").
+		AddMethod("a/post/top/:top", "AddTop").
+		AddMethod("a/delete/:ruleId", "RemoveRule")
+}
+
+func (this *WatchRulesController) Index(
+	ctx tgool.Context,
+) (tgbotapi.Chattable, error) {
+	chatId := ctx.GetChatId()
+
+	l, err := localizer(ctx, this.Locales)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := this.WatchlistRepo.Selected(chatId)
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := this.WatchlistRepo.Rules(list.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	txt := l.T("watch_rules.title", list.Name)
+
+	if len(rules) == 0 {
+		txt += "\n\n" + l.T("watch_rules.empty")
+	}
+
+	msg := tgbotapi.NewMessage(chatId, txt)
+
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0)
+
+	for _, rule := range rules {
+		rows = append(rows,
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(
+					"🚫 "+this.describe(l, rule),
+					fmt.Sprintf("/watch-rules/a/delete/%d", rule.ID),
+				),
+			),
+		)
+	}
+
+	rows = append(rows,
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				l.T("watch_rules.by_name"),
+				"/watch-rules/pattern",
+			),
+			tgbotapi.NewInlineKeyboardButtonData(
+				l.T("watch_rules.by_rank"),
+				"/watch-rules/top",
+			),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				l.T("common.back"),
+				"/watchlist",
+			),
+		),
+	)
+
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+
+	return reply(ctx, msg), nil
+}
+
+// AddPattern prompts for a name pattern when entered through a button and
+// adds the rule once the pattern is sent.
+func (this *WatchRulesController) AddPattern(
+	ctx tgool.Context,
+) (tgbotapi.Chattable, error) {
+	chatId := ctx.GetChatId()
+
+	l, err := localizer(ctx, this.Locales)
+	if err != nil {
+		return nil, err
+	}
+
+	if ctx.Update().Message == nil {
+		return this.prompt(ctx, l, l.T("watch_rules.enter_pattern")), nil
+	}
+
+	err = checkCanEdit(ctx, this.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := this.WatchlistRepo.Selected(chatId)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = this.WatchlistRepo.AddRule(repo.WatchRule{
+		ListId:  list.ID,
+		Kind:    repo.WatchRulePattern,
+		Pattern: ctx.Update().Message.Text,
+	})
+	if errors.Is(err, repo.ERR_INVALID_WATCH_PATTERN) ||
+		errors.Is(err, repo.ERR_TOO_MANY_WATCH_RULES) {
+		return this.prompt(ctx, l, l.T("watch_rules.invalid_pattern", l.Error(err))), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ctx.Redirect("/watch-rules")
+
+	return this.Index(ctx)
+}
+
+func (this *WatchRulesController) TopIndex(
+	ctx tgool.Context,
+) (tgbotapi.Chattable, error) {
+	l, err := localizer(ctx, this.Locales)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := tgbotapi.NewMessage(ctx.GetChatId(), l.T("watch_rules.pick_top"))
+
+	row := make([]tgbotapi.InlineKeyboardButton, 0, len(WATCH_TOP_CHOICES))
+	for _, top := range WATCH_TOP_CHOICES {
+		row = append(row,
+			tgbotapi.NewInlineKeyboardButtonData(
+				l.Number(top),
+				fmt.Sprintf("/watch-rules/a/post/top/%d", top),
+			),
+		)
+	}
+
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		row,
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				l.T("common.back"),
+				"/watch-rules",
+			),
+		),
+	)
+
+	return reply(ctx, msg), nil
+}
+
+func (this *WatchRulesController) AddTop(
+	ctx tgool.Context,
+) (tgbotapi.Chattable, error) {
+	l, err := localizer(ctx, this.Locales)
+	if err != nil {
+		return nil, err
+	}
+
+	top, err := strconv.Atoi(ctx.Params().ByName("top"))
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkCanEdit(ctx, this.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := this.WatchlistRepo.Selected(ctx.GetChatId())
+	if err != nil {
+		return nil, err
+	}
+
+	rule, err := this.WatchlistRepo.AddRule(repo.WatchRule{
+		ListId: list.ID,
+		Kind:   repo.WatchRuleTop,
+		Top:    top,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ctx.Bot().Request(
+		tgbotapi.NewCallback(
+			ctx.Update().CallbackQuery.ID,
+			l.T("watch_rules.watching", this.describe(l, rule)),
+		),
+	)
+
+	ctx.Redirect("/watch-rules")
+
+	return this.Index(ctx)
+}
+
+func (this *WatchRulesController) RemoveRule(
+	ctx tgool.Context,
+) (tgbotapi.Chattable, error) {
+	l, err := localizer(ctx, this.Locales)
+	if err != nil {
+		return nil, err
+	}
+
+	ruleId, err := strconv.ParseInt(ctx.Params().ByName("ruleId"), 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkCanEdit(ctx, this.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := this.WatchlistRepo.Selected(ctx.GetChatId())
+	if err != nil {
+		return nil, err
+	}
+
+	rule, err := this.WatchlistRepo.GetRule(list.ID, ruleId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = this.WatchlistRepo.RemoveRule(list.ID, rule.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx.Bot().Request(
+		tgbotapi.NewCallback(
+			ctx.Update().CallbackQuery.ID,
+			l.T("watch_rules.stopped_watching", this.describe(l, rule)),
+		),
+	)
+
+	ctx.Redirect("/watch-rules")
+
+	return this.Index(ctx)
+}
+
+func (this *WatchRulesController) describe(
+	l i18n.Localizer, rule repo.WatchRule,
+) string {
+	switch rule.Kind {
+	case repo.WatchRulePattern:
+		return l.T("watch_rules.pattern", format.Name(rule.Pattern))
+	case repo.WatchRuleTop:
+		return l.T("watch_rules.top", rule.Top)
+	default:
+		return string(rule.Kind)
+	}
+}
+
+func (this *WatchRulesController) prompt(
+	ctx tgool.Context, l i18n.Localizer, txt string,
+) tgbotapi.Chattable {
+	msg := tgbotapi.NewMessage(ctx.GetChatId(), txt)
+
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				l.T("common.back"),
+				"/watch-rules",
+			),
+		),
+	)
+
+	return reply(ctx, msg)
+}
+
+var _ tgool.Controller = (*WatchRulesController)(nil)
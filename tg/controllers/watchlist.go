@@ -6,6 +6,7 @@ import (
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/thekhanj/csdmpro/core"
+	"github.com/thekhanj/csdmpro/tg/format"
 	"github.com/thekhanj/csdmpro/tg/repo"
 	"github.com/thekhanj/csdmpro/tg/service"
 	"github.com/thekhanj/tgool"
@@ -14,7 +15,10 @@ import (
 type WatchlistController struct {
 	PlayerRepo    *core.PlayerRepo
 	WatchlistRepo *repo.WatchlistRepo
+	SettingsRepo  *repo.SettingsRepo
 	Service       *service.WatchlistService
+	Permissions   *service.PermissionService
+	Locales       *service.LocaleService
 }
 
 func (this *WatchlistController) AddRoutes(b *tgool.RouterBuilder) {
@@ -23,7 +27,8 @@ func (this *WatchlistController) AddRoutes(b *tgool.RouterBuilder) {
 		AddMethod("add-players/:page", "AddPlayersIndex").
 		AddMethod("remove-players", "RemovePlayersIndex").
 		AddMethod("a/post/players/:playerId", "AddPlayer").
-		AddMethod("a/delete/players/:playerId", "RemovePlayer")
+		AddMethod("a/delete/players/:playerId", "RemovePlayer").
+		AddMethod("members-can-edit/:canEdit", "SetMembersCanEdit")
 }
 
 func (this *WatchlistController) Index(
@@ -31,21 +36,41 @@ func (this *WatchlistController) Index(
 ) (tgbotapi.Chattable, error) {
 	chatId := ctx.GetChatId()
 
-	txt := `🕵️ Watchlist Feature
+	l, err := localizer(ctx, this.Locales)
+	if err != nil {
+		return nil, err
+	}
 
-Keep track of your favorite players! I'll notify you when they join or leave the server.
-Just add them to your watchlist, and I'll handle the rest. 🚀`
+	txt := l.T("watchlist.title")
+
+	list, err := this.WatchlistRepo.Selected(chatId)
+	if err != nil {
+		return nil, err
+	}
 
 	tps, err := this.Service.GetTracking(chatId)
 	if err != nil {
 		return nil, err
 	}
 
+	txt += "\n\n" + l.T("watchlist.list", list.Name)
+	if !list.Notify {
+		txt += " " + l.T("watchlist.list_muted")
+	}
+
+	rules, err := this.WatchlistRepo.Rules(list.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(rules) != 0 {
+		txt += "\n" + l.N("watchlist.rules", len(rules))
+	}
+
 	txt += "\n\n"
 	if len(tps) == 0 {
-		txt += "👀 You’re not tracking anyone yet."
+		txt += l.T("watchlist.empty")
 	} else {
-		txt += "👀 Currently Tracked Players:\n"
+		txt += l.T("watchlist.tracking") + "\n"
 		for _, tp := range tps {
 			var status string
 			if tp.IsOnline {
@@ -53,7 +78,12 @@ Just add them to your watchlist, and I'll handle the rest. 🚀`
 			} else {
 				status = "🔴"
 			}
-			txt += fmt.Sprintf("%s %s\n", status, tp.DbPlayer.Player.Name)
+			txt += fmt.Sprintf(
+				"%s %s %s\n",
+				status,
+				core.CountryFlag(tp.DbPlayer.Player.Country),
+				format.Name(tp.DbPlayer.Player.Name),
+			)
 		}
 	}
 
@@ -64,23 +94,69 @@ Just add them to your watchlist, and I'll handle the rest. 🚀`
 	rows = append(rows,
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData(
-				"➕ Add to Watchlist",
+				l.T("watchlist.add"),
 				"/watchlist/add-players/0",
 			),
 			tgbotapi.NewInlineKeyboardButtonData(
-				"➖ Remove from Watchlist",
+				l.T("watchlist.remove"),
 				"/watchlist/remove-players",
 			),
 		),
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData(
-				"🔄 Refresh List",
+				l.T("common.refresh_list"),
 				"/watchlist",
 			),
 		),
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData(
-				"🔙 Back",
+				l.T("watchlist.lists"),
+				"/watchlists",
+			),
+			tgbotapi.NewInlineKeyboardButtonData(
+				l.T("watchlist.watch_rules"),
+				"/watch-rules",
+			),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				l.T("watchlist.export"),
+				"/export",
+			),
+			tgbotapi.NewInlineKeyboardButtonData(
+				l.T("watchlist.import"),
+				"/import",
+			),
+		),
+	)
+
+	if !ctx.GetMessage().Chat.IsPrivate() {
+		settings, err := this.SettingsRepo.Get(chatId)
+		if err != nil {
+			return nil, err
+		}
+
+		title := l.T("watchlist.members_can_edit", "❌")
+		if settings.MembersCanEdit {
+			title = l.T("watchlist.members_can_edit", "✅")
+		}
+
+		rows = append(rows,
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(
+					title,
+					fmt.Sprintf(
+						"/watchlist/members-can-edit/%t", !settings.MembersCanEdit,
+					),
+				),
+			),
+		)
+	}
+
+	rows = append(rows,
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				l.T("common.back"),
 				"/start",
 			),
 		),
@@ -88,20 +164,24 @@ Just add them to your watchlist, and I'll handle the rest. 🚀`
 
 	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
 
-	return msg, nil
+	return reply(ctx, msg), nil
 }
 
 func (this *WatchlistController) AddPlayersIndex(
 	ctx tgool.Context,
 ) (tgbotapi.Chattable, error) {
 	chatId := ctx.GetChatId()
-	page, err := strconv.Atoi(ctx.Params().ByName("page"))
+
+	l, err := localizer(ctx, this.Locales)
 	if err != nil {
 		return nil, err
 	}
-	txt := `➕ Add to Watchlist
 
-Select a player to add to your watchlist`
+	page, err := strconv.Atoi(ctx.Params().ByName("page"))
+	if err != nil {
+		return nil, err
+	}
+	txt := l.T("watchlist.add_title")
 
 	players, err := this.PlayerRepo.List(page*20, 21)
 	if err != nil {
@@ -117,7 +197,9 @@ Select a player to add to your watchlist`
 	) []tgbotapi.InlineKeyboardButton {
 		row := tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData(
-				fmt.Sprintf("✔️ %s", p1.Player.Name),
+				fmt.Sprintf(
+					"✔️ %s %s", core.CountryFlag(p1.Player.Country), p1.Player.Name,
+				),
 				fmt.Sprintf("/watchlist/a/post/players/%d", p1.ID),
 			),
 		)
@@ -125,7 +207,9 @@ Select a player to add to your watchlist`
 		if p2 != nil {
 			row = append(row,
 				tgbotapi.NewInlineKeyboardButtonData(
-					fmt.Sprintf("✔️ %s", p2.Player.Name),
+					fmt.Sprintf(
+						"✔️ %s %s", core.CountryFlag(p2.Player.Country), p2.Player.Name,
+					),
 					fmt.Sprintf("/watchlist/a/post/players/%d", p2.ID),
 				),
 			)
@@ -149,7 +233,7 @@ Select a player to add to your watchlist`
 	if page != 0 {
 		paginationButtons = append(
 			paginationButtons, tgbotapi.NewInlineKeyboardButtonData(
-				fmt.Sprintf("⬅️ Previous Page"),
+				l.T("common.previous_page"),
 				fmt.Sprintf("/watchlist/add-players/%d", page-1),
 			),
 		)
@@ -158,7 +242,7 @@ Select a player to add to your watchlist`
 	if len(players) == 21 {
 		paginationButtons = append(
 			paginationButtons, tgbotapi.NewInlineKeyboardButtonData(
-				fmt.Sprintf("Next Page ➡️"),
+				l.T("common.next_page"),
 				fmt.Sprintf("/watchlist/add-players/%d", page+1),
 			),
 		)
@@ -168,7 +252,7 @@ Select a player to add to your watchlist`
 		tgbotapi.NewInlineKeyboardRow(paginationButtons...),
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData(
-				"🔙 Back",
+				l.T("common.back"),
 				"/watchlist",
 			),
 		),
@@ -176,18 +260,29 @@ Select a player to add to your watchlist`
 
 	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
 
-	return msg, nil
+	return reply(ctx, msg), nil
 }
 
 func (this *WatchlistController) AddPlayer(
 	ctx tgool.Context,
 ) (tgbotapi.Chattable, error) {
 	chatId := ctx.GetChatId()
+
+	l, err := localizer(ctx, this.Locales)
+	if err != nil {
+		return nil, err
+	}
+
 	playerId, err := strconv.Atoi(ctx.Params().ByName("playerId"))
 	if err != nil {
 		return nil, err
 	}
 
+	err = checkCanEdit(ctx, this.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
 	id := core.PlayerId(playerId)
 	player, err := this.PlayerRepo.GetPlayer(id)
 	if err != nil {
@@ -203,7 +298,7 @@ func (this *WatchlistController) AddPlayer(
 		ctx.Bot().Request(
 			tgbotapi.NewCallback(
 				ctx.Update().CallbackQuery.ID,
-				fmt.Sprintf("player %s is already in the watchlist", player.Player.Name),
+				l.T("watchlist.already_added", player.Player.Name),
 			),
 		)
 	} else {
@@ -215,7 +310,7 @@ func (this *WatchlistController) AddPlayer(
 		ctx.Bot().Request(
 			tgbotapi.NewCallback(
 				ctx.Update().CallbackQuery.ID,
-				fmt.Sprintf("player %s added into the watchlist", player.Player.Name),
+				l.T("watchlist.added", player.Player.Name),
 			),
 		)
 	}
@@ -230,9 +325,12 @@ func (this *WatchlistController) RemovePlayersIndex(
 ) (tgbotapi.Chattable, error) {
 	chatId := ctx.GetChatId()
 
-	txt := `➖ Remove from Watchlist
+	l, err := localizer(ctx, this.Locales)
+	if err != nil {
+		return nil, err
+	}
 
-Select a player to remove from your watchlist`
+	txt := l.T("watchlist.remove_title")
 
 	tps, err := this.Service.GetTracking(chatId)
 	if err != nil {
@@ -247,7 +345,11 @@ Select a player to remove from your watchlist`
 		rows = append(rows,
 			tgbotapi.NewInlineKeyboardRow(
 				tgbotapi.NewInlineKeyboardButtonData(
-					fmt.Sprintf("🚫 %s", tp.DbPlayer.Player.Name),
+					fmt.Sprintf(
+						"🚫 %s %s",
+						core.CountryFlag(tp.DbPlayer.Player.Country),
+						tp.DbPlayer.Player.Name,
+					),
 					fmt.Sprintf("/watchlist/a/delete/players/%d", tp.DbPlayer.ID),
 				),
 			),
@@ -257,7 +359,7 @@ Select a player to remove from your watchlist`
 	rows = append(rows,
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData(
-				"🔙 Back",
+				l.T("common.back"),
 				"/watchlist",
 			),
 		),
@@ -265,18 +367,29 @@ Select a player to remove from your watchlist`
 
 	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
 
-	return msg, nil
+	return reply(ctx, msg), nil
 }
 
 func (this *WatchlistController) RemovePlayer(
 	ctx tgool.Context,
 ) (tgbotapi.Chattable, error) {
 	chatId := ctx.GetChatId()
+
+	l, err := localizer(ctx, this.Locales)
+	if err != nil {
+		return nil, err
+	}
+
 	playerId, err := strconv.Atoi(ctx.Params().ByName("playerId"))
 	if err != nil {
 		return nil, err
 	}
 
+	err = checkCanEdit(ctx, this.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
 	id := core.PlayerId(playerId)
 	player, err := this.PlayerRepo.GetPlayer(id)
 	if err != nil {
@@ -291,7 +404,7 @@ func (this *WatchlistController) RemovePlayer(
 	ctx.Bot().Request(
 		tgbotapi.NewCallback(
 			ctx.Update().CallbackQuery.ID,
-			fmt.Sprintf("player %s removed from the watchlist", player.Player.Name),
+			l.T("watchlist.removed", player.Player.Name),
 		),
 	)
 
@@ -300,4 +413,27 @@ func (this *WatchlistController) RemovePlayer(
 	return this.Index(ctx)
 }
 
+func (this *WatchlistController) SetMembersCanEdit(
+	ctx tgool.Context,
+) (tgbotapi.Chattable, error) {
+	canEdit, err := strconv.ParseBool(ctx.Params().ByName("canEdit"))
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkIsAdmin(ctx, this.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	err = this.SettingsRepo.SetMembersCanEdit(ctx.GetChatId(), canEdit)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx.Redirect("/watchlist")
+
+	return this.Index(ctx)
+}
+
 var _ tgool.Controller = (*WatchlistController)(nil)
@@ -0,0 +1,408 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/thekhanj/csdmpro/tg/i18n"
+	"github.com/thekhanj/csdmpro/tg/repo"
+	"github.com/thekhanj/csdmpro/tg/service"
+	"github.com/thekhanj/tgool"
+)
+
+// WatchlistsController manages the chat's named watchlists, the players of
+// the selected one are managed by WatchlistController.
+type WatchlistsController struct {
+	WatchlistRepo *repo.WatchlistRepo
+	Locales       *service.LocaleService
+	Permissions   *service.PermissionService
+}
+
+func (this *WatchlistsController) AddRoutes(b *tgool.RouterBuilder) {
+	b.SetPrefixRoute("/watchlists").
+		AddMethod("", "Index").
+		AddMethod("new", "CreateList").WithBody().
+		AddMethod("manage/:listId", "ListIndex").
+		AddMethod("rename/:listId", "RenameList").WithBody().
+		AddMethod("delete/:listId", "DeleteListIndex").
+		AddMethod("a/post/selected/:listId", "SelectList").
+		AddMethod("a/post/notify/:listId/:notify", "SetNotify").
+		AddMethod("a/delete/:listId", "DeleteList")
+}
+
+func (this *WatchlistsController) Index(
+	ctx tgool.Context,
+) (tgbotapi.Chattable, error) {
+	chatId := ctx.GetChatId()
+
+	l, err := localizer(ctx, this.Locales)
+	if err != nil {
+		return nil, err
+	}
+
+	lists, err := this.WatchlistRepo.Lists(chatId)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := tgbotapi.NewMessage(chatId, l.T("watchlists.title"))
+
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0)
+
+	for _, list := range lists {
+		rows = append(rows,
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(
+					this.listTitle(list),
+					fmt.Sprintf("/watchlists/a/post/selected/%d", list.ID),
+				),
+				tgbotapi.NewInlineKeyboardButtonData(
+					"⚙️",
+					fmt.Sprintf("/watchlists/manage/%d", list.ID),
+				),
+			),
+		)
+	}
+
+	rows = append(rows,
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				l.T("watchlists.new"),
+				"/watchlists/new",
+			),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				l.T("common.back"),
+				"/watchlist",
+			),
+		),
+	)
+
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+
+	return reply(ctx, msg), nil
+}
+
+// CreateList prompts for the new list's name when entered through a button
+// and creates the list once the name is sent.
+func (this *WatchlistsController) CreateList(
+	ctx tgool.Context,
+) (tgbotapi.Chattable, error) {
+	chatId := ctx.GetChatId()
+
+	l, err := localizer(ctx, this.Locales)
+	if err != nil {
+		return nil, err
+	}
+
+	if ctx.Update().Message == nil {
+		return this.namePrompt(
+			ctx, l, l.T("watchlists.enter_name"), "/watchlists",
+		), nil
+	}
+
+	err = checkCanEdit(ctx, this.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = this.WatchlistRepo.CreateList(chatId, ctx.Update().Message.Text)
+	if this.isNameError(err) {
+		return this.namePrompt(
+			ctx, l, l.T("watchlists.invalid_name", l.Error(err)), "/watchlists",
+		), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ctx.Redirect("/watchlists")
+
+	return this.Index(ctx)
+}
+
+func (this *WatchlistsController) ListIndex(
+	ctx tgool.Context,
+) (tgbotapi.Chattable, error) {
+	chatId := ctx.GetChatId()
+
+	l, err := localizer(ctx, this.Locales)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := this.getListParam(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	notifications := l.T("watchlists.notifications_on")
+	if !list.Notify {
+		notifications = l.T("watchlists.notifications_off")
+	}
+
+	msg := tgbotapi.NewMessage(
+		chatId, l.T("watchlists.list_title", list.Name, notifications),
+	)
+
+	notifyTitle := l.T("watchlists.mute")
+	if !list.Notify {
+		notifyTitle = l.T("watchlists.unmute")
+	}
+
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				notifyTitle,
+				fmt.Sprintf("/watchlists/a/post/notify/%d/%t", list.ID, !list.Notify),
+			),
+			tgbotapi.NewInlineKeyboardButtonData(
+				l.T("watchlists.rename"),
+				fmt.Sprintf("/watchlists/rename/%d", list.ID),
+			),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				l.T("watchlists.delete"),
+				fmt.Sprintf("/watchlists/delete/%d", list.ID),
+			),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				l.T("common.back"),
+				"/watchlists",
+			),
+		),
+	)
+
+	return reply(ctx, msg), nil
+}
+
+// RenameList prompts for the list's new name when entered through a button
+// and renames the list once the name is sent.
+func (this *WatchlistsController) RenameList(
+	ctx tgool.Context,
+) (tgbotapi.Chattable, error) {
+	chatId := ctx.GetChatId()
+
+	l, err := localizer(ctx, this.Locales)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := this.getListParam(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	backRoute := fmt.Sprintf("/watchlists/manage/%d", list.ID)
+
+	if ctx.Update().Message == nil {
+		return this.namePrompt(
+			ctx, l, l.T("watchlists.enter_new_name", list.Name), backRoute,
+		), nil
+	}
+
+	err = checkCanEdit(ctx, this.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	err = this.WatchlistRepo.RenameList(chatId, list.ID, ctx.Update().Message.Text)
+	if this.isNameError(err) {
+		return this.namePrompt(
+			ctx, l, l.T("watchlists.invalid_name", l.Error(err)), backRoute,
+		), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ctx.Redirect(backRoute)
+
+	return this.ListIndex(ctx)
+}
+
+func (this *WatchlistsController) DeleteListIndex(
+	ctx tgool.Context,
+) (tgbotapi.Chattable, error) {
+	l, err := localizer(ctx, this.Locales)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := this.getListParam(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := tgbotapi.NewMessage(
+		ctx.GetChatId(), l.T("watchlists.confirm_delete", list.Name),
+	)
+
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				l.T("watchlists.yes_delete"),
+				fmt.Sprintf("/watchlists/a/delete/%d", list.ID),
+			),
+			tgbotapi.NewInlineKeyboardButtonData(
+				l.T("common.cancel"),
+				fmt.Sprintf("/watchlists/manage/%d", list.ID),
+			),
+		),
+	)
+
+	return reply(ctx, msg), nil
+}
+
+func (this *WatchlistsController) SelectList(
+	ctx tgool.Context,
+) (tgbotapi.Chattable, error) {
+	l, err := localizer(ctx, this.Locales)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := this.getListParam(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkCanEdit(ctx, this.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	err = this.WatchlistRepo.Select(ctx.GetChatId(), list.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx.Bot().Request(
+		tgbotapi.NewCallback(
+			ctx.Update().CallbackQuery.ID,
+			l.T("watchlists.switched", list.Name),
+		),
+	)
+
+	ctx.Redirect("/watchlists")
+
+	return this.Index(ctx)
+}
+
+func (this *WatchlistsController) SetNotify(
+	ctx tgool.Context,
+) (tgbotapi.Chattable, error) {
+	list, err := this.getListParam(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	notify, err := strconv.ParseBool(ctx.Params().ByName("notify"))
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkCanEdit(ctx, this.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	err = this.WatchlistRepo.SetNotify(ctx.GetChatId(), list.ID, notify)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx.Redirect(fmt.Sprintf("/watchlists/manage/%d", list.ID))
+
+	return this.ListIndex(ctx)
+}
+
+func (this *WatchlistsController) DeleteList(
+	ctx tgool.Context,
+) (tgbotapi.Chattable, error) {
+	l, err := localizer(ctx, this.Locales)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := this.getListParam(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkCanEdit(ctx, this.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	err = this.WatchlistRepo.DeleteList(ctx.GetChatId(), list.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx.Bot().Request(
+		tgbotapi.NewCallback(
+			ctx.Update().CallbackQuery.ID,
+			l.T("watchlists.deleted", list.Name),
+		),
+	)
+
+	ctx.Redirect("/watchlists")
+
+	return this.Index(ctx)
+}
+
+func (this *WatchlistsController) listTitle(list repo.Watchlist) string {
+	title := list.Name
+	if list.Selected {
+		title = "✅ " + title
+	}
+	if !list.Notify {
+		title += " 🔕"
+	}
+
+	return title
+}
+
+func (this *WatchlistsController) namePrompt(
+	ctx tgool.Context, l i18n.Localizer, txt string, backRoute string,
+) tgbotapi.Chattable {
+	msg := tgbotapi.NewMessage(ctx.GetChatId(), txt)
+
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				l.T("common.back"),
+				backRoute,
+			),
+		),
+	)
+
+	return reply(ctx, msg)
+}
+
+// isNameError tells whether the error is about the name the user sent, in
+// which case they're asked for another one.
+func (this *WatchlistsController) isNameError(err error) bool {
+	return errors.Is(err, repo.ERR_INVALID_WATCHLIST_NAME) ||
+		errors.Is(err, repo.ERR_DUPLICATE_WATCHLIST) ||
+		errors.Is(err, repo.ERR_TOO_MANY_WATCHLISTS)
+}
+
+func (this *WatchlistsController) getListParam(
+	ctx tgool.Context,
+) (repo.Watchlist, error) {
+	listId, err := strconv.ParseInt(ctx.Params().ByName("listId"), 10, 64)
+	if err != nil {
+		return repo.Watchlist{}, err
+	}
+
+	return this.WatchlistRepo.GetList(ctx.GetChatId(), listId)
+}
+
+var _ tgool.Controller = (*WatchlistsController)(nil)
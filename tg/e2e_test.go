@@ -0,0 +1,282 @@
+package tg
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/thekhanj/csdmpro/core"
+	"github.com/thekhanj/csdmpro/db"
+	"github.com/thekhanj/csdmpro/tg/repo"
+)
+
+// e2e runs the whole bot against a fake bot api, players are added to the
+// database directly and the observer's events are published by hand.
+type e2e struct {
+	Fake       *FakeBotApi
+	PlayerRepo *core.PlayerRepo
+	Observer   *core.Observer
+	AlertRepo  *repo.AlertRepo
+	Server     *Server
+	Notifier   *Notifier
+	Alerter    *Alerter
+
+	dbF  db.FakeDbFactory
+	stop func()
+}
+
+func (this *e2e) Init(t *testing.T) {
+	database, err := this.dbF.Init()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	this.PlayerRepo, err = core.CreatePlayerRepo(database)
+	if err != nil {
+		t.Fatal(err)
+	}
+	this.Observer = core.NewObserver(this.PlayerRepo, nil, 0, 0)
+
+	watchlistRepo := ProvideWatchlistRepo(database)
+	settingsRepo := ProvideSettingsRepo(database)
+	this.AlertRepo = ProvideAlertRepo(database)
+	liveBoardRepo := ProvideLiveBoardRepo(database)
+	bilakhRepo := ProvideBilakhRepo(database)
+	chatRepo := ProvideChatRepo(database)
+	failureRepo := ProvideFailureRepo(database)
+
+	watchlistService := ProvideWatchlistService(this.PlayerRepo, watchlistRepo)
+	locales := ProvideLocaleService(settingsRepo)
+	chats := ProvideChatService(chatRepo)
+	alertService := ProvideAlertService(this.PlayerRepo, this.AlertRepo)
+
+	controllers := ProvideControllers(
+		this.PlayerRepo,
+		watchlistRepo,
+		settingsRepo,
+		this.AlertRepo,
+		watchlistService,
+		ProvideDigestService(this.PlayerRepo, watchlistRepo),
+		alertService,
+		ProvidePermissionService(settingsRepo),
+		ProvideTransferService(this.PlayerRepo, watchlistRepo),
+		liveBoardRepo,
+		ProvideLiveBoardService(this.PlayerRepo),
+		locales,
+		this.Observer,
+		bilakhRepo,
+		chatRepo,
+		ProvideUsageRepo(database),
+		failureRepo,
+		ProvideBroadcastService(chatRepo, chats),
+	)
+
+	this.Fake = NewFakeBotApi(t)
+
+	this.Server, err = (&ServerBuilder{}).
+		WithToken(FAKE_BOT_TOKEN).
+		WithEndpoint(this.Fake.Endpoint()).
+		WithControllers(controllers...).
+		WithBilakhRepo(bilakhRepo).
+		WithChatRepo(chatRepo).
+		WithLocales(locales).
+		WithFailureRepo(failureRepo).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	this.Notifier = ProvideNotifier(
+		this.Observer, watchlistRepo, this.PlayerRepo, locales, chats, this.Server,
+	)
+	this.Alerter = ProvideAlerter(
+		this.Observer, this.PlayerRepo, alertService, locales, chats, this.Server,
+	)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	listening := make(chan struct{})
+	notifying := make(chan struct{})
+	alerting := make(chan struct{})
+
+	go func() {
+		defer close(listening)
+		this.Server.Listen(ctx)
+	}()
+	go func() {
+		defer close(notifying)
+		this.Notifier.Start(ctx)
+	}()
+	go func() {
+		defer close(alerting)
+		this.Alerter.Start(ctx)
+	}()
+
+	this.stop = func() {
+		cancel()
+		this.Fake.Close()
+		<-listening
+		<-notifying
+		<-alerting
+	}
+}
+
+func (this *e2e) Deinit() {
+	if this.stop != nil {
+		this.stop()
+	}
+	this.dbF.Deinit()
+}
+
+func (this *e2e) AddPlayer(t *testing.T, name string, country string) core.PlayerId {
+	rank := 1
+
+	id, err := this.PlayerRepo.AddPlayer(core.Player{
+		Name:    name,
+		Country: country,
+		Rank:    &rank,
+		Score:   1000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return id
+}
+
+// GetOnline publishes the player getting online until the notifier, which
+// subscribes in the background, picks it up and sends the text.
+func (this *e2e) GetOnline(
+	t *testing.T, playerId core.PlayerId, chatId int64, text string,
+) *tgbotapi.Message {
+	t.Helper()
+
+	return this.Publish(t, playerId, core.GotOnlineTopic, chatId, text)
+}
+
+// Publish publishes the topic of the player until a message containing the
+// text is sent into the chat, the subscribers subscribe in the background.
+func (this *e2e) Publish(
+	t *testing.T, playerId core.PlayerId, topic core.Topic, chatId int64, text string,
+) *tgbotapi.Message {
+	t.Helper()
+
+	deadline := time.Now().Add(FAKE_WAIT_TIMEOUT)
+
+	for time.Now().Before(deadline) {
+		this.Observer.Bus.Pub(playerId, topic)
+
+		req, ok := this.Fake.wait(0, 200*time.Millisecond, "sendMessage",
+			func(r FakeRequest) bool {
+				return r.Message.Chat.ID == chatId &&
+					strings.Contains(r.Message.Text, text)
+			},
+		)
+		if ok {
+			return req.Message
+		}
+	}
+
+	t.Fatalf("expected chat %d to be sent %q", chatId, text)
+	return nil
+}
+
+func TestE2EWatchPlayerAndGetNotified(t *testing.T) {
+	e := e2e{}
+	defer e.Deinit()
+
+	e.Init(t)
+
+	const chatId int64 = 1000
+
+	playerId := e.AddPlayer(t, "s1mple", "UA")
+	e.AddPlayer(t, "zywoo", "FR")
+	e.AddPlayer(t, "m0nesy", "RU")
+
+	e.Fake.Send(chatId, "/start")
+	start := e.Fake.ExpectText(t, chatId, "Welcome")
+
+	e.Fake.Press(t, start, "/watchlist")
+	watchlist := e.Fake.ExpectText(t, chatId, "You’re not tracking anyone yet.")
+	if watchlist.MessageID != start.MessageID {
+		t.Fatal("expected the watchlist to replace the start message")
+	}
+
+	e.Fake.Press(t, watchlist, "/watchlist/add-players/0")
+	players := e.Fake.ExpectText(t, chatId, "➕ Add to Watchlist")
+
+	e.Fake.Press(t, players, fmt.Sprintf("/watchlist/a/post/players/%d", playerId))
+	e.Fake.Expect(t, "answerCallbackQuery", func(r FakeRequest) bool {
+		return r.Params.Get("text") == "player s1mple added into the watchlist"
+	})
+	e.Fake.ExpectText(t, chatId, "🔴 🇺🇦 s1mple")
+
+	e.GetOnline(t, playerId, chatId, "🟢 Player 🇺🇦 s1mple got online")
+}
+
+func TestE2EAlertsFireForAddedPlayers(t *testing.T) {
+	e := e2e{}
+	defer e.Deinit()
+
+	e.Init(t)
+
+	const chatId int64 = 1000
+
+	_, err := e.AlertRepo.Add(repo.Alert{
+		ChatId:    chatId,
+		Metric:    repo.AlertScore,
+		Op:        repo.AlertAtLeast,
+		Threshold: 500,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	playerId := e.AddPlayer(t, "s1mple", "UA")
+
+	e.Publish(t, playerId, core.AddedPlayerTopic, chatId, "s1mple is now at")
+}
+
+func TestE2EErrorsAreShown(t *testing.T) {
+	e := e2e{}
+	defer e.Deinit()
+
+	e.Init(t)
+
+	const chatId int64 = 1000
+
+	e.Fake.Send(chatId, "/watchlist/a/post/players/404")
+	e.Fake.ExpectText(t, chatId, "Player not found")
+
+	e.Fake.Send(chatId, "/watchlist/a/post/players/abc")
+	e.Fake.ExpectText(t, chatId, "Something went wrong")
+}
+
+func TestE2EPromptsTakeInputFromTheirOpener(t *testing.T) {
+	e := e2e{}
+	defer e.Deinit()
+
+	e.Init(t)
+
+	const chatId int64 = -1000
+	const otherId int64 = 2000
+
+	e.Fake.Send(chatId, "/watchlists")
+	lists := e.Fake.ExpectText(t, chatId, "📋 Watchlists")
+
+	e.Fake.Press(t, lists, "/watchlists/new")
+	e.Fake.ExpectText(t, chatId, "Send me the name of the new list")
+
+	e.Fake.SendFrom(chatId, otherId, "rivals")
+	e.Fake.Send(chatId, "friends")
+	lists = e.Fake.ExpectText(t, chatId, "📋 Watchlists")
+
+	if hasButtonText(lists, "rivals") {
+		t.Fatal("expected the input of another member to be ignored")
+	}
+	if !hasButtonText(lists, "friends") {
+		t.Fatal("expected the opener's input to create the list")
+	}
+}
@@ -0,0 +1,384 @@
+package tg
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	FAKE_BOT_TOKEN    = "1:fake"
+	FAKE_WAIT_TIMEOUT = 5 * time.Second
+)
+
+// FakeRequest is a request the bot made to the fake bot api. Message is the
+// message it sent or edited, if any.
+type FakeRequest struct {
+	Method  string
+	Params  url.Values
+	Message *tgbotapi.Message
+}
+
+// FakeBotApi is a bot api server keeping the bot's requests and the
+// messages in its chats, and handing it the updates the test injects.
+type FakeBotApi struct {
+	server    *httptest.Server
+	closeOnce sync.Once
+
+	mutex         sync.Mutex
+	changed       chan struct{}
+	closed        chan struct{}
+	updates       []tgbotapi.Update
+	requests      []FakeRequest
+	mark          int
+	messages      map[int64]map[int]*tgbotapi.Message
+	nextMessageId int
+	nextCallback  int
+}
+
+func (this *FakeBotApi) Endpoint() string {
+	return this.server.URL + "/bot%s/%s"
+}
+
+// Send injects a message sent by the user into their private chat.
+func (this *FakeBotApi) Send(chatId int64, text string) {
+	this.SendFrom(chatId, chatId, text)
+}
+
+// SendFrom injects a message of the user into the chat, chats with negative
+// ids being groups.
+func (this *FakeBotApi) SendFrom(chatId int64, userId int64, text string) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	msg := &tgbotapi.Message{
+		MessageID: this.newMessageId(),
+		From:      this.user(userId),
+		Chat:      this.chat(chatId),
+		Date:      int(time.Now().Unix()),
+		Text:      text,
+	}
+	if strings.HasPrefix(text, "/") {
+		command, _, _ := strings.Cut(text, " ")
+		msg.Entities = []tgbotapi.MessageEntity{
+			{Type: "bot_command", Offset: 0, Length: len(command)},
+		}
+	}
+
+	this.inject(tgbotapi.Update{Message: msg})
+}
+
+// Press injects a press of the message's button with the data, failing if
+// the message doesn't have such a button at the moment.
+func (this *FakeBotApi) Press(t *testing.T, msg *tgbotapi.Message, data string) {
+	t.Helper()
+
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	current, ok := this.messages[msg.Chat.ID][msg.MessageID]
+	if !ok {
+		t.Fatalf("message %d isn't in chat %d", msg.MessageID, msg.Chat.ID)
+	}
+
+	if !hasButton(current, data) {
+		t.Fatalf("message %q has no button for %s", current.Text, data)
+	}
+
+	this.nextCallback++
+	copied := *current
+
+	this.inject(tgbotapi.Update{
+		CallbackQuery: &tgbotapi.CallbackQuery{
+			ID:      strconv.Itoa(this.nextCallback),
+			From:    this.user(msg.Chat.ID),
+			Message: &copied,
+			Data:    data,
+		},
+	})
+}
+
+// Expect waits for a request of the method, made since the last injected
+// update, that matches.
+func (this *FakeBotApi) Expect(
+	t *testing.T, method string, match func(FakeRequest) bool,
+) FakeRequest {
+	t.Helper()
+
+	this.mutex.Lock()
+	from := this.mark
+	this.mutex.Unlock()
+
+	req, ok := this.wait(from, FAKE_WAIT_TIMEOUT, method, match)
+	if !ok {
+		if method == "" {
+			method = "send a matching message"
+		}
+		t.Fatalf("expected the bot to %s, it made %v", method, this.methods(from))
+	}
+
+	return req
+}
+
+// ExpectText waits for a message sent or edited into the chat containing
+// the text.
+func (this *FakeBotApi) ExpectText(
+	t *testing.T, chatId int64, text string,
+) *tgbotapi.Message {
+	t.Helper()
+
+	req := this.Expect(t, "", func(r FakeRequest) bool {
+		return r.Message != nil &&
+			r.Message.Chat.ID == chatId &&
+			strings.Contains(r.Message.Text, text)
+	})
+
+	return req.Message
+}
+
+// Close stops the server, letting go of the bot's pending getUpdates.
+func (this *FakeBotApi) Close() {
+	this.closeOnce.Do(func() {
+		close(this.closed)
+		this.server.Close()
+	})
+}
+
+func (this *FakeBotApi) wait(
+	from int, timeout time.Duration, method string, match func(FakeRequest) bool,
+) (FakeRequest, bool) {
+	deadline := time.After(timeout)
+
+	for {
+		this.mutex.Lock()
+		for _, req := range this.requests[from:] {
+			if (method == "" || req.Method == method) && match(req) {
+				this.mutex.Unlock()
+				return req, true
+			}
+		}
+		from = len(this.requests)
+		changed := this.changed
+		this.mutex.Unlock()
+
+		select {
+		case <-changed:
+		case <-deadline:
+			return FakeRequest{}, false
+		}
+	}
+}
+
+func (this *FakeBotApi) methods(from int) []string {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	methods := make([]string, 0)
+	for _, req := range this.requests[from:] {
+		methods = append(methods, req.Method)
+	}
+
+	return methods
+}
+
+func (this *FakeBotApi) inject(u tgbotapi.Update) {
+	u.UpdateID = len(this.updates) + 1
+	this.updates = append(this.updates, u)
+	this.mark = len(this.requests)
+	this.notify()
+}
+
+// notify wakes up whoever waits on a change, the mutex must be held.
+func (this *FakeBotApi) notify() {
+	close(this.changed)
+	this.changed = make(chan struct{})
+}
+
+func (this *FakeBotApi) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseMultipartForm(1 << 20)
+	if err != nil && err != http.ErrNotMultipart {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	method := path.Base(r.URL.Path)
+
+	if method == "getUpdates" {
+		this.respond(w, this.getUpdates(r.Form))
+		return
+	}
+
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	req := FakeRequest{Method: method, Params: r.Form}
+	var result any = true
+
+	switch method {
+	case "getMe":
+		result = tgbotapi.User{ID: 1, IsBot: true, UserName: "csdmpro_test_bot"}
+	case "getChatMember":
+		userId, _ := strconv.ParseInt(r.Form.Get("user_id"), 10, 64)
+		result = tgbotapi.ChatMember{User: this.user(userId), Status: "administrator"}
+	case "sendMessage", "sendPhoto", "sendDocument":
+		req.Message = this.store(r.Form, this.newMessageId())
+		result = req.Message
+	case "copyMessage":
+		id := this.newMessageId()
+		this.store(r.Form, id)
+		result = tgbotapi.MessageID{MessageID: id}
+	case "editMessageText", "editMessageReplyMarkup":
+		id, _ := strconv.Atoi(r.Form.Get("message_id"))
+		req.Message = this.store(r.Form, id)
+		result = req.Message
+	}
+
+	this.requests = append(this.requests, req)
+	this.notify()
+
+	this.respond(w, result)
+}
+
+// getUpdates hands out the updates after the offset, waiting for some to
+// be injected like telegram does.
+func (this *FakeBotApi) getUpdates(params url.Values) []tgbotapi.Update {
+	offset, _ := strconv.Atoi(params.Get("offset"))
+	timeout, _ := strconv.Atoi(params.Get("timeout"))
+	deadline := time.After(time.Duration(timeout) * time.Second)
+
+	for {
+		this.mutex.Lock()
+		from := max(offset-1, 0)
+		if from < len(this.updates) {
+			updates := slices.Clone(this.updates[from:])
+			this.mutex.Unlock()
+			return updates
+		}
+		changed := this.changed
+		this.mutex.Unlock()
+
+		select {
+		case <-changed:
+		case <-deadline:
+			return []tgbotapi.Update{}
+		case <-this.closed:
+			return []tgbotapi.Update{}
+		}
+	}
+}
+
+func (this *FakeBotApi) store(params url.Values, id int) *tgbotapi.Message {
+	chatId, _ := strconv.ParseInt(params.Get("chat_id"), 10, 64)
+
+	msg := &tgbotapi.Message{
+		MessageID: id,
+		Chat:      this.chat(chatId),
+		Date:      int(time.Now().Unix()),
+		Text:      params.Get("text"),
+	}
+	if msg.Text == "" {
+		msg.Text = params.Get("caption")
+	}
+
+	if markup := params.Get("reply_markup"); markup != "" {
+		var keyboard tgbotapi.InlineKeyboardMarkup
+		if json.Unmarshal([]byte(markup), &keyboard) == nil {
+			msg.ReplyMarkup = &keyboard
+		}
+	}
+
+	if this.messages[chatId] == nil {
+		this.messages[chatId] = make(map[int]*tgbotapi.Message)
+	}
+	if prev, ok := this.messages[chatId][id]; ok && msg.Text == "" {
+		msg.Text = prev.Text
+	}
+	this.messages[chatId][id] = msg
+
+	copied := *msg
+	return &copied
+}
+
+func (this *FakeBotApi) respond(w http.ResponseWriter, result any) {
+	raw, err := json.Marshal(result)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tgbotapi.APIResponse{Ok: true, Result: raw})
+}
+
+func (this *FakeBotApi) newMessageId() int {
+	this.nextMessageId++
+	return this.nextMessageId
+}
+
+func (this *FakeBotApi) user(userId int64) *tgbotapi.User {
+	return &tgbotapi.User{ID: userId, FirstName: "Tester", LanguageCode: "en"}
+}
+
+func (this *FakeBotApi) chat(chatId int64) *tgbotapi.Chat {
+	if chatId < 0 {
+		return &tgbotapi.Chat{ID: chatId, Type: "group", Title: "Testers"}
+	}
+
+	return &tgbotapi.Chat{ID: chatId, Type: "private", FirstName: "Tester"}
+}
+
+// hasButtonText tells whether the message has a button containing the text.
+func hasButtonText(msg *tgbotapi.Message, text string) bool {
+	if msg.ReplyMarkup == nil {
+		return false
+	}
+
+	for _, row := range msg.ReplyMarkup.InlineKeyboard {
+		for _, b := range row {
+			if strings.Contains(b.Text, text) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func hasButton(msg *tgbotapi.Message, data string) bool {
+	if msg.ReplyMarkup == nil {
+		return false
+	}
+
+	for _, row := range msg.ReplyMarkup.InlineKeyboard {
+		for _, b := range row {
+			if b.CallbackData != nil && *b.CallbackData == data {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func NewFakeBotApi(t *testing.T) *FakeBotApi {
+	fake := &FakeBotApi{
+		changed:  make(chan struct{}),
+		closed:   make(chan struct{}),
+		messages: make(map[int64]map[int]*tgbotapi.Message),
+	}
+	fake.server = httptest.NewServer(fake)
+
+	t.Cleanup(fake.Close)
+
+	return fake
+}
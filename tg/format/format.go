@@ -0,0 +1,30 @@
+// Package format renders values the same way all over the bot.
+package format
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Duration renders a duration the way players talk about playtime.
+//
+//	Example: 3h 25m
+func Duration(d time.Duration) string {
+	d = d.Round(time.Minute)
+
+	h := int(d.Hours())
+	m := int(d.Minutes()) % 60
+
+	if h == 0 {
+		return fmt.Sprintf("%dm", m)
+	}
+
+	return fmt.Sprintf("%dh %dm", h, m)
+}
+
+// Name makes a player's name safe to send into groups, names looking like
+// @username would otherwise mention, and notify, whoever owns that username.
+func Name(name string) string {
+	return strings.ReplaceAll(name, "@", "@​")
+}
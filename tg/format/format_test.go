@@ -0,0 +1,12 @@
+package format
+
+import "testing"
+
+func TestName(t *testing.T) {
+	if Name("@alice|clan") != "@​alice|clan" {
+		t.Fatal("expected mentions to be broken")
+	}
+	if Name("bob") != "bob" {
+		t.Fatal("expected plain names to stay the same")
+	}
+}
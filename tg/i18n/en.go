@@ -0,0 +1,338 @@
+package i18n
+
+// english is the catalog every other one falls back to, it has to have
+// every text.
+var english = Catalog{
+	Texts: map[string]string{
+		"start.welcome": `👋 Welcome to the csdmpro Bot!
+
+Track your performance, keep an eye on your watchlist, or check who's online — all in one place.
+
+Choose a page to get started 👇`,
+		"start.stats":                         "📊 Stats",
+		"start.onlines":                       "🟢 Online Players",
+		"start.watchlist":                     "👁️ Watchlist",
+		"start.leaderboards":                  "🏆 Leaderboards",
+		"start.compare":                       "⚔️ Compare Players",
+		"start.server":                        "🖥️ Server Stats",
+		"start.digest":                        "📰 Digest",
+		"start.alerts":                        "🔔 Alerts",
+		"start.language":                      "🌐 Language",
+		"common.back":                         "🔙 Back",
+		"common.refresh":                      "🔄 Refresh",
+		"common.refresh_list":                 "🔄 Refresh List",
+		"common.previous_page":                "⬅️ Previous Page",
+		"common.next_page":                    "Next Page ➡️",
+		"duration.minutes":                    "%dm",
+		"duration.hours":                      "%dh %dm",
+		"onlines.title":                       "📊 Live player stats from the battlefield — updated in real-time.",
+		"onlines.empty":                       "There are no players online!",
+		"onlines.live_board":                  "📌 Live Board",
+		"onlines.stop_live_board":             "⏹️ Stop Live Board",
+		"onlines.live_board_started":          "live board started",
+		"onlines.live_board_started_unpinned": "live board started, pin it yourself to keep it at hand",
+		"onlines.live_board_stopped":          "live board stopped",
+		"onlines.live_board_ended":            "⏹️ Stopped, tap for a fresh list",
+		"stats.by_country":                    "🌍 By Country",
+		"stats.pick_country":                  "🌍 Pick a country to see its leaderboard.",
+		"stats.country_title":                 "%s Top players of %s",
+		"stats.player": `🎮 Player %s Stats
+
+🌍 Country: %s %s
+🏅 Rank: #%d
+📈 Score: %d
+🔫 Kills: %d
+💀 Deaths: %d
+🎯 Accuracy: %d%%`,
+		"stats.compare":                "⚔️ Compare",
+		"stats.rank_chart":             "📉 Rank Chart",
+		"stats.score_chart":            "📈 Score Chart",
+		"stats.playtime_chart":         "📊 Daily Playtime",
+		"stats.activity":               "🗓️ Activity",
+		"stats.rank_chart_caption":     "📉 Rank of %s in the last 30 days",
+		"stats.score_chart_caption":    "📈 Score of %s in the last 30 days",
+		"stats.playtime_chart_caption": "📊 Daily playtime of %s in the last %d days",
+		"stats.no_chart_data":          "🤷 There is not enough history to draw this chart yet.",
+		"stats.no_activity":            "😴 %s hasn't played in the last 4 weeks.",
+		"stats.activity_caption": `🗓️ %s is usually online %s
+
+(last 4 weeks, times in UTC%s)`,
+		"watchlist.title": `🕵️ Watchlist Feature
+
+Keep track of your favorite players! I'll notify you when they join or leave the server.
+Just add them to your watchlist, and I'll handle the rest. 🚀`,
+		"watchlist.list":             "📋 List: %s",
+		"watchlist.list_muted":       "(🔕 notifications off)",
+		"watchlist.empty":            "👀 You’re not tracking anyone yet.",
+		"watchlist.tracking":         "👀 Currently Tracked Players:",
+		"watchlist.add":              "➕ Add to Watchlist",
+		"watchlist.remove":           "➖ Remove from Watchlist",
+		"watchlist.lists":            "📋 Lists",
+		"watchlist.watch_rules":      "🧩 Rules",
+		"watchlist.export":           "📤 Export",
+		"watchlist.import":           "📥 Import",
+		"watchlist.members_can_edit": "👥 Members can edit: %s",
+		"watchlist.add_title": `➕ Add to Watchlist
+
+Select a player to add to your watchlist`,
+		"watchlist.remove_title": `➖ Remove from Watchlist
+
+Select a player to remove from your watchlist`,
+		"watchlist.already_added": "player %s is already in the watchlist",
+		"watchlist.added":         "player %s added into the watchlist",
+		"watchlist.removed":       "player %s removed from the watchlist",
+		"bilakh.surprise": `Surprise! 🎁
+
+You’ve received a certified Bilakh™ from the universe. 👍`,
+		"bilakh.accept_your_fate":    "🫡 Accept your fate",
+		"bilakh.who_sent_this":       "🤨 Who sent this?!",
+		"bilakh.return_the_bilakh":   "🔁 Return the Bilakh",
+		"bilakh.show_me_more":        "💥 Show me more!",
+		"bilakh.wise_choice":         "Wise choice. Resistance is futile. 👏",
+		"bilakh.yes_double_it":       "🔁 Yes, double it",
+		"bilakh.no_thanks":           "🙈 No thanks",
+		"bilakh.double":              "Here's a double Bilakh for you honey. 👍👍❤️",
+		"bilakh.appreciated":         "🥰 Appretiated",
+		"bilakh.classified":          "Sorry, that info is classified. 🕵️",
+		"bilakh.send_me_one_more":    "👍 Send me one more then",
+		"bilakh.dont_embarrass_me":   "😭 Don't embarrase me anymore!",
+		"bilakh.one_more":            "Here's one more Bilakh for you dear. ❤️👍",
+		"bilakh.thanks":              "❤️ Thanks",
+		"bilakh.non_refundable":      "Sorry, this bilakh is non-refundable — it's handcrafted just for you. 🎁",
+		"bilakh.thanks_then":         "❤️🥹 Thanks then",
+		"bilakh.heaven":              "You’re now entering Bilakh Heaven. 🥳",
+		"bilakh.with_fireworks":      "🔥 Bilakh with fireworks",
+		"bilakh.with_dramatic_music": "🎵 Bilakh with dramatic music",
+		"bilakh.with_screaming_goat": "😱 Bilakh with screaming goat",
+		"bilakh.in_slow_motion":      "🐢 Bilakh in slow motion",
+		"bilakh.fireworks":           "Here's a Bilakh with fireworks for you. 👍🔥🧨🎆",
+		"bilakh.cool":                "🔥😍 Coooool",
+		"bilakh.music":               "Here's a Bilakh with some music for you. 👍🎵🎹🎷🎸🎺",
+		"bilakh.love_it":             "☺️😍 I love it",
+		"bilakh.goat":                "Here's a Bilakh with an screaming goat for you. 👍🐐😱",
+		"bilakh.beee":                "🐑 Beeeee",
+		"bilakh.slow_motion_1":       "Here's a Biiii...",
+		"bilakh.slow_motion_2":       "...llllaaaakhhhhh for you. 👍🐢",
+		"bilakh.thanks_slowly":       "🐌 Than... ...kksss",
+		"notifier.online":            "🟢 Player %s %s got online",
+		"notifier.offline":           "🔴 Player %s %s got offline",
+		"notifier.session":           "⏱️ Session: %s",
+		"notifier.score":             "🎯 Score: %+d",
+		"notifier.kills":             "🔫 Kills: %+d",
+		"notifier.rank":              "%s Rank: #%d → #%d (%+d)",
+		"language.title":             "🌐 Choose the bot's language for this chat:",
+		"ratelimit.slow_down":        "🐢 Slow down, give me a second!",
+		"ratelimit.bilakhed":         "🛑 That's too much pressing, take a break for %s.",
+		"errors.failed":              "⚠️ Something went wrong, it's been reported. Try again in a bit.",
+		"leaderboards.title": `🏆 Leaderboards
+
+Pick a metric to see who's on top.`,
+		"leaderboards.kd":                      "⚔️ K/D Ratio",
+		"leaderboards.accuracy":                "🎯 Accuracy",
+		"leaderboards.kills":                   "🔫 Kills",
+		"leaderboards.score_today":             "📈 Score Today",
+		"leaderboards.score_week":              "📅 Score This Week",
+		"leaderboards.playtime":                "⏱️ Most Playtime",
+		"leaderboards.kd_description":          "Players with at least %d kills.",
+		"leaderboards.accuracy_description":    "Players with at least %d kills.",
+		"leaderboards.kills_description":       "All time kills.",
+		"leaderboards.score_today_description": "Score gained since midnight.",
+		"leaderboards.score_week_description":  "Score gained since monday.",
+		"leaderboards.playtime_description":    "Time spent on the server in the last 7 days.",
+		"leaderboards.empty":                   "Nobody made it to this leaderboard yet.",
+		"compare.pick_first": `⚔️ Compare Players
+
+Pick the first player from your watchlist or search by name.`,
+		"compare.pick_second": `⚔️ Compare Players
+
+Pick a player to compare %s %s with.`,
+		"compare.search_first":  "🔍 Send me the name of the first player.",
+		"compare.search_second": "🔍 Send me the name of the second player.",
+		"compare.search":        "🔍 Search",
+		"compare.results":       "🔍 Results for \"%s\"",
+		"compare.no_results":    "No player found, try another name.",
+		"compare.pick_result":   "Pick a player, or send another name to search again.",
+		"compare.title":         "⚔️ %s vs %s",
+		"compare.metric":        "%s: %s vs %s",
+		"compare.leads":         "🏆 %s leads in %d of %d metrics",
+		"compare.tie":           "🤝 It's a tie!",
+		"compare.swap":          "🔁 Swap",
+		"compare.rank":          "🏅 Rank",
+		"compare.score":         "📈 Score",
+		"compare.kills":         "🔫 Kills",
+		"compare.deaths":        "💀 Deaths",
+		"compare.kd":            "⚔️ K/D",
+		"compare.accuracy":      "🎯 Accuracy",
+		"compare.best_rank":     "🥇 Best rank (7d)",
+		"compare.rank_change":   "📊 Rank change (7d)",
+		"compare.playtime":      "⏱️ Playtime (7d)",
+		"weekday.sun":           "Sun",
+		"weekday.mon":           "Mon",
+		"weekday.tue":           "Tue",
+		"weekday.wed":           "Wed",
+		"weekday.thu":           "Thu",
+		"weekday.fri":           "Fri",
+		"weekday.sat":           "Sat",
+		"server.title": `🖥️ Server Population
+
+👥 Online now: %d
+📊 Average online: %.1f players`,
+		"server.peak":          "🔝 Peak: %d players on %s",
+		"server.busiest_hours": "🔥 Busiest hours: %s",
+		"server.best_time":     "🎯 Best time to play: %s (%.1f players on average)",
+		"server.daily_uniques": "📅 Unique players per day:",
+		"server.period":        "(last %d days, times in UTC%s)",
+		"server.hourly_chart":  "📊 Hourly Chart",
+		"server.chart_caption": "📊 Average online players per hour in the last %d days",
+		"digest.title": `📰 Digest
+
+Get a summary of your watchlist's playtime, rank changes and score gains, plus the players who made it into the top %d.
+
+📅 Sent: %s
+🕘 At: %s
+🌐 Timezone: %s`,
+		"digest.every_day":        "every day",
+		"digest.every_monday":     "every monday",
+		"digest.off":              "off",
+		"digest.off_button":       "Off",
+		"digest.daily":            "Daily",
+		"digest.weekly":           "Weekly",
+		"digest.earlier":          "⏪ 1h Earlier",
+		"digest.later":            "1h Later ⏩",
+		"digest.change_timezone":  "🌐 Change Timezone",
+		"digest.preview":          "👁️ Preview",
+		"digest.pick_timezone":    "🌐 Pick your timezone, or send its name (e.g. Asia/Tehran).",
+		"digest.type_timezone":    "⌨️ Type It",
+		"digest.enter_timezone":   "⌨️ Send me your timezone name, e.g. Europe/Berlin.",
+		"digest.unknown_timezone": "❌ Unknown timezone \"%s\", try another one.",
+		"digest.weekly_title":     "📰 Weekly digest — %s to %s",
+		"digest.daily_title":      "📰 Daily digest — %s",
+		"digest.empty":            "👀 You’re not tracking anyone yet.",
+		"digest.watchlist":        "👀 Your watchlist:",
+		"digest.unranked":         "unranked",
+		"digest.entry":            "%s %s — ⏱️ %s, 🏅 %s, 📈 %+d score, 🔫 %+d kills",
+		"digest.top_entrants":     "🆕 New in top %d:",
+		"alert_metric.rank":       "rank",
+		"alert_metric.score":      "score",
+		"alert_metric.kills":      "kills",
+		"alert_metric.accuracy":   "accuracy",
+		"alert_metric.kd":         "kd",
+		"alerter.fired": `🔔 %s %s is now at %s %s
+
+(alert: %s)`,
+		"alerts.title": `🔔 Alerts
+
+Get notified when a player's stats cross a threshold, like reaching rank 10 or passing 100k score.`,
+		"alerts.empty":         "You have no alerts yet.",
+		"alerts.tap_to_remove": "Tap an alert to remove it:",
+		"alerts.new":           "➕ New Alert",
+		"alerts.pick_player": `🔔 New Alert
+
+Who should the alert watch? Pick a player from your watchlist, or anyone.`,
+		"alerts.anyone":      "🌍 Anyone",
+		"alerts.anyone_name": "anyone",
+		"alerts.rank":        "🏅 Rank",
+		"alerts.score":       "📈 Score",
+		"alerts.kills":       "🔫 Kills",
+		"alerts.accuracy":    "🎯 Accuracy",
+		"alerts.kd":          "⚔️ K/D",
+		"alerts.pick_metric": `🔔 New alert on %s
+
+Which stat should it watch?`,
+		"alerts.pick_op": `🔔 New alert on %s's %s
+
+Should it fire when it goes up to or down to a value?`,
+		"alerts.rank_note":         "Note that better ranks are lower, to be told when a player reaches the top 10 pick “at most”.",
+		"alerts.at_least":          "≥ At least",
+		"alerts.at_most":           "≤ At most",
+		"alerts.enter_threshold":   "⌨️ Send me the threshold, e.g. 10 or 100k.",
+		"alerts.invalid_threshold": "❌ That's not a valid number, try again.",
+		"alerts.removed":           "alert removed",
+		"common.cancel":            "✖️ Cancel",
+		"watchlists.title": `📋 Watchlists
+
+Split the players you track into lists, like clan, rivals or friends. Tap a list to switch to it, or ⚙️ to manage it.`,
+		"watchlists.new":            "➕ New List",
+		"watchlists.enter_name":     "⌨️ Send me the name of the new list.",
+		"watchlists.enter_new_name": "⌨️ Send me the new name of %s.",
+		"watchlists.invalid_name":   "❌ %s, try again.",
+		"watchlists.list_title": `📋 %s
+
+Notifications: %s`,
+		"watchlists.notifications_on":  "on 🔔",
+		"watchlists.notifications_off": "off 🔕",
+		"watchlists.mute":              "🔕 Mute",
+		"watchlists.unmute":            "🔔 Unmute",
+		"watchlists.rename":            "✏️ Rename",
+		"watchlists.delete":            "🗑️ Delete",
+		"watchlists.confirm_delete":    "🗑️ Delete %s along with all of its players? This can't be undone.",
+		"watchlists.yes_delete":        "✔️ Yes, Delete",
+		"watchlists.switched":          "switched to %s",
+		"watchlists.deleted":           "list %s deleted",
+		"watch_rules.title": `🧩 Watch Rules of %s
+
+Rules watch players without adding them one by one, like your clan members by their tag or whoever is in the top 20. They're checked whenever someone gets online or offline, so newcomers are covered too.`,
+		"watch_rules.empty":   "🤷 No rules yet.",
+		"watch_rules.by_name": "🔤 By Name",
+		"watch_rules.by_rank": "🏆 By Rank",
+		"watch_rules.enter_pattern": `⌨️ Send me the name pattern to watch.
+
+* matches anything and ? a single character, case doesn't matter. For instance [TAG]* watches everyone whose name starts with [TAG].`,
+		"watch_rules.invalid_pattern":  "❌ %s, try again.",
+		"watch_rules.pick_top":         "🏆 Watch everyone ranked in the top:",
+		"watch_rules.watching":         "watching %s",
+		"watch_rules.stopped_watching": "stopped watching %s",
+		"watch_rules.pattern":          "names like %s",
+		"watch_rules.top":              "top %d",
+		"transfer.export_title": `📤 Export Watchlists
+
+Get all of your watchlists as a file, to keep as a backup or import into another chat with /import.`,
+		"transfer.export_caption": "📤 Your watchlists, send this file after /import to restore them.",
+		"transfer.import_title": `📥 Import Watchlists
+
+Send me a file exported with /export. A JSON array of names, or a CSV with one name per line, is fine too, those players are added into the selected list.`,
+		"transfer.send_file":        "📎 Send the watchlist as a file.",
+		"transfer.too_big_file":     "❌ The file is too big, try a smaller one.",
+		"transfer.invalid_file":     "❌ The file is neither a watchlist JSON nor CSV, try another file.",
+		"transfer.too_many_players": "❌ Too many players in the file, at most %d players can be imported at once.",
+		"transfer.import_done": `📥 Import done
+
+➕ Added: %d
+👀 Already watched: %d`,
+		"transfer.created_lists": "📋 New lists: %s",
+		"transfer.skipped_lists": "⚠️ Lists that couldn't be created: %s",
+		"transfer.unmatched": `❓ No player found by these names:
+%s`,
+		"transfer.more_unmatched":       "…and %d more",
+		"errors.player_not_found":       "Player not found",
+		"errors.compare_same_player":    "A player can't be compared with themselves",
+		"errors.no_broadcast_running":   "No broadcast is running",
+		"errors.too_many_watchlists":    "Too many watchlists, delete some first",
+		"errors.invalid_watchlist_name": "Watchlist name must be 1 to 32 characters long",
+		"errors.duplicate_watchlist":    "A watchlist with this name already exists",
+		"errors.last_watchlist":         "Can't delete the only watchlist",
+		"errors.too_many_watch_rules":   "Too many rules, remove some first",
+		"errors.invalid_watch_pattern":  "Pattern must be 1 to 32 characters long and not only wildcards",
+		"errors.invalid_watch_top":      "Top must be between 1 and 100",
+		"errors.too_many_alerts":        "Too many alerts, remove some first",
+		"errors.invalid_import":         "The file is neither a watchlist JSON nor CSV",
+		"errors.too_big_import":         "Too many players in the file, at most %d players can be imported at once",
+		"errors.broadcast_running":      "A broadcast is already running",
+		"errors.no_broadcast_draft":     "No message to broadcast, send it again",
+		"errors.admins_only":            "⛔ Only the group's admins can change this",
+	},
+	Plurals: map[string]Plural{
+		"live_board.title": {
+			One:   "📡 Live Board — %d online",
+			Other: "📡 Live Board — %d online",
+		},
+		"common.and_more": {
+			One:   "…and %d more",
+			Other: "…and %d more",
+		},
+		"watchlist.rules": {
+			One:   "🧩 %d watch rule",
+			Other: "🧩 %d watch rules",
+		},
+	},
+}
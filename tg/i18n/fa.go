@@ -0,0 +1,329 @@
+package i18n
+
+// persian has a single plural form, nouns stay singular after numbers.
+var persian = Catalog{
+	Texts: map[string]string{
+		"start.welcome": `👋 به ربات csdmpro خوش اومدی!
+
+عملکردت رو دنبال کن، واچ‌لیستت رو زیر نظر بگیر یا ببین کی آنلاینه — همه در یک جا.
+
+برای شروع یکی از صفحه‌ها رو انتخاب کن 👇`,
+		"start.stats":                         "📊 آمار",
+		"start.onlines":                       "🟢 بازیکنان آنلاین",
+		"start.watchlist":                     "👁️ واچ‌لیست",
+		"start.leaderboards":                  "🏆 جدول رده‌بندی",
+		"start.compare":                       "⚔️ مقایسه بازیکنان",
+		"start.server":                        "🖥️ آمار سرور",
+		"start.digest":                        "📰 خلاصه",
+		"start.alerts":                        "🔔 هشدارها",
+		"start.language":                      "🌐 زبان",
+		"common.back":                         "🔙 بازگشت",
+		"common.refresh":                      "🔄 به‌روزرسانی",
+		"common.refresh_list":                 "🔄 به‌روزرسانی لیست",
+		"common.previous_page":                "➡️ صفحه قبل",
+		"common.next_page":                    "صفحه بعد ⬅️",
+		"duration.minutes":                    "%d دقیقه",
+		"duration.hours":                      "%d ساعت و %d دقیقه",
+		"onlines.title":                       "📊 آمار زنده بازیکنان از میدان نبرد — به‌روز در لحظه.",
+		"onlines.empty":                       "هیچ بازیکنی آنلاین نیست!",
+		"onlines.live_board":                  "📌 تابلوی زنده",
+		"onlines.stop_live_board":             "⏹️ توقف تابلوی زنده",
+		"onlines.live_board_started":          "تابلوی زنده شروع شد",
+		"onlines.live_board_started_unpinned": "تابلوی زنده شروع شد، خودت پینش کن تا همیشه دم دستت باشه",
+		"onlines.live_board_stopped":          "تابلوی زنده متوقف شد",
+		"onlines.live_board_ended":            "⏹️ متوقف شد، برای لیست تازه بزن",
+		"stats.by_country":                    "🌍 بر اساس کشور",
+		"stats.pick_country":                  "🌍 یک کشور رو انتخاب کن تا جدول رده‌بندیش رو ببینی.",
+		"stats.country_title":                 "%s برترین بازیکنان %s",
+		"stats.player": `🎮 آمار بازیکن %s
+
+🌍 کشور: %s %s
+🏅 رتبه: #%d
+📈 امتیاز: %d
+🔫 کشته‌ها: %d
+💀 مرگ‌ها: %d
+🎯 دقت: %d٪`,
+		"stats.compare":                "⚔️ مقایسه",
+		"stats.rank_chart":             "📉 نمودار رتبه",
+		"stats.score_chart":            "📈 نمودار امتیاز",
+		"stats.playtime_chart":         "📊 زمان بازی روزانه",
+		"stats.activity":               "🗓️ فعالیت",
+		"stats.rank_chart_caption":     "📉 رتبه %s در ۳۰ روز گذشته",
+		"stats.score_chart_caption":    "📈 امتیاز %s در ۳۰ روز گذشته",
+		"stats.playtime_chart_caption": "📊 زمان بازی روزانه %s در %d روز گذشته",
+		"stats.no_chart_data":          "🤷 هنوز سابقه کافی برای رسم این نمودار وجود نداره.",
+		"stats.no_activity":            "😴 %s در ۴ هفته گذشته بازی نکرده.",
+		"stats.activity_caption": `🗓️ %s معمولاً این زمان‌ها آنلاینه: %s
+
+(۴ هفته گذشته، ساعت‌ها به وقت UTC%s)`,
+		"watchlist.title": `🕵️ واچ‌لیست
+
+بازیکنای مورد علاقه‌ت رو دنبال کن! هر وقت وارد سرور بشن یا ازش خارج بشن خبرت می‌کنم.
+فقط به واچ‌لیستت اضافه‌شون کن، بقیه‌ش با من. 🚀`,
+		"watchlist.list":             "📋 لیست: %s",
+		"watchlist.list_muted":       "(🔕 اعلان‌ها خاموش)",
+		"watchlist.empty":            "👀 هنوز کسی رو دنبال نمی‌کنی.",
+		"watchlist.tracking":         "👀 بازیکنانی که دنبال می‌کنی:",
+		"watchlist.add":              "➕ افزودن به واچ‌لیست",
+		"watchlist.remove":           "➖ حذف از واچ‌لیست",
+		"watchlist.lists":            "📋 لیست‌ها",
+		"watchlist.watch_rules":      "🧩 قانون‌ها",
+		"watchlist.export":           "📤 خروجی",
+		"watchlist.import":           "📥 ورودی",
+		"watchlist.members_can_edit": "👥 اعضا می‌تونن ویرایش کنن: %s",
+		"watchlist.add_title": `➕ افزودن به واچ‌لیست
+
+بازیکنی رو که می‌خوای به واچ‌لیستت اضافه کنی انتخاب کن`,
+		"watchlist.remove_title": `➖ حذف از واچ‌لیست
+
+بازیکنی رو که می‌خوای از واچ‌لیستت حذف کنی انتخاب کن`,
+		"watchlist.already_added": "بازیکن %s از قبل توی واچ‌لیسته",
+		"watchlist.added":         "بازیکن %s به واچ‌لیست اضافه شد",
+		"watchlist.removed":       "بازیکن %s از واچ‌لیست حذف شد",
+		"bilakh.surprise": `سورپرایز! 🎁
+
+یه بیلاخ™ تضمینی از طرف کائنات برات رسیده. 👍`,
+		"bilakh.accept_your_fate":    "🫡 سرنوشتت رو بپذیر",
+		"bilakh.who_sent_this":       "🤨 کی اینو فرستاده؟!",
+		"bilakh.return_the_bilakh":   "🔁 پس دادن بیلاخ",
+		"bilakh.show_me_more":        "💥 بازم نشون بده!",
+		"bilakh.wise_choice":         "انتخاب عاقلانه‌ای بود. مقاومت بی‌فایده‌ست. 👏",
+		"bilakh.yes_double_it":       "🔁 آره، دوبلش کن",
+		"bilakh.no_thanks":           "🙈 نه مرسی",
+		"bilakh.double":              "اینم یه بیلاخ دوبل برای تو عزیزم. 👍👍❤️",
+		"bilakh.appreciated":         "🥰 ممنونم",
+		"bilakh.classified":          "ببخشید، این اطلاعات محرمانه‌ست. 🕵️",
+		"bilakh.send_me_one_more":    "👍 پس یکی دیگه بفرست",
+		"bilakh.dont_embarrass_me":   "😭 دیگه آبروم رو نبر!",
+		"bilakh.one_more":            "اینم یه بیلاخ دیگه برای تو عزیزم. ❤️👍",
+		"bilakh.thanks":              "❤️ مرسی",
+		"bilakh.non_refundable":      "ببخشید، این بیلاخ پس گرفته نمی‌شه — دست‌ساز و مخصوص خودته. 🎁",
+		"bilakh.thanks_then":         "❤️🥹 پس مرسی",
+		"bilakh.heaven":              "داری وارد بهشت بیلاخ می‌شی. 🥳",
+		"bilakh.with_fireworks":      "🔥 بیلاخ با آتش‌بازی",
+		"bilakh.with_dramatic_music": "🎵 بیلاخ با موسیقی حماسی",
+		"bilakh.with_screaming_goat": "😱 بیلاخ با بز جیغ‌جیغو",
+		"bilakh.in_slow_motion":      "🐢 بیلاخ اسلوموشن",
+		"bilakh.fireworks":           "اینم یه بیلاخ با آتش‌بازی برای تو. 👍🔥🧨🎆",
+		"bilakh.cool":                "🔥😍 عاااالیه",
+		"bilakh.music":               "اینم یه بیلاخ با کمی موسیقی برای تو. 👍🎵🎹🎷🎸🎺",
+		"bilakh.love_it":             "☺️😍 عاشقشم",
+		"bilakh.goat":                "اینم یه بیلاخ با یه بز جیغ‌جیغو برای تو. 👍🐐😱",
+		"bilakh.beee":                "🐑 بععععع",
+		"bilakh.slow_motion_1":       "اینم یه بیییی...",
+		"bilakh.slow_motion_2":       "...لاااااااخ برای تو. 👍🐢",
+		"bilakh.thanks_slowly":       "🐌 مرر... ...سیییی",
+		"notifier.online":            "🟢 بازیکن %s %s آنلاین شد",
+		"notifier.offline":           "🔴 بازیکن %s %s آفلاین شد",
+		"notifier.session":           "⏱️ مدت بازی: %s",
+		"notifier.score":             "🎯 امتیاز: %+d",
+		"notifier.kills":             "🔫 کشته‌ها: %+d",
+		"notifier.rank":              "%s رتبه: #%d ← #%d (%+d)",
+		"language.title":             "🌐 زبان ربات رو برای این چت انتخاب کن:",
+		"ratelimit.slow_down":        "🐢 یواش‌تر، یه لحظه صبر کن!",
+		"ratelimit.bilakhed":         "🛑 خیلی زیادی دکمه زدی، %s استراحت کن.",
+		"errors.failed":              "⚠️ یه مشکلی پیش اومد و گزارشش رفت. یه کم دیگه دوباره امتحان کن.",
+		"leaderboards.title": `🏆 جدول رده‌بندی
+
+یه معیار انتخاب کن تا ببینی کی بالاتره.`,
+		"leaderboards.kd":                      "⚔️ نسبت کشته به مرگ",
+		"leaderboards.accuracy":                "🎯 دقت",
+		"leaderboards.kills":                   "🔫 کشته‌ها",
+		"leaderboards.score_today":             "📈 امتیاز امروز",
+		"leaderboards.score_week":              "📅 امتیاز این هفته",
+		"leaderboards.playtime":                "⏱️ بیشترین زمان بازی",
+		"leaderboards.kd_description":          "بازیکنانی با حداقل %d کشته.",
+		"leaderboards.accuracy_description":    "بازیکنانی با حداقل %d کشته.",
+		"leaderboards.kills_description":       "کشته‌های تمام دوران.",
+		"leaderboards.score_today_description": "امتیاز گرفته‌شده از نیمه‌شب.",
+		"leaderboards.score_week_description":  "امتیاز گرفته‌شده از دوشنبه.",
+		"leaderboards.playtime_description":    "زمان حضور روی سرور در ۷ روز گذشته.",
+		"leaderboards.empty":                   "هنوز کسی به این جدول راه پیدا نکرده.",
+		"compare.pick_first": `⚔️ مقایسه بازیکنان
+
+بازیکن اول رو از واچ‌لیستت انتخاب کن یا با اسم جستجو کن.`,
+		"compare.pick_second": `⚔️ مقایسه بازیکنان
+
+یه بازیکن انتخاب کن تا با %s %s مقایسه بشه.`,
+		"compare.search_first":  "🔍 اسم بازیکن اول رو برام بفرست.",
+		"compare.search_second": "🔍 اسم بازیکن دوم رو برام بفرست.",
+		"compare.search":        "🔍 جستجو",
+		"compare.results":       "🔍 نتایج برای «%s»",
+		"compare.no_results":    "بازیکنی پیدا نشد، یه اسم دیگه امتحان کن.",
+		"compare.pick_result":   "یه بازیکن انتخاب کن، یا برای جستجوی دوباره یه اسم دیگه بفرست.",
+		"compare.title":         "⚔️ %s در برابر %s",
+		"compare.metric":        "%s: %s در برابر %s",
+		"compare.leads":         "🏆 %s در %d از %d معیار جلوتره",
+		"compare.tie":           "🤝 مساوی شدن!",
+		"compare.swap":          "🔁 جابجایی",
+		"compare.rank":          "🏅 رتبه",
+		"compare.score":         "📈 امتیاز",
+		"compare.kills":         "🔫 کشته‌ها",
+		"compare.deaths":        "💀 مرگ‌ها",
+		"compare.kd":            "⚔️ نسبت K/D",
+		"compare.accuracy":      "🎯 دقت",
+		"compare.best_rank":     "🥇 بهترین رتبه (۷ روز)",
+		"compare.rank_change":   "📊 تغییر رتبه (۷ روز)",
+		"compare.playtime":      "⏱️ زمان بازی (۷ روز)",
+		"weekday.sun":           "یکشنبه",
+		"weekday.mon":           "دوشنبه",
+		"weekday.tue":           "سه‌شنبه",
+		"weekday.wed":           "چهارشنبه",
+		"weekday.thu":           "پنجشنبه",
+		"weekday.fri":           "جمعه",
+		"weekday.sat":           "شنبه",
+		"server.title": `🖥️ جمعیت سرور
+
+👥 آنلاین الان: %d
+📊 میانگین آنلاین: %.1f بازیکن`,
+		"server.peak":          "🔝 اوج: %d بازیکن در %s",
+		"server.busiest_hours": "🔥 شلوغ‌ترین ساعت‌ها: %s",
+		"server.best_time":     "🎯 بهترین زمان بازی: %s (به طور میانگین %.1f بازیکن)",
+		"server.daily_uniques": "📅 بازیکنان یکتا در هر روز:",
+		"server.period":        "(%d روز گذشته، زمان‌ها به وقت UTC%s)",
+		"server.hourly_chart":  "📊 نمودار ساعتی",
+		"server.chart_caption": "📊 میانگین بازیکنان آنلاین در هر ساعت در %d روز گذشته",
+		"digest.title": `📰 خلاصه
+
+خلاصه‌ای از زمان بازی، تغییر رتبه و امتیاز واچ‌لیستت، به علاوه بازیکنانی که به %d نفر برتر رسیدن.
+
+📅 ارسال: %s
+🕘 ساعت: %s
+🌐 منطقه زمانی: %s`,
+		"digest.every_day":        "هر روز",
+		"digest.every_monday":     "هر دوشنبه",
+		"digest.off":              "خاموش",
+		"digest.off_button":       "خاموش",
+		"digest.daily":            "روزانه",
+		"digest.weekly":           "هفتگی",
+		"digest.earlier":          "⏪ ۱ ساعت زودتر",
+		"digest.later":            "۱ ساعت دیرتر ⏩",
+		"digest.change_timezone":  "🌐 تغییر منطقه زمانی",
+		"digest.preview":          "👁️ پیش‌نمایش",
+		"digest.pick_timezone":    "🌐 منطقه زمانیت رو انتخاب کن، یا اسمش رو بفرست (مثلا Asia/Tehran).",
+		"digest.type_timezone":    "⌨️ تایپش می‌کنم",
+		"digest.enter_timezone":   "⌨️ اسم منطقه زمانیت رو بفرست، مثلا Europe/Berlin.",
+		"digest.unknown_timezone": "❌ منطقه زمانی «%s» ناشناخته‌ست، یکی دیگه امتحان کن.",
+		"digest.weekly_title":     "📰 خلاصه هفتگی — %s تا %s",
+		"digest.daily_title":      "📰 خلاصه روزانه — %s",
+		"digest.empty":            "👀 هنوز کسی رو دنبال نمی‌کنی.",
+		"digest.watchlist":        "👀 واچ‌لیست تو:",
+		"digest.unranked":         "بدون رتبه",
+		"digest.entry":            "%s %s — ⏱️ %s، 🏅 %s، 📈 %+d امتیاز، 🔫 %+d کشته",
+		"digest.top_entrants":     "🆕 تازه‌واردهای %d نفر برتر:",
+		"alert_metric.rank":       "رتبه",
+		"alert_metric.score":      "امتیاز",
+		"alert_metric.kills":      "کشته‌ها",
+		"alert_metric.accuracy":   "دقت",
+		"alert_metric.kd":         "نسبت K/D",
+		"alerter.fired": `🔔 %s %s الان در %s %s است
+
+(هشدار: %s)`,
+		"alerts.title": `🔔 هشدارها
+
+وقتی آمار یه بازیکن از یه حد رد شد باخبر شو، مثل رسیدن به رتبه ۱۰ یا رد کردن ۱۰۰ هزار امتیاز.`,
+		"alerts.empty":         "هنوز هیچ هشداری نداری.",
+		"alerts.tap_to_remove": "برای حذف یه هشدار روش بزن:",
+		"alerts.new":           "➕ هشدار جدید",
+		"alerts.pick_player": `🔔 هشدار جدید
+
+هشدار کی رو زیر نظر بگیره؟ یه بازیکن از واچ‌لیستت انتخاب کن، یا هر کسی.`,
+		"alerts.anyone":      "🌍 هر کسی",
+		"alerts.anyone_name": "هر کسی",
+		"alerts.rank":        "🏅 رتبه",
+		"alerts.score":       "📈 امتیاز",
+		"alerts.kills":       "🔫 کشته‌ها",
+		"alerts.accuracy":    "🎯 دقت",
+		"alerts.kd":          "⚔️ نسبت K/D",
+		"alerts.pick_metric": `🔔 هشدار جدید برای %s
+
+کدوم آمار رو زیر نظر بگیره؟`,
+		"alerts.pick_op": `🔔 هشدار جدید برای %s، %s
+
+وقتی به یه مقدار بالا رفت خبر بده یا وقتی پایین اومد؟`,
+		"alerts.rank_note":         "دقت کن رتبه‌های بهتر عدد کمتری دارن، برای اینکه وقتی یه بازیکن به ۱۰ نفر برتر رسید باخبر بشی «حداکثر» رو انتخاب کن.",
+		"alerts.at_least":          "≥ حداقل",
+		"alerts.at_most":           "≤ حداکثر",
+		"alerts.enter_threshold":   "⌨️ حد رو برام بفرست، مثلا 10 یا 100k.",
+		"alerts.invalid_threshold": "❌ این عدد معتبری نیست، دوباره امتحان کن.",
+		"alerts.removed":           "هشدار حذف شد",
+		"common.cancel":            "✖️ لغو",
+		"watchlists.title": `📋 واچ‌لیست‌ها
+
+بازیکن‌هایی که دنبال می‌کنی رو توی لیست‌های جدا بذار، مثل کلن، رقیب‌ها یا دوستان. برای رفتن به یه لیست روش بزن، یا برای مدیریتش ⚙️ رو بزن.`,
+		"watchlists.new":            "➕ لیست جدید",
+		"watchlists.enter_name":     "⌨️ اسم لیست جدید رو برام بفرست.",
+		"watchlists.enter_new_name": "⌨️ اسم جدید %s رو برام بفرست.",
+		"watchlists.invalid_name":   "❌ %s، دوباره امتحان کن.",
+		"watchlists.list_title": `📋 %s
+
+اعلان‌ها: %s`,
+		"watchlists.notifications_on":  "روشن 🔔",
+		"watchlists.notifications_off": "خاموش 🔕",
+		"watchlists.mute":              "🔕 بی‌صدا",
+		"watchlists.unmute":            "🔔 باصدا",
+		"watchlists.rename":            "✏️ تغییر نام",
+		"watchlists.delete":            "🗑️ حذف",
+		"watchlists.confirm_delete":    "🗑️ %s با همه بازیکن‌هاش حذف بشه؟ این کار برگشت‌پذیر نیست.",
+		"watchlists.yes_delete":        "✔️ آره، حذف کن",
+		"watchlists.switched":          "رفتی به %s",
+		"watchlists.deleted":           "لیست %s حذف شد",
+		"watch_rules.title": `🧩 قوانین دنبال کردن %s
+
+قوانین بازیکن‌ها رو بدون اضافه کردن تک‌تک دنبال می‌کنن، مثل اعضای کلنت با تگشون یا هر کی توی ۲۰ نفر برتره. هر وقت کسی آنلاین یا آفلاین بشه بررسی می‌شن، پس تازه‌واردها هم پوشش داده می‌شن.`,
+		"watch_rules.empty":   "🤷 هنوز قانونی نیست.",
+		"watch_rules.by_name": "🔤 با اسم",
+		"watch_rules.by_rank": "🏆 با رتبه",
+		"watch_rules.enter_pattern": `⌨️ الگوی اسمی که باید دنبال بشه رو برام بفرست.
+
+* با هر چیزی جور می‌شه و ? با یک حرف، بزرگی و کوچکی حروف مهم نیست. مثلا [TAG]* هر کسی که اسمش با [TAG] شروع می‌شه رو دنبال می‌کنه.`,
+		"watch_rules.invalid_pattern":  "❌ %s، دوباره امتحان کن.",
+		"watch_rules.pick_top":         "🏆 همه کسایی که رتبه‌شون توی این تعداد برتره رو دنبال کن:",
+		"watch_rules.watching":         "در حال دنبال کردن %s",
+		"watch_rules.stopped_watching": "دنبال کردن %s متوقف شد",
+		"watch_rules.pattern":          "اسم‌های شبیه %s",
+		"watch_rules.top":              "%d نفر برتر",
+		"transfer.export_title": `📤 خروجی واچ‌لیست‌ها
+
+همه واچ‌لیست‌هات رو به صورت فایل بگیر، برای نگه داشتن به عنوان پشتیبان یا وارد کردن توی یه چت دیگه با /import.`,
+		"transfer.export_caption": "📤 واچ‌لیست‌هات، برای برگردوندنشون این فایل رو بعد از /import بفرست.",
+		"transfer.import_title": `📥 وارد کردن واچ‌لیست‌ها
+
+یه فایل که با /export گرفتی برام بفرست. یه آرایه JSON از اسم‌ها، یا یه CSV با یه اسم در هر خط هم قبوله، اون بازیکن‌ها به لیست انتخاب‌شده اضافه می‌شن.`,
+		"transfer.send_file":        "📎 واچ‌لیست رو به صورت فایل بفرست.",
+		"transfer.too_big_file":     "❌ فایل خیلی بزرگه، یه فایل کوچیک‌تر امتحان کن.",
+		"transfer.invalid_file":     "❌ فایل نه JSON واچ‌لیسته نه CSV، یه فایل دیگه امتحان کن.",
+		"transfer.too_many_players": "❌ بازیکن‌های فایل خیلی زیادن، هر بار حداکثر %d بازیکن می‌شه وارد کرد.",
+		"transfer.import_done": `📥 وارد کردن انجام شد
+
+➕ اضافه‌شده: %d
+👀 از قبل دنبال‌شده: %d`,
+		"transfer.created_lists": "📋 لیست‌های جدید: %s",
+		"transfer.skipped_lists": "⚠️ لیست‌هایی که ساخته نشدن: %s",
+		"transfer.unmatched": `❓ بازیکنی با این اسم‌ها پیدا نشد:
+%s`,
+		"transfer.more_unmatched":       "…و %d تای دیگه",
+		"errors.player_not_found":       "بازیکن پیدا نشد",
+		"errors.compare_same_player":    "یه بازیکن رو نمی‌شه با خودش مقایسه کرد",
+		"errors.no_broadcast_running":   "هیچ پیام همگانی‌ای در حال ارسال نیست",
+		"errors.too_many_watchlists":    "واچ‌لیست‌ها خیلی زیادن، اول چندتاشون رو پاک کن",
+		"errors.invalid_watchlist_name": "اسم واچ‌لیست باید بین ۱ تا ۳۲ حرف باشه",
+		"errors.duplicate_watchlist":    "یه واچ‌لیست با این اسم از قبل هست",
+		"errors.last_watchlist":         "تنها واچ‌لیست رو نمی‌شه پاک کرد",
+		"errors.too_many_watch_rules":   "قانون‌ها خیلی زیادن، اول چندتاشون رو حذف کن",
+		"errors.invalid_watch_pattern":  "الگو باید بین ۱ تا ۳۲ حرف باشه و فقط از * تشکیل نشده باشه",
+		"errors.invalid_watch_top":      "تعداد برترها باید بین ۱ تا ۱۰۰ باشه",
+		"errors.too_many_alerts":        "هشدارها خیلی زیادن، اول چندتاشون رو حذف کن",
+		"errors.invalid_import":         "فایل نه JSON واچ‌لیسته نه CSV",
+		"errors.too_big_import":         "بازیکن‌های فایل خیلی زیادن، هر بار حداکثر %d بازیکن می‌شه وارد کرد",
+		"errors.broadcast_running":      "یه پیام همگانی در حال ارساله",
+		"errors.no_broadcast_draft":     "پیامی برای ارسال همگانی نیست، دوباره بفرستش",
+		"errors.admins_only":            "⛔ فقط ادمین‌های گروه می‌تونن اینو تغییر بدن",
+	},
+	Plurals: map[string]Plural{
+		"live_board.title": {Other: "📡 تابلوی زنده — %d آنلاین"},
+		"common.and_more":  {Other: "…و %d نفر دیگر"},
+		"watchlist.rules":  {Other: "🧩 %d قانون دنبال کردن"},
+	},
+	Digits: []rune("۰۱۲۳۴۵۶۷۸۹"),
+}
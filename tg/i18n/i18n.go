@@ -0,0 +1,234 @@
+// Package i18n translates the bot's texts into the chats' languages.
+//
+// Texts are looked up by key in the language's catalog, falling back to the
+// english one, and formatted like fmt.Sprintf. Numbers passed as arguments
+// are written with the language's digits.
+package i18n
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/thekhanj/csdmpro/core"
+	"github.com/thekhanj/csdmpro/logging"
+)
+
+var i18nLog = logging.Component("i18n")
+
+type Lang string
+
+const (
+	English Lang = "en"
+	Persian Lang = "fa"
+)
+
+const DEFAULT_LANG = English
+
+var LANGS = []Lang{English, Persian}
+
+// Title is the language's name in itself, as it's shown to pick it.
+func (this Lang) Title() string {
+	switch this {
+	case Persian:
+		return "🇮🇷 فارسی"
+	default:
+		return "🇬🇧 English"
+	}
+}
+
+// ParseLang parses one of the supported languages' codes.
+func ParseLang(code string) (Lang, bool) {
+	for _, lang := range LANGS {
+		if string(lang) == code {
+			return lang, true
+		}
+	}
+
+	return "", false
+}
+
+// FromLanguageCode picks the language of a telegram user's language_code,
+// which is an IETF tag like "fa" or "en-US".
+func FromLanguageCode(code string) Lang {
+	base, _, _ := strings.Cut(strings.ToLower(code), "-")
+
+	lang, ok := ParseLang(base)
+	if !ok {
+		return DEFAULT_LANG
+	}
+
+	return lang
+}
+
+// Plural holds the forms of a text varying by a count.
+type Plural struct {
+	One   string
+	Other string
+}
+
+type Catalog struct {
+	Texts   map[string]string
+	Plurals map[string]Plural
+	// Digits are the language's digits from zero to nine, nil for ascii.
+	Digits []rune
+}
+
+// WEEKDAYS are the keys of the days of the week's names, from sunday.
+var WEEKDAYS = [7]string{
+	"weekday.sun",
+	"weekday.mon",
+	"weekday.tue",
+	"weekday.wed",
+	"weekday.thu",
+	"weekday.fri",
+	"weekday.sat",
+}
+
+var catalogs = map[Lang]*Catalog{
+	English: &english,
+	Persian: &persian,
+}
+
+type Localizer struct {
+	lang    Lang
+	catalog *Catalog
+}
+
+// New localizes into lang, unsupported languages fall back to the default.
+func New(lang Lang) Localizer {
+	catalog, ok := catalogs[lang]
+	if !ok {
+		lang = DEFAULT_LANG
+		catalog = catalogs[lang]
+	}
+
+	return Localizer{lang, catalog}
+}
+
+func (this Localizer) Lang() Lang {
+	return this.lang
+}
+
+// T formats the text of the key with the arguments.
+func (this Localizer) T(key string, args ...any) string {
+	text, ok := this.catalog.Texts[key]
+	if !ok {
+		text, ok = english.Texts[key]
+	}
+	if !ok {
+		i18nLog.Warn("missing text", "key", key, "lang", this.lang)
+		return key
+	}
+
+	return this.sprintf(text, args...)
+}
+
+// N formats the form of the key's text fitting n, n is passed as the first
+// argument.
+func (this Localizer) N(key string, n int, args ...any) string {
+	plural, ok := this.catalog.Plurals[key]
+	if !ok {
+		plural, ok = english.Plurals[key]
+	}
+	if !ok {
+		i18nLog.Warn("missing plural", "key", key, "lang", this.lang)
+		return key
+	}
+
+	text := plural.Other
+	if n == 1 && plural.One != "" {
+		text = plural.One
+	}
+
+	return this.sprintf(text, append([]any{n}, args...)...)
+}
+
+// Error tells users what went wrong, core.UserError by the text of its key
+// and any other error as it is.
+func (this Localizer) Error(err error) string {
+	var userErr *core.UserError
+	if errors.As(err, &userErr) {
+		return this.T(userErr.Key, userErr.Args...)
+	}
+
+	return err.Error()
+}
+
+// Number writes the number with the language's digits.
+func (this Localizer) Number(n int) string {
+	return this.Digits(fmt.Sprint(n))
+}
+
+// Digits replaces the ascii digits of s with the language's.
+func (this Localizer) Digits(s string) string {
+	if this.catalog.Digits == nil {
+		return s
+	}
+
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return this.catalog.Digits[r-'0']
+		}
+		return r
+	}, s)
+}
+
+// Duration renders a duration the way players talk about playtime.
+//
+//	Example: 3h 25m
+func (this Localizer) Duration(d time.Duration) string {
+	d = d.Round(time.Minute)
+
+	h := int(d.Hours())
+	m := int(d.Minutes()) % 60
+
+	if h == 0 {
+		return this.T("duration.minutes", m)
+	}
+
+	return this.T("duration.hours", h, m)
+}
+
+// Weekday names the day of the week, shortened.
+//
+//	Example: Mon
+func (this Localizer) Weekday(d time.Weekday) string {
+	return this.T(WEEKDAYS[d])
+}
+
+// Day writes the date's day with its weekday.
+//
+//	Example: Mon 01/02
+func (this Localizer) Day(t time.Time) string {
+	return this.Weekday(t.Weekday()) + " " + this.Digits(t.Format("01/02"))
+}
+
+func (this Localizer) sprintf(text string, args ...any) string {
+	if this.catalog.Digits != nil {
+		args = append([]any(nil), args...)
+
+		for i, arg := range args {
+			switch arg.(type) {
+			case int, int64, float64:
+				args[i] = number{arg, this}
+			}
+		}
+	}
+
+	return fmt.Sprintf(text, args...)
+}
+
+// number formats like the number it wraps, with the language's digits.
+type number struct {
+	value     any
+	localizer Localizer
+}
+
+func (this number) Format(f fmt.State, verb rune) {
+	io.WriteString(
+		f, this.localizer.Digits(fmt.Sprintf(fmt.FormatString(f, verb), this.value)),
+	)
+}
@@ -0,0 +1,98 @@
+package i18n
+
+import (
+	"regexp"
+	"testing"
+	"time"
+)
+
+var verbRegex = regexp.MustCompile(`%[+#\- 0-9.]*[a-zA-Z]`)
+
+func verbs(text string) int {
+	return len(verbRegex.FindAllString(text, -1))
+}
+
+func TestCatalogsHaveEveryText(t *testing.T) {
+	for _, lang := range LANGS {
+		catalog := catalogs[lang]
+
+		for key, text := range english.Texts {
+			translated, ok := catalog.Texts[key]
+			if !ok {
+				t.Fatalf("%s: missing text %s", lang, key)
+			}
+			if verbs(translated) != verbs(text) {
+				t.Fatalf("%s: expected %s to have %d verbs", lang, key, verbs(text))
+			}
+		}
+
+		for key, plural := range english.Plurals {
+			translated, ok := catalog.Plurals[key]
+			if !ok {
+				t.Fatalf("%s: missing plural %s", lang, key)
+			}
+			if verbs(translated.Other) != verbs(plural.Other) {
+				t.Fatalf("%s: expected %s to have %d verbs", lang, key, verbs(plural.Other))
+			}
+		}
+	}
+}
+
+func TestLocalizerPlurals(t *testing.T) {
+	l := New(English)
+
+	if l.N("watchlist.rules", 1) != "🧩 1 watch rule" {
+		t.Fatalf("unexpected singular: %s", l.N("watchlist.rules", 1))
+	}
+	if l.N("watchlist.rules", 3) != "🧩 3 watch rules" {
+		t.Fatalf("unexpected plural: %s", l.N("watchlist.rules", 3))
+	}
+}
+
+func TestLocalizerDigits(t *testing.T) {
+	l := New(Persian)
+
+	if l.Number(1402) != "۱۴۰۲" {
+		t.Fatalf("unexpected number: %s", l.Number(1402))
+	}
+	if l.T("notifier.score", 12) != "🎯 امتیاز: +۱۲" {
+		t.Fatalf("unexpected text: %s", l.T("notifier.score", 12))
+	}
+	if l.Duration(65*time.Minute) != "۱ ساعت و ۵ دقیقه" {
+		t.Fatalf("unexpected duration: %s", l.Duration(65*time.Minute))
+	}
+
+	if New(English).Number(1402) != "1402" {
+		t.Fatal("expected english to keep ascii digits")
+	}
+}
+
+func TestLocalizerWeekday(t *testing.T) {
+	if New(English).Weekday(time.Monday) != "Mon" {
+		t.Fatalf("unexpected weekday: %s", New(English).Weekday(time.Monday))
+	}
+	if New(Persian).Weekday(time.Saturday) != "شنبه" {
+		t.Fatalf("unexpected weekday: %s", New(Persian).Weekday(time.Saturday))
+	}
+
+	day := time.Date(2025, 4, 7, 0, 0, 0, 0, time.UTC)
+	if New(Persian).Day(day) != "دوشنبه ۰۴/۰۷" {
+		t.Fatalf("unexpected day: %s", New(Persian).Day(day))
+	}
+}
+
+func TestFromLanguageCode(t *testing.T) {
+	cases := map[string]Lang{
+		"fa":    Persian,
+		"FA-IR": Persian,
+		"en-US": English,
+		"de":    DEFAULT_LANG,
+		"":      DEFAULT_LANG,
+	}
+
+	for code, expected := range cases {
+		if FromLanguageCode(code) != expected {
+			t.Fatalf("expected %q to be %s", code, expected)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package tg
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/thekhanj/csdmpro/core"
+	"github.com/thekhanj/csdmpro/logging"
+	"github.com/thekhanj/csdmpro/tg/i18n"
+	"github.com/thekhanj/csdmpro/tg/repo"
+	"github.com/thekhanj/csdmpro/tg/service"
+)
+
+var liveBoardLog = logging.Component("liveboard")
+
+const (
+	// Boards are edited at most this often however many players come and go,
+	// telegram rate limits editing a message.
+	LIVE_BOARD_THROTTLE = 15 * time.Second
+	// Boards are edited at least this often to keep the session durations
+	// fresh.
+	LIVE_BOARD_REFRESH = time.Minute
+	// Gap between editing the boards of different chats.
+	LIVE_BOARD_EDIT_GAP = 50 * time.Millisecond
+)
+
+// LiveBoards keeps the chats' live boards up to date as players get online
+// and offline.
+type LiveBoards struct {
+	gotOnline  chan core.PlayerId
+	gotOffline chan core.PlayerId
+	dirty      atomic.Bool
+
+	observer      *core.Observer
+	liveBoardRepo *repo.LiveBoardRepo
+	service       *service.LiveBoardService
+	locales       *service.LocaleService
+	chats         *service.ChatService
+	bot           *tgbotapi.BotAPI
+	wg            sync.WaitGroup
+}
+
+func (this *LiveBoards) Start(ctx context.Context) {
+	liveBoardLog.Info("started")
+	defer liveBoardLog.Info("stopped")
+
+	// subscribed before going on, stop unsubscribes them
+	this.gotOnline = this.observer.Bus.Sub(core.GotOnlineTopic)
+	this.gotOffline = this.observer.Bus.Sub(core.GotOfflineTopic)
+
+	this.wg.Add(2)
+
+	go func() {
+		defer this.wg.Done()
+		this.handleEvents(this.gotOnline)
+	}()
+	go func() {
+		defer this.wg.Done()
+		this.handleEvents(this.gotOffline)
+	}()
+
+	ticker := time.NewTicker(LIVE_BOARD_THROTTLE)
+	defer ticker.Stop()
+
+	var lastUpdate time.Time
+
+	for {
+		select {
+		case <-ctx.Done():
+			this.stop()
+			return
+		case now := <-ticker.C:
+			if !this.dirty.Swap(false) && now.Sub(lastUpdate) < LIVE_BOARD_REFRESH {
+				continue
+			}
+
+			this.updateBoards(now)
+			lastUpdate = now
+		}
+	}
+}
+
+func (this *LiveBoards) stop() {
+	liveBoardLog.Info("stopping")
+
+	go this.observer.Bus.Unsub(this.gotOnline)
+	go this.observer.Bus.Unsub(this.gotOffline)
+
+	this.wg.Wait()
+}
+
+func (this *LiveBoards) handleEvents(events chan core.PlayerId) {
+	for range events {
+		this.dirty.Store(true)
+	}
+}
+
+func (this *LiveBoards) updateBoards(now time.Time) {
+	boards, err := this.liveBoardRepo.List()
+	if err != nil {
+		liveBoardLog.Error("failed listing boards", "err", err)
+		return
+	}
+	if len(boards) == 0 {
+		return
+	}
+
+	// rendered once per language
+	texts := map[i18n.Lang]string{}
+
+	for i, b := range boards {
+		if i != 0 {
+			time.Sleep(LIVE_BOARD_EDIT_GAP)
+		}
+
+		l, err := this.locales.ForChat(b.ChatId)
+		if err != nil {
+			liveBoardLog.Error("failed getting locale", "chat_id", b.ChatId, "err", err)
+			continue
+		}
+
+		txt, ok := texts[l.Lang()]
+		if !ok {
+			txt, err = this.service.Render(now, l)
+			if err != nil {
+				liveBoardLog.Error("failed rendering board", "lang", l.Lang(), "err", err)
+				return
+			}
+
+			texts[l.Lang()] = txt
+		}
+
+		_, err = this.chats.Send(
+			this.bot, b.ChatId, tgbotapi.NewEditMessageText(b.ChatId, b.MessageId, txt),
+		)
+		if err == nil || isMessageNotModified(err) {
+			continue
+		}
+
+		if isMessageGone(err) {
+			liveBoardLog.Info("dropping board", "chat_id", b.ChatId, "err", err)
+
+			err = this.liveBoardRepo.Remove(b.ChatId)
+			if err != nil {
+				liveBoardLog.Error("failed removing board", "chat_id", b.ChatId, "err", err)
+			}
+			continue
+		}
+
+		liveBoardLog.Warn("failed editing board", "chat_id", b.ChatId, "err", err)
+	}
+}
+
+// isMessageGone tells whether the error is for the message, or the chat, not
+// being reachable anymore, e.g. deleted or the bot being blocked.
+func isMessageGone(err error) bool {
+	if service.IsUnreachable(err) {
+		return true
+	}
+
+	var tgErr *tgbotapi.Error
+
+	return errors.As(err, &tgErr) &&
+		strings.Contains(tgErr.Message, "message to edit not found")
+}
+
+func isMessageNotModified(err error) bool {
+	var tgErr *tgbotapi.Error
+
+	return errors.As(err, &tgErr) &&
+		strings.Contains(tgErr.Message, "message is not modified")
+}
@@ -0,0 +1,40 @@
+package middlewares
+
+import (
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/thekhanj/csdmpro/tg/service"
+	"github.com/thekhanj/tgool"
+)
+
+// AdminMiddleware drops updates meant for the /admin routes unless they
+// come from an admin chat, so to everyone else the routes don't exist.
+type AdminMiddleware struct {
+	admins *service.AdminService
+}
+
+func (this *AdminMiddleware) Handle(
+	ctx tgool.Context, next func(),
+) tgbotapi.Chattable {
+	chatId := ctx.GetChatId()
+	path := ctx.ChatsState().GetChat(chatId).GetPath()
+
+	isAdminRoute := isAdminPath(ctx.GetRoute()) || isAdminPath(path)
+	if isAdminRoute && !this.admins.IsAdminChat(chatId) {
+		return nil
+	}
+
+	next()
+	return nil
+}
+
+func isAdminPath(path string) bool {
+	return path == "/admin" || strings.HasPrefix(path, "/admin/")
+}
+
+func NewAdminMiddleware(admins *service.AdminService) *AdminMiddleware {
+	return &AdminMiddleware{admins}
+}
+
+var _ tgool.Middleware = (*AdminMiddleware)(nil)
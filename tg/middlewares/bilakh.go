@@ -1,16 +1,18 @@
 package middlewares
 
 import (
-	"log"
+	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/thekhanj/csdmpro/tg/controllers"
 	"github.com/thekhanj/csdmpro/tg/repo"
+	"github.com/thekhanj/csdmpro/tg/service"
 	"github.com/thekhanj/tgool"
 )
 
 type BilakhMiddleware struct {
-	repo *repo.BilakhRepo
+	repo    *repo.BilakhRepo
+	locales *service.LocaleService
 }
 
 func (this *BilakhMiddleware) Handle(
@@ -18,9 +20,9 @@ func (this *BilakhMiddleware) Handle(
 ) tgbotapi.Chattable {
 	chatId := ctx.GetChatId()
 
-	isBilakhed, err := this.repo.IsBilakhed(chatId)
+	isBilakhed, err := this.repo.IsBilakhed(chatId, time.Now())
 	if err != nil {
-		log.Println(err)
+		requestsLog.Error("failed checking bilakh", "chat_id", chatId, "err", err)
 		return nil
 	}
 	if !isBilakhed {
@@ -28,14 +30,18 @@ func (this *BilakhMiddleware) Handle(
 		return nil
 	}
 
-	m := tgool.NewControllerMiddleware(&controllers.BilakhController{})
+	m := tgool.NewControllerMiddleware(
+		&controllers.BilakhController{Locales: this.locales},
+	)
 
 	ret := m.Handle(ctx, func() {})
 	return ret
 }
 
-func NewBilakhMiddleware(bilakhRepo *repo.BilakhRepo) *BilakhMiddleware {
-	return &BilakhMiddleware{bilakhRepo}
+func NewBilakhMiddleware(
+	bilakhRepo *repo.BilakhRepo, locales *service.LocaleService,
+) *BilakhMiddleware {
+	return &BilakhMiddleware{bilakhRepo, locales}
 }
 
 var _ tgool.Middleware = (*BilakhMiddleware)(nil)
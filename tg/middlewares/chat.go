@@ -0,0 +1,83 @@
+package middlewares
+
+import (
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/thekhanj/csdmpro/logging"
+	"github.com/thekhanj/csdmpro/tg/repo"
+	"github.com/thekhanj/tgool"
+)
+
+var chatsLog = logging.Component("chats")
+
+// ChatMiddleware keeps the chats registry up to date with every update.
+// Updates about the bot's own membership, which come when it's blocked or
+// kicked out, mark the chat blocked and go no further.
+type ChatMiddleware struct {
+	repo *repo.ChatRepo
+}
+
+func (this *ChatMiddleware) Handle(
+	ctx tgool.Context, next func(),
+) tgbotapi.Chattable {
+	if m := ctx.Update().MyChatMember; m != nil {
+		this.handleMembership(m)
+		return nil
+	}
+
+	msg := ctx.GetMessage()
+	if msg == nil || msg.Chat == nil {
+		next()
+		return nil
+	}
+
+	chat := this.chatOf(msg.Chat)
+	if from := ctx.GetFrom(); from != nil {
+		chat.Language = from.LanguageCode
+	}
+
+	err := this.repo.Seen(chat, time.Now())
+	if err != nil {
+		chatsLog.Error("failed recording chat", "chat_id", chat.ID, "err", err)
+	}
+
+	next()
+	return nil
+}
+
+func (this *ChatMiddleware) handleMembership(m *tgbotapi.ChatMemberUpdated) {
+	var err error
+
+	switch m.NewChatMember.Status {
+	case "kicked", "left":
+		err = this.repo.SetBlocked(m.Chat.ID, true)
+	default:
+		err = this.repo.Seen(this.chatOf(&m.Chat), time.Now())
+	}
+
+	if err != nil {
+		chatsLog.Error("failed recording membership", "chat_id", m.Chat.ID, "err", err)
+	}
+}
+
+func (this *ChatMiddleware) chatOf(c *tgbotapi.Chat) repo.Chat {
+	chat := repo.Chat{
+		ID:       c.ID,
+		Type:     c.Type,
+		Title:    c.Title,
+		Username: c.UserName,
+	}
+	if c.IsPrivate() {
+		chat.Title = strings.TrimSpace(c.FirstName + " " + c.LastName)
+	}
+
+	return chat
+}
+
+func NewChatMiddleware(chatRepo *repo.ChatRepo) *ChatMiddleware {
+	return &ChatMiddleware{chatRepo}
+}
+
+var _ tgool.Middleware = (*ChatMiddleware)(nil)
@@ -0,0 +1,49 @@
+package middlewares
+
+import (
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/thekhanj/tgool"
+)
+
+// CommandMiddleware strips the bot's username off commands, which is how
+// commands are sent in groups, e.g. /watchlist@csdmprobot. Commands meant
+// for other bots of the group are dropped.
+type CommandMiddleware struct{}
+
+func (this *CommandMiddleware) Handle(
+	ctx tgool.Context, next func(),
+) tgbotapi.Chattable {
+	msg := ctx.Update().Message
+	if msg == nil || !strings.HasPrefix(msg.Text, "/") {
+		next()
+		return nil
+	}
+
+	command, args, _ := strings.Cut(msg.Text, " ")
+
+	command, username, found := strings.Cut(command, "@")
+	if !found {
+		next()
+		return nil
+	}
+
+	if !strings.EqualFold(username, ctx.Bot().Self.UserName) {
+		return nil
+	}
+
+	if args != "" {
+		command += " " + args
+	}
+	msg.Text = command
+
+	next()
+	return nil
+}
+
+func NewCommandMiddleware() *CommandMiddleware {
+	return &CommandMiddleware{}
+}
+
+var _ tgool.Middleware = (*CommandMiddleware)(nil)
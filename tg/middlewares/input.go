@@ -0,0 +1,36 @@
+package middlewares
+
+import (
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/thekhanj/tgool"
+)
+
+// InputMiddleware takes the chat out of a text input route (one added using
+// WithBody) whenever the update brings its own route, that is a button press
+// or a command. Otherwise tgool keeps handing every update to the input
+// route and the chat gets stuck in there.
+type InputMiddleware struct{}
+
+func (this *InputMiddleware) Handle(
+	ctx tgool.Context, next func(),
+) tgbotapi.Chattable {
+	u := ctx.Update()
+
+	isCallback := u.CallbackQuery != nil
+	isCommand := u.Message != nil && strings.HasPrefix(u.Message.Text, "/")
+
+	if isCallback || isCommand {
+		ctx.ChatsState().GetChat(ctx.GetChatId()).SetPath("/")
+	}
+
+	next()
+	return nil
+}
+
+func NewInputMiddleware() *InputMiddleware {
+	return &InputMiddleware{}
+}
+
+var _ tgool.Middleware = (*InputMiddleware)(nil)
@@ -0,0 +1,103 @@
+package middlewares
+
+import (
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/thekhanj/csdmpro/logging"
+	"github.com/thekhanj/csdmpro/tg/controllers"
+	"github.com/thekhanj/csdmpro/tg/repo"
+	"github.com/thekhanj/csdmpro/tg/service"
+	"github.com/thekhanj/tgool"
+)
+
+var rateLimitLog = logging.Component("ratelimit")
+
+// RateLimitMiddleware drops the updates of chats going too fast, telling
+// them to slow down. Chats that keep at it get a temporary bilakh. The admin
+// chats are never limited.
+type RateLimitMiddleware struct {
+	limiter    *service.RateLimiter
+	bilakhRepo *repo.BilakhRepo
+	locales    *service.LocaleService
+	admins     *service.AdminService
+}
+
+func (this *RateLimitMiddleware) Handle(
+	ctx tgool.Context, next func(),
+) tgbotapi.Chattable {
+	chatId := ctx.GetChatId()
+	if chatId == 0 || (this.admins != nil && this.admins.IsAdminChat(chatId)) {
+		next()
+		return nil
+	}
+
+	now := time.Now()
+	// input routes are named after the route, not whatever the user typed
+	route := controllers.Route(ctx)
+
+	res := this.limiter.Allow(chatId, route, now)
+	if res.Allowed {
+		next()
+		return nil
+	}
+
+	if res.Bilakh != 0 {
+		err := this.bilakhRepo.AddUntil(chatId, now.Add(res.Bilakh))
+		if err != nil {
+			rateLimitLog.Error("failed bilakhing chat", "chat_id", chatId, "err", err)
+		} else {
+			rateLimitLog.Warn(
+				"bilakhed chat",
+				"chat_id", chatId, "route", route, "for", res.Bilakh,
+			)
+		}
+	}
+
+	if !res.Warn && res.Bilakh == 0 {
+		this.answer(ctx, "")
+		return nil
+	}
+
+	l, err := this.locales.ForChat(chatId)
+	if err != nil {
+		rateLimitLog.Error("failed getting locale", "chat_id", chatId, "err", err)
+		return nil
+	}
+
+	txt := l.T("ratelimit.slow_down")
+	if res.Bilakh != 0 {
+		txt = l.T("ratelimit.bilakhed", l.Duration(res.Bilakh))
+	}
+
+	if ctx.Update().CallbackQuery != nil {
+		this.answer(ctx, txt)
+		return nil
+	}
+
+	return tgbotapi.NewMessage(chatId, txt)
+}
+
+// answer answers the pressed button, if any, so its spinner goes away.
+func (this *RateLimitMiddleware) answer(ctx tgool.Context, txt string) {
+	q := ctx.Update().CallbackQuery
+	if q == nil {
+		return
+	}
+
+	_, err := ctx.Bot().Request(tgbotapi.NewCallback(q.ID, txt))
+	if err != nil {
+		rateLimitLog.Warn("failed answering callback", "chat_id", ctx.GetChatId(), "err", err)
+	}
+}
+
+func NewRateLimitMiddleware(
+	limiter *service.RateLimiter,
+	bilakhRepo *repo.BilakhRepo,
+	locales *service.LocaleService,
+	admins *service.AdminService,
+) *RateLimitMiddleware {
+	return &RateLimitMiddleware{limiter, bilakhRepo, locales, admins}
+}
+
+var _ tgool.Middleware = (*RateLimitMiddleware)(nil)
@@ -0,0 +1,254 @@
+package middlewares
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/thekhanj/csdmpro/core"
+	"github.com/thekhanj/csdmpro/logging"
+	"github.com/thekhanj/csdmpro/tg/controllers"
+	"github.com/thekhanj/csdmpro/tg/i18n"
+	"github.com/thekhanj/csdmpro/tg/repo"
+	"github.com/thekhanj/csdmpro/tg/service"
+	"github.com/thekhanj/drouter"
+	"github.com/thekhanj/tgool"
+)
+
+var requestsLog = logging.Component("requests")
+
+// ERR_UNREADABLE_ROUTES is when the routes the controllers register can't
+// be read out of tgool's builder, its fields must've been renamed.
+var ERR_UNREADABLE_ROUTES error = errors.New("unreadable tgool routes")
+
+// controllerMethod is what the controllers' methods look like to tgool.
+type controllerMethod = func(ctx tgool.Context) (tgbotapi.Chattable, error)
+
+type route struct {
+	method  controllerMethod
+	hasBody bool
+}
+
+// requestContext hands the params of the matched route to the controller,
+// tgool sets them on its own context only.
+type requestContext struct {
+	tgool.Context
+	params *drouter.Params
+}
+
+func (this *requestContext) Params() *drouter.Params {
+	return this.params
+}
+
+// input is a text input route a user was prompted for, see WithBody.
+type input struct {
+	path   string
+	userId int64
+}
+
+// RequestMiddleware runs the controllers, logging the route, chat and
+// latency of every request. It routes the requests the way tgool's
+// ControllerMiddleware does, only keeping the errors of controllers, which
+// tgool would've sent as they are. The ones that are core.UserError are
+// shown to the users, any other is a failure of the bot, recorded for the
+// admins and shown as a friendly message. Either is answered to the pressed
+// button, if any. Unlike tgool, the input a chat is prompted for is only
+// taken from the user who opened the prompt, so that the other members of a
+// group don't answer it by chatting.
+type RequestMiddleware struct {
+	router   *drouter.Router
+	failures *repo.FailureRepo
+	locales  *service.LocaleService
+
+	mutex  sync.Mutex
+	inputs map[int64]input
+}
+
+func (this *RequestMiddleware) Handle(
+	ctx tgool.Context, next func(),
+) tgbotapi.Chattable {
+	chatId := ctx.GetChatId()
+	route := controllers.Route(ctx)
+	logger := requestsLog.With("chat_id", chatId, "route", route)
+	start := time.Now()
+
+	path, r, params, ok := this.lookup(ctx)
+	if !ok {
+		next()
+		return nil
+	}
+
+	ctx.ChatsState().GetChat(chatId).SetPath(path)
+
+	res, err := r.method(&requestContext{ctx, params})
+	latency := time.Since(start)
+
+	this.keepInput(ctx)
+
+	if err == nil {
+		logger.Info("handled", "latency", latency)
+		return res
+	}
+
+	l, lerr := this.locales.ForUser(chatId, ctx.GetFrom())
+	if lerr != nil {
+		logger.Error("failed getting locale", "err", lerr)
+		l = i18n.New(i18n.DEFAULT_LANG)
+	}
+
+	txt := l.Error(err)
+	if core.IsUserError(err) {
+		logger.Info("refused", "latency", latency, "err", err)
+	} else {
+		logger.Error("failed", "latency", latency, "err", err)
+
+		this.fail(repo.Failure{
+			At:      start,
+			ChatId:  chatId,
+			Route:   route,
+			Err:     err.Error(),
+			Latency: latency,
+		})
+		txt = l.T("errors.failed")
+	}
+
+	if q := ctx.Update().CallbackQuery; q != nil {
+		_, err := ctx.Bot().Request(tgbotapi.NewCallbackWithAlert(q.ID, txt))
+		if err != nil {
+			logger.Warn("failed answering callback", "err", err)
+		}
+
+		return nil
+	}
+
+	return tgbotapi.NewMessage(chatId, txt)
+}
+
+// lookup finds the route of the request. Like tgool, the chat's path is
+// preferred when it's waiting for an input, otherwise the update's route is.
+func (this *RequestMiddleware) lookup(
+	ctx tgool.Context,
+) (path string, r route, params *drouter.Params, ok bool) {
+	current := ctx.ChatsState().GetChat(ctx.GetChatId()).GetPath()
+
+	p := make(drouter.Params, 0, 20)
+	handle, _ := this.router.Lookup(current, &p)
+	if handle != nil && handle.(route).hasBody && this.isInputFrom(ctx, current) {
+		return current, handle.(route), &p, true
+	}
+
+	p = make(drouter.Params, 0, 20)
+	handle, _ = this.router.Lookup(ctx.GetRoute(), &p)
+	if handle != nil {
+		return ctx.GetRoute(), handle.(route), &p, true
+	}
+
+	return "", route{}, nil, false
+}
+
+// keepInput remembers who opened the prompt if the chat is left waiting for
+// an input.
+func (this *RequestMiddleware) keepInput(ctx tgool.Context) {
+	chatId := ctx.GetChatId()
+	path := ctx.ChatsState().GetChat(chatId).GetPath()
+
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	p := make(drouter.Params, 0, 20)
+	handle, _ := this.router.Lookup(path, &p)
+	if handle == nil || !handle.(route).hasBody || ctx.GetFrom() == nil {
+		delete(this.inputs, chatId)
+		return
+	}
+
+	this.inputs[chatId] = input{path, ctx.GetFrom().ID}
+}
+
+// isInputFrom tells whether the update is from the user who opened the
+// prompt of the path. Prompts with no known opener are anyone's to answer.
+func (this *RequestMiddleware) isInputFrom(ctx tgool.Context, path string) bool {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	in, ok := this.inputs[ctx.GetChatId()]
+	if !ok || in.path != path || ctx.GetFrom() == nil {
+		return true
+	}
+
+	return in.userId == ctx.GetFrom().ID
+}
+
+// fail records the failure for the admins to look into.
+func (this *RequestMiddleware) fail(f repo.Failure) {
+	_, err := this.failures.Add(f)
+	if err != nil {
+		requestsLog.Error("failed recording failure", "chat_id", f.ChatId, "err", err)
+	}
+}
+
+func NewRequestMiddleware(
+	controllers []tgool.Controller,
+	failures *repo.FailureRepo,
+	locales *service.LocaleService,
+) (*RequestMiddleware, error) {
+	router := drouter.New()
+
+	for _, controller := range controllers {
+		err := addRoutes(router, controller)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &RequestMiddleware{
+		router:   router,
+		failures: failures,
+		locales:  locales,
+		inputs:   make(map[int64]input),
+	}, nil
+}
+
+// addRoutes adds the routes the controller registers on a tgool builder,
+// whose fields are read as tgool doesn't expose them.
+func addRoutes(router *drouter.Router, controller tgool.Controller) error {
+	b := &tgool.RouterBuilder{}
+	controller.AddRoutes(b.SetPrefixRoute("/"))
+
+	metadatas := reflect.ValueOf(b).Elem().FieldByName("metadatas")
+	if metadatas.Kind() != reflect.Slice {
+		return ERR_UNREADABLE_ROUTES
+	}
+
+	for i := 0; i < metadatas.Len(); i++ {
+		path := metadatas.Index(i).FieldByName("path")
+		name := metadatas.Index(i).FieldByName("method")
+		hasBody := metadatas.Index(i).FieldByName("hasBody")
+		if path.Kind() != reflect.String ||
+			name.Kind() != reflect.String ||
+			hasBody.Kind() != reflect.Bool {
+			return ERR_UNREADABLE_ROUTES
+		}
+
+		value := reflect.ValueOf(controller).MethodByName(name.String())
+
+		method, ok := controllerMethod(nil), false
+		if value.IsValid() {
+			method, ok = value.Interface().(controllerMethod)
+		}
+		if !ok {
+			return fmt.Errorf(
+				"%T has no controller method %s", controller, name.String(),
+			)
+		}
+
+		router.AddRoute(path.String(), route{method, hasBody.Bool()})
+	}
+
+	return nil
+}
+
+var _ tgool.Middleware = (*RequestMiddleware)(nil)
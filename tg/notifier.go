@@ -2,15 +2,19 @@ package tg
 
 import (
 	"context"
-	"fmt"
-	"log"
 	"sync"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/thekhanj/csdmpro/core"
+	"github.com/thekhanj/csdmpro/logging"
+	"github.com/thekhanj/csdmpro/tg/format"
+	"github.com/thekhanj/csdmpro/tg/i18n"
 	"github.com/thekhanj/csdmpro/tg/repo"
+	"github.com/thekhanj/csdmpro/tg/service"
 )
 
+var notifierLog = logging.Component("notifier")
+
 type Notifier struct {
 	gotOnline  chan core.PlayerId
 	gotOffline chan core.PlayerId
@@ -18,26 +22,28 @@ type Notifier struct {
 	observer      *core.Observer
 	watchlistRepo *repo.WatchlistRepo
 	playerRepo    *core.PlayerRepo
+	locales       *service.LocaleService
+	chats         *service.ChatService
 	bot           *tgbotapi.BotAPI
 	wg            sync.WaitGroup
 }
 
 func (this *Notifier) Start(ctx context.Context) {
-	log.Println("notifier: started")
-	defer log.Println("notifier: stopped")
+	notifierLog.Info("started")
+	defer notifierLog.Info("stopped")
+
+	// subscribed before going on, stop unsubscribes them
+	this.gotOnline = this.observer.Bus.Sub(core.GotOnlineTopic)
+	this.gotOffline = this.observer.Bus.Sub(core.GotOfflineTopic)
 
 	this.wg.Add(2)
 
 	go func() {
 		defer this.wg.Done()
-		this.gotOnline = this.observer.Bus.Sub(core.GotOnlineTopic)
-
 		this.handleEvent(this.gotOnline, true)
 	}()
 	go func() {
 		defer this.wg.Done()
-		this.gotOffline = this.observer.Bus.Sub(core.GotOfflineTopic)
-
 		this.handleEvent(this.gotOffline, false)
 	}()
 
@@ -46,7 +52,7 @@ func (this *Notifier) Start(ctx context.Context) {
 }
 
 func (this *Notifier) stop() {
-	log.Println("notifier: stopping...")
+	notifierLog.Info("stopping")
 
 	go this.observer.Bus.Unsub(this.gotOnline)
 	go this.observer.Bus.Unsub(this.gotOffline)
@@ -58,27 +64,95 @@ func (this *Notifier) handleEvent(events chan core.PlayerId, gotOnline bool) {
 	for playerId := range events {
 		player, err := this.playerRepo.GetPlayer(playerId)
 		if err != nil {
-			log.Printf("notifier: %s", err.Error())
+			notifierLog.Error("failed getting player", "player_id", playerId, "err", err)
 			continue
 		}
 
-		chatIds, err := this.watchlistRepo.GetInterested(player.ID)
+		chatIds, err := this.watchlistRepo.GetInterestedIn(player)
 		if err != nil {
-			log.Printf("notifier: %s", err.Error())
+			notifierLog.Error(
+				"failed getting interested chats", "player", player.Player.Name, "err", err,
+			)
 			continue
 		}
 
-		var msg string
+		var report *core.SessionReport
 		if gotOnline {
-			msg = fmt.Sprintf("🟢 Player %s got online", player.Player.Name)
-			log.Printf("notifier: player %s got online", player.Player.Name)
+			notifierLog.Info("player got online", "player", player.Player.Name, "chats", len(chatIds))
 		} else {
-			msg = fmt.Sprintf("🔴 Player %s got offline", player.Player.Name)
-			log.Printf("notifier: player %s got offline", player.Player.Name)
+			notifierLog.Info("player got offline", "player", player.Player.Name, "chats", len(chatIds))
+
+			r, err := this.playerRepo.LastSessionReport(player.ID)
+			if err != nil {
+				notifierLog.Error(
+					"failed getting session report", "player", player.Player.Name, "err", err,
+				)
+			} else {
+				report = &r
+			}
 		}
 
 		for _, chatId := range chatIds {
-			this.bot.Send(tgbotapi.NewMessage(chatId, msg))
+			l, err := this.locales.ForChat(chatId)
+			if err != nil {
+				notifierLog.Error("failed getting locale", "chat_id", chatId, "err", err)
+				continue
+			}
+
+			this.chats.Send(
+				this.bot,
+				chatId,
+				tgbotapi.NewMessage(chatId, this.message(l, player, gotOnline, report)),
+			)
+		}
+	}
+}
+
+func (this *Notifier) message(
+	l i18n.Localizer,
+	player core.DbPlayer,
+	gotOnline bool,
+	report *core.SessionReport,
+) string {
+	flag := core.CountryFlag(player.Player.Country)
+	name := format.Name(player.Player.Name)
+
+	if gotOnline {
+		return l.T("notifier.online", flag, name)
+	}
+
+	msg := l.T("notifier.offline", flag, name)
+	if report != nil {
+		msg += this.sessionDetails(l, *report)
+	}
+
+	return msg
+}
+
+// sessionDetails describes what the player did during the session that just
+// ended, leaving out whatever didn't change.
+func (this *Notifier) sessionDetails(
+	l i18n.Localizer, report core.SessionReport,
+) string {
+	txt := "\n\n" + l.T("notifier.session", l.Duration(report.Session.Duration()))
+
+	if gain := report.ScoreGain(); gain != 0 {
+		txt += "\n" + l.T("notifier.score", gain)
+	}
+	if gain := report.KillsGain(); gain != 0 {
+		txt += "\n" + l.T("notifier.kills", gain)
+	}
+
+	if change, ok := report.RankChange(); ok && change != 0 {
+		arrow := "📈"
+		if change < 0 {
+			arrow = "📉"
 		}
+
+		txt += "\n" + l.T(
+			"notifier.rank", arrow, *report.Before.Rank, *report.After.Rank, change,
+		)
 	}
+
+	return txt
 }
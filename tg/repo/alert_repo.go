@@ -0,0 +1,271 @@
+package repo
+
+import (
+	"database/sql"
+	"math"
+
+	"github.com/thekhanj/csdmpro/core"
+)
+
+type AlertMetric string
+
+const (
+	AlertRank     AlertMetric = "rank"
+	AlertScore    AlertMetric = "score"
+	AlertKills    AlertMetric = "kills"
+	AlertAccuracy AlertMetric = "accuracy"
+	AlertKD       AlertMetric = "kd"
+)
+
+var AlertMetrics = []AlertMetric{
+	AlertRank, AlertScore, AlertKills, AlertAccuracy, AlertKD,
+}
+
+type AlertOp string
+
+const (
+	AlertAtLeast AlertOp = "gte"
+	AlertAtMost  AlertOp = "lte"
+)
+
+// Once fired, an alert is re-armed only after the value moves back past the
+// threshold by this ratio of it, so values wobbling around the threshold
+// don't flood the chat.
+const ALERT_HYSTERESIS_RATIO = 0.05
+
+const MAX_ALERTS_PER_CHAT = 20
+
+var ERR_TOO_MANY_ALERTS error = core.NewUserError("errors.too_many_alerts")
+
+type Alert struct {
+	ID     int64
+	ChatId int64
+	// PlayerId is nil for alerts on anyone.
+	PlayerId  *core.PlayerId
+	Metric    AlertMetric
+	Op        AlertOp
+	Threshold float64
+}
+
+// Value extracts the alert's metric from the player, unranked players have
+// no rank to compare.
+func (this *Alert) Value(p core.Player) (float64, bool) {
+	switch this.Metric {
+	case AlertRank:
+		if p.Rank == nil {
+			return 0, false
+		}
+		return float64(*p.Rank), true
+	case AlertScore:
+		return float64(p.Score), true
+	case AlertKills:
+		return float64(p.Kills), true
+	case AlertAccuracy:
+		return float64(p.Accuracy), true
+	case AlertKD:
+		return float64(p.Kills) / math.Max(float64(p.Deaths), 1), true
+	default:
+		return 0, false
+	}
+}
+
+func (this *Alert) IsMet(value float64) bool {
+	if this.Op == AlertAtMost {
+		return value <= this.Threshold
+	}
+
+	return value >= this.Threshold
+}
+
+// Evaluate tells whether the alert is fired for a player having the given
+// value, and whether it just got fired and should be notified.
+func (this *Alert) Evaluate(value float64, fired bool) (bool, bool) {
+	if !fired {
+		met := this.IsMet(value)
+		return met, met
+	}
+
+	margin := math.Max(math.Abs(this.Threshold)*ALERT_HYSTERESIS_RATIO, this.step())
+
+	var rearmed bool
+	if this.Op == AlertAtMost {
+		rearmed = value > this.Threshold+margin
+	} else {
+		rearmed = value < this.Threshold-margin
+	}
+
+	return !rearmed, false
+}
+
+// step is the smallest meaningful change of the alert's metric.
+func (this *Alert) step() float64 {
+	if this.Metric == AlertKD {
+		return 0.05
+	}
+
+	return 1
+}
+
+type AlertRepo struct {
+	db *sql.DB
+}
+
+func (this *AlertRepo) List(chatId int64) ([]Alert, error) {
+	rows, err := this.db.Query(`
+	SELECT id, chat_id, player_id, metric, op, threshold
+	FROM alerts
+	WHERE chat_id = ?
+	ORDER BY id ASC
+	`, chatId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return this.scanAlerts(rows)
+}
+
+// ForPlayer lists the alerts on the player, including the ones on anyone.
+func (this *AlertRepo) ForPlayer(playerId core.PlayerId) ([]Alert, error) {
+	rows, err := this.db.Query(`
+	SELECT id, chat_id, player_id, metric, op, threshold
+	FROM alerts
+	WHERE player_id = ? OR player_id IS NULL
+	`, playerId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return this.scanAlerts(rows)
+}
+
+func (this *AlertRepo) Add(alert Alert) (Alert, error) {
+	var count int
+	err := this.db.QueryRow(
+		`SELECT COUNT(*) FROM alerts WHERE chat_id = ?`, alert.ChatId,
+	).Scan(&count)
+	if err != nil {
+		return Alert{}, err
+	}
+	if count >= MAX_ALERTS_PER_CHAT {
+		return Alert{}, ERR_TOO_MANY_ALERTS
+	}
+
+	res, err := this.db.Exec(`
+	INSERT INTO alerts (chat_id, player_id, metric, op, threshold)
+	VALUES (?, ?, ?, ?, ?)
+	`, alert.ChatId, alert.PlayerId, alert.Metric, alert.Op, alert.Threshold)
+	if err != nil {
+		return Alert{}, err
+	}
+
+	alert.ID, err = res.LastInsertId()
+	if err != nil {
+		return Alert{}, err
+	}
+
+	return alert, nil
+}
+
+func (this *AlertRepo) Remove(chatId int64, alertId int64) error {
+	_, err := this.db.Exec(
+		`DELETE FROM alert_states WHERE alert_id IN (
+			SELECT id FROM alerts WHERE id = ? AND chat_id = ?
+		)`,
+		alertId, chatId,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = this.db.Exec(
+		`DELETE FROM alerts WHERE id = ? AND chat_id = ?`, alertId, chatId,
+	)
+	return err
+}
+
+func (this *AlertRepo) IsFired(
+	alertId int64, playerId core.PlayerId,
+) (bool, error) {
+	rows, err := this.db.Query(
+		`SELECT alert_id FROM alert_states WHERE alert_id = ? AND player_id = ?`,
+		alertId, playerId,
+	)
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	return rows.Next(), nil
+}
+
+func (this *AlertRepo) SetFired(
+	alertId int64, playerId core.PlayerId, fired bool,
+) error {
+	var err error
+	if fired {
+		_, err = this.db.Exec(
+			`INSERT OR IGNORE INTO alert_states (alert_id, player_id) VALUES (?, ?)`,
+			alertId, playerId,
+		)
+	} else {
+		_, err = this.db.Exec(
+			`DELETE FROM alert_states WHERE alert_id = ? AND player_id = ?`,
+			alertId, playerId,
+		)
+	}
+
+	return err
+}
+
+func (this *AlertRepo) scanAlerts(rows *sql.Rows) ([]Alert, error) {
+	alerts := make([]Alert, 0)
+
+	for rows.Next() {
+		var a Alert
+		var playerId sql.NullInt64
+
+		err := rows.Scan(
+			&a.ID, &a.ChatId, &playerId, &a.Metric, &a.Op, &a.Threshold,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if playerId.Valid {
+			id := core.PlayerId(playerId.Int64)
+			a.PlayerId = &id
+		}
+
+		alerts = append(alerts, a)
+	}
+
+	return alerts, nil
+}
+
+func CreateAlertRepo(db *sql.DB) (*AlertRepo, error) {
+	sql := `CREATE TABLE IF NOT EXISTS alerts (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		chat_id INTEGER NOT NULL,
+		player_id INTEGER,
+		metric TEXT NOT NULL,
+		op TEXT NOT NULL,
+		threshold REAL NOT NULL,
+		FOREIGN KEY (player_id) REFERENCES players(id) ON DELETE RESTRICT
+	);
+	CREATE INDEX IF NOT EXISTS idx_alerts_chat ON alerts(chat_id);
+	CREATE INDEX IF NOT EXISTS idx_alerts_player ON alerts(player_id);
+	CREATE TABLE IF NOT EXISTS alert_states (
+		alert_id INTEGER NOT NULL,
+		player_id INTEGER NOT NULL,
+		PRIMARY KEY (alert_id, player_id)
+	);`
+
+	_, err := db.Exec(sql)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AlertRepo{db}, nil
+}
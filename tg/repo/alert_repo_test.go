@@ -0,0 +1,143 @@
+package repo
+
+import (
+	"testing"
+
+	"github.com/thekhanj/csdmpro/core"
+	"github.com/thekhanj/csdmpro/db"
+)
+
+func TestAlertEvaluate(t *testing.T) {
+	alert := Alert{Metric: AlertScore, Op: AlertAtLeast, Threshold: 1000}
+
+	fired := false
+	notifications := 0
+	for _, score := range []float64{900, 1000, 990, 1010, 960, 1020, 940, 1000} {
+		var notify bool
+		fired, notify = alert.Evaluate(score, fired)
+		if notify {
+			notifications++
+		}
+	}
+
+	// fires at 1000, stays fired while wobbling within 5% of the threshold,
+	// re-arms at 940 and fires again at 1000
+	if notifications != 2 {
+		t.Fatalf("expected 2 notifications, got %d", notifications)
+	}
+
+	rank := Alert{Metric: AlertRank, Op: AlertAtMost, Threshold: 10}
+
+	fired, notify := rank.Evaluate(10, false)
+	if !fired || !notify {
+		t.Fatal("expected reaching rank 10 to fire")
+	}
+	fired, notify = rank.Evaluate(11, fired)
+	if !fired || notify {
+		t.Fatal("expected rank 11 to be within the hysteresis margin")
+	}
+	fired, _ = rank.Evaluate(12, fired)
+	if fired {
+		t.Fatal("expected rank 12 to re-arm the alert")
+	}
+}
+
+func TestAlertRepoSimply(t *testing.T) {
+	f := db.FakeDbFactory{}
+	db, err := f.Init()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Deinit()
+
+	err = addCoupleOfPlayers(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repo, err := CreateAlertRepo(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var chatId int64 = 1
+	var playerId core.PlayerId = 1
+
+	onPlayer, err := repo.Add(Alert{
+		ChatId: chatId, PlayerId: &playerId,
+		Metric: AlertRank, Op: AlertAtMost, Threshold: 10,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = repo.Add(Alert{
+		ChatId: chatId, Metric: AlertScore, Op: AlertAtLeast, Threshold: 100000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	alerts, err := repo.ForPlayer(playerId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(alerts) != 2 {
+		t.Fatalf("expected 2 alerts on player 1, got %d", len(alerts))
+	}
+
+	alerts, err = repo.ForPlayer(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(alerts) != 1 || alerts[0].PlayerId != nil {
+		t.Fatal("expected only the alert on anyone to apply to player 2")
+	}
+
+	err = repo.SetFired(onPlayer.ID, playerId, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fired, err := repo.IsFired(onPlayer.ID, playerId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !fired {
+		t.Fatal("expected alert to be fired")
+	}
+
+	err = repo.Remove(chatId, onPlayer.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	alerts, err = repo.List(chatId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(alerts) != 1 {
+		t.Fatalf("expected 1 alert left, got %d", len(alerts))
+	}
+
+	fired, err = repo.IsFired(onPlayer.ID, playerId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fired {
+		t.Fatal("expected removed alert's state to be gone")
+	}
+
+	for i := 1; i < MAX_ALERTS_PER_CHAT; i++ {
+		_, err = repo.Add(Alert{
+			ChatId: chatId, Metric: AlertKills, Op: AlertAtLeast, Threshold: 1,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err = repo.Add(Alert{
+		ChatId: chatId, Metric: AlertKills, Op: AlertAtLeast, Threshold: 1,
+	})
+	if err != ERR_TOO_MANY_ALERTS {
+		t.Fatalf("expected too many alerts error, got %v", err)
+	}
+}
@@ -1,15 +1,29 @@
 package repo
 
-import "database/sql"
+import (
+	"database/sql"
+	"time"
+)
+
+// Bilakh is a bilakhed chat, for good unless Until is set.
+type Bilakh struct {
+	ChatId int64
+	Until  time.Time
+}
+
+func (this Bilakh) IsTemporary() bool {
+	return !this.Until.IsZero()
+}
 
 type BilakhRepo struct {
 	db *sql.DB
 }
 
-func (this *BilakhRepo) IsBilakhed(chatId int64) (bool, error) {
-	sql := `SELECT chat_id FROM bilakhs WHERE chat_id = ?`
+func (this *BilakhRepo) IsBilakhed(chatId int64, now time.Time) (bool, error) {
+	sql := `SELECT chat_id FROM bilakhs
+	WHERE chat_id = ? AND (until IS NULL OR until > ?)`
 
-	rows, err := this.db.Query(sql, chatId)
+	rows, err := this.db.Query(sql, chatId, now.Unix())
 	if err != nil {
 		return false, err
 	}
@@ -18,9 +32,63 @@ func (this *BilakhRepo) IsBilakhed(chatId int64) (bool, error) {
 	return rows.Next(), nil
 }
 
+// List lists the bilakhs in effect at the moment.
+func (this *BilakhRepo) List(now time.Time) ([]Bilakh, error) {
+	rows, err := this.db.Query(`SELECT chat_id, until FROM bilakhs
+	WHERE until IS NULL OR until > ?
+	ORDER BY chat_id`, now.Unix())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	bilakhs := make([]Bilakh, 0)
+
+	for rows.Next() {
+		var b Bilakh
+		var until sql.NullInt64
+
+		err = rows.Scan(&b.ChatId, &until)
+		if err != nil {
+			return nil, err
+		}
+		if until.Valid {
+			b.Until = time.Unix(until.Int64, 0)
+		}
+
+		bilakhs = append(bilakhs, b)
+	}
+
+	return bilakhs, rows.Err()
+}
+
+// Add bilakhs the chat for good.
+func (this *BilakhRepo) Add(chatId int64) error {
+	_, err := this.db.Exec(`INSERT INTO bilakhs (chat_id, until) VALUES (?, NULL)
+	ON CONFLICT (chat_id) DO UPDATE SET until = NULL`, chatId)
+	return err
+}
+
+// AddUntil bilakhs the chat for a while. It never shortens a bilakh the
+// chat already has.
+func (this *BilakhRepo) AddUntil(chatId int64, until time.Time) error {
+	_, err := this.db.Exec(`INSERT INTO bilakhs (chat_id, until) VALUES (?, ?)
+	ON CONFLICT (chat_id) DO UPDATE SET until = excluded.until
+	WHERE bilakhs.until IS NOT NULL AND bilakhs.until < excluded.until`,
+		chatId, until.Unix(),
+	)
+	return err
+}
+
+func (this *BilakhRepo) Remove(chatId int64) error {
+	_, err := this.db.Exec(`DELETE FROM bilakhs WHERE chat_id = ?`, chatId)
+	return err
+}
+
 func CreateBilakhRepo(db *sql.DB) (*BilakhRepo, error) {
 	sql := `CREATE TABLE IF NOT EXISTS bilakhs (
-		chat_id INTEGER PRIMARY KEY
+		chat_id INTEGER PRIMARY KEY,
+		until INTEGER
 	);`
 
 	_, err := db.Exec(sql)
@@ -28,5 +96,10 @@ func CreateBilakhRepo(db *sql.DB) (*BilakhRepo, error) {
 		return nil, err
 	}
 
+	err = addColumn(db, "bilakhs", "until", "INTEGER")
+	if err != nil {
+		return nil, err
+	}
+
 	return &BilakhRepo{db}, nil
 }
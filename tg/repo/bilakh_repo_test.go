@@ -0,0 +1,130 @@
+package repo
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/thekhanj/csdmpro/db"
+)
+
+func TestBilakhRepoSimply(t *testing.T) {
+	f := db.FakeDbFactory{}
+	db, err := f.Init()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Deinit()
+
+	repo, err := CreateBilakhRepo(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, chatId := range []int64{2, 1, 2} {
+		err = repo.Add(chatId)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	now := time.Now()
+
+	bilakhs, err := repo.List(now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(bilakhs, []Bilakh{{ChatId: 1}, {ChatId: 2}}) {
+		t.Fatalf("unexpected bilakhs %v", bilakhs)
+	}
+
+	err = repo.Remove(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	isBilakhed, err := repo.IsBilakhed(1, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if isBilakhed {
+		t.Fatal("expected chat to be forgiven")
+	}
+}
+
+func TestBilakhRepoTemporary(t *testing.T) {
+	f := db.FakeDbFactory{}
+	db, err := f.Init()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Deinit()
+
+	repo, err := CreateBilakhRepo(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Unix(1700000000, 0)
+
+	err = repo.AddUntil(1, now.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a shorter one doesn't cut the first one short
+	err = repo.AddUntil(1, now.Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = repo.Add(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// nor turns a bilakh for good into a temporary one
+	err = repo.AddUntil(2, now.Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bilakhs, err := repo.List(now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Bilakh{{ChatId: 1, Until: now.Add(time.Hour)}, {ChatId: 2}}
+	if !slices.Equal(bilakhs, expected) {
+		t.Fatalf("expected bilakhs %v, got %v", expected, bilakhs)
+	}
+
+	later := now.Add(2 * time.Hour)
+
+	isBilakhed, err := repo.IsBilakhed(1, later)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if isBilakhed {
+		t.Fatal("expected the temporary bilakh to expire")
+	}
+
+	isBilakhed, err = repo.IsBilakhed(2, later)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !isBilakhed {
+		t.Fatal("expected the bilakh for good to stay")
+	}
+
+	// expired ones can be renewed
+	err = repo.AddUntil(1, later.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	isBilakhed, err = repo.IsBilakhed(1, later)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !isBilakhed {
+		t.Fatal("expected the bilakh to be renewed")
+	}
+}
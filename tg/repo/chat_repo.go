@@ -0,0 +1,243 @@
+package repo
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+var ERR_CHAT_NOT_FOUND error = errors.New("chat not found")
+var ERR_INVALID_AUDIENCE error = errors.New("invalid audience")
+
+// Audience is a group of chats to reach at once, e.g. by a broadcast.
+type Audience string
+
+const (
+	AudienceAll      Audience = "all"
+	AudienceWatchers Audience = "watchers"
+	AudiencePrivate  Audience = "private"
+	AudienceGroups   Audience = "groups"
+	AudienceActive   Audience = "active"
+)
+
+var AUDIENCES = []Audience{
+	AudienceAll, AudienceWatchers, AudiencePrivate, AudienceGroups, AudienceActive,
+}
+
+func ParseAudience(s string) (Audience, error) {
+	for _, a := range AUDIENCES {
+		if string(a) == s {
+			return a, nil
+		}
+	}
+
+	return "", ERR_INVALID_AUDIENCE
+}
+
+// Chat is what the bot knows of a chat it got an update from.
+type Chat struct {
+	ID int64
+	// Type is one of private, group, supergroup or channel, empty for chats
+	// known from before the registry.
+	Type string
+	// Title is the group's title, or the user's full name in private chats.
+	Title    string
+	Username string
+	// Language is the telegram language_code of whoever wrote last.
+	Language  string
+	FirstSeen time.Time
+	LastSeen  time.Time
+	// Blocked is set once the bot can't reach the chat anymore, e.g. the
+	// user blocked it or it got kicked out of the group.
+	Blocked bool
+}
+
+// Name is the chat's title, its username or otherwise nothing.
+func (this *Chat) Name() string {
+	if this.Title != "" {
+		return this.Title
+	}
+	if this.Username != "" {
+		return "@" + this.Username
+	}
+
+	return ""
+}
+
+type ChatRepo struct {
+	db *sql.DB
+}
+
+// Seen records an update from the chat, updating what's known of it. A
+// chat writing to the bot isn't blocked anymore.
+func (this *ChatRepo) Seen(chat Chat, at time.Time) error {
+	_, err := this.db.Exec(`
+	INSERT INTO chats (id, type, title, username, language, first_seen, last_seen)
+	VALUES (?, ?, ?, ?, ?, ?, ?)
+	ON CONFLICT(id) DO UPDATE SET
+		type = excluded.type,
+		title = excluded.title,
+		username = excluded.username,
+		language = CASE
+			WHEN excluded.language != '' THEN excluded.language
+			ELSE chats.language
+		END,
+		last_seen = excluded.last_seen,
+		blocked = FALSE
+	`,
+		chat.ID, chat.Type, chat.Title, chat.Username, chat.Language,
+		at.Unix(), at.Unix(),
+	)
+	return err
+}
+
+func (this *ChatRepo) SetBlocked(chatId int64, blocked bool) error {
+	_, err := this.db.Exec(
+		`UPDATE chats SET blocked = ? WHERE id = ?`, blocked, chatId,
+	)
+	return err
+}
+
+func (this *ChatRepo) Get(chatId int64) (Chat, error) {
+	rows, err := this.db.Query(`
+	SELECT id, type, title, username, language, first_seen, last_seen, blocked
+	FROM chats
+	WHERE id = ?
+	`, chatId)
+	if err != nil {
+		return Chat{}, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return Chat{}, ERR_CHAT_NOT_FOUND
+	}
+
+	return this.scanChat(rows)
+}
+
+// Audience lists the chats of the audience that haven't blocked the bot. Chats watching
+// players are the ones having players or rules in any of their watchlists.
+func (this *ChatRepo) Audience(a Audience, now time.Time) ([]Chat, error) {
+	cond := "TRUE"
+	args := []any{}
+
+	switch a {
+	case AudienceAll:
+	case AudienceWatchers:
+		cond = `id IN (
+			SELECT w.chat_id FROM watchlists w JOIN watchlist e ON e.list_id = w.id
+			UNION
+			SELECT w.chat_id FROM watchlists w JOIN watch_rules r ON r.list_id = w.id
+		)`
+	case AudiencePrivate:
+		cond = `type = 'private'`
+	case AudienceGroups:
+		cond = `type IN ('group', 'supergroup')`
+	case AudienceActive:
+		cond = `last_seen >= ?`
+		args = append(args, now.Add(-ACTIVE_CHAT_PERIOD).Unix())
+	default:
+		return nil, ERR_INVALID_AUDIENCE
+	}
+
+	rows, err := this.db.Query(`
+	SELECT id, type, title, username, language, first_seen, last_seen, blocked
+	FROM chats
+	WHERE NOT blocked AND `+cond+`
+	ORDER BY id
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	chats := make([]Chat, 0)
+
+	for rows.Next() {
+		c, err := this.scanChat(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		chats = append(chats, c)
+	}
+
+	return chats, rows.Err()
+}
+
+func (this *ChatRepo) scanChat(rows *sql.Rows) (Chat, error) {
+	var c Chat
+	var firstSeen, lastSeen int64
+
+	err := rows.Scan(
+		&c.ID, &c.Type, &c.Title, &c.Username, &c.Language,
+		&firstSeen, &lastSeen, &c.Blocked,
+	)
+	if err != nil {
+		return Chat{}, err
+	}
+
+	c.FirstSeen = time.Unix(firstSeen, 0)
+	c.LastSeen = time.Unix(lastSeen, 0)
+
+	return c, nil
+}
+
+// seedChats registers the chats known from before the registry, out of
+// the tables having a chat_id.
+func seedChats(db *sql.DB) error {
+	var count int
+	err := db.QueryRow(`SELECT COUNT(*) FROM chats`).Scan(&count)
+	if err != nil || count != 0 {
+		return err
+	}
+
+	now := time.Now().Unix()
+
+	for _, table := range []string{"chat_settings", "watchlists", "alerts"} {
+		exists, err := hasColumn(db, table, "chat_id")
+		if err != nil {
+			return err
+		}
+		if !exists {
+			continue
+		}
+
+		_, err = db.Exec(`
+		INSERT OR IGNORE INTO chats (id, first_seen, last_seen)
+		SELECT DISTINCT chat_id, ?, ? FROM `+table,
+			now, now,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func CreateChatRepo(db *sql.DB) (*ChatRepo, error) {
+	sql := `CREATE TABLE IF NOT EXISTS chats (
+		id INTEGER PRIMARY KEY,
+		type TEXT NOT NULL DEFAULT '',
+		title TEXT NOT NULL DEFAULT '',
+		username TEXT NOT NULL DEFAULT '',
+		language TEXT NOT NULL DEFAULT '',
+		first_seen INTEGER NOT NULL,
+		last_seen INTEGER NOT NULL,
+		blocked BOOLEAN NOT NULL DEFAULT FALSE
+	);`
+
+	_, err := db.Exec(sql)
+	if err != nil {
+		return nil, err
+	}
+
+	err = seedChats(db)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ChatRepo{db}, nil
+}
@@ -0,0 +1,166 @@
+package repo
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/thekhanj/csdmpro/db"
+)
+
+func TestChatRepoSimply(t *testing.T) {
+	f := db.FakeDbFactory{}
+	db, err := f.Init()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Deinit()
+
+	repo, err := CreateChatRepo(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = repo.Get(1)
+	if err != ERR_CHAT_NOT_FOUND {
+		t.Fatalf("expected no chat initially, got %v", err)
+	}
+
+	first := time.Unix(1000, 0)
+	last := time.Unix(2000, 0)
+
+	err = repo.Seen(Chat{ID: 1, Type: "private", Title: "Ali", Language: "fa"}, first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = repo.SetBlocked(1, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chats, err := repo.Audience(AudienceAll, last)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chats) != 0 {
+		t.Fatal("expected blocked chats to be unreachable")
+	}
+
+	err = repo.Seen(Chat{ID: 1, Type: "private", Title: "Ali Reza"}, last)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := repo.Get(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Blocked {
+		t.Fatal("expected the chat writing again to be unblocked")
+	}
+	if c.Title != "Ali Reza" || c.Language != "fa" {
+		t.Fatalf("unexpected chat %+v", c)
+	}
+	if !c.FirstSeen.Equal(first) || !c.LastSeen.Equal(last) {
+		t.Fatalf("unexpected first and last seen %s, %s", c.FirstSeen, c.LastSeen)
+	}
+
+	chats, err = repo.Audience(AudienceAll, last)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chats) != 1 {
+		t.Fatalf("expected 1 reachable chat, got %d", len(chats))
+	}
+}
+
+func TestChatRepoAudience(t *testing.T) {
+	f := db.FakeDbFactory{}
+	db, err := f.Init()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Deinit()
+
+	err = addCoupleOfPlayers(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	watchlists, err := CreateWatchlistRepo(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repo, err := CreateChatRepo(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	longAgo := now.Add(-30 * 24 * time.Hour)
+
+	seen := []struct {
+		chat Chat
+		at   time.Time
+	}{
+		{Chat{ID: 1, Type: "private"}, now},
+		{Chat{ID: 2, Type: "private"}, longAgo},
+		{Chat{ID: -3, Type: "supergroup"}, now},
+		{Chat{ID: -4, Type: "group"}, longAgo},
+		{Chat{ID: 5, Type: "private"}, now},
+	}
+	for _, s := range seen {
+		err = repo.Seen(s.chat, s.at)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err = repo.SetBlocked(5, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = watchlists.Add(2, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	list, err := watchlists.Selected(-4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = watchlists.AddRule(WatchRule{ListId: list.ID, Kind: WatchRuleTop, Top: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[Audience][]int64{
+		AudienceAll:      {-4, -3, 1, 2},
+		AudienceWatchers: {-4, 2},
+		AudiencePrivate:  {1, 2},
+		AudienceGroups:   {-4, -3},
+		AudienceActive:   {-3, 1},
+	}
+
+	for audience, ids := range expected {
+		chats, err := repo.Audience(audience, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got := make([]int64, 0, len(chats))
+		for _, c := range chats {
+			got = append(got, c.ID)
+		}
+
+		if !slices.Equal(got, ids) {
+			t.Fatalf("expected %s to be %v, got %v", audience, ids, got)
+		}
+	}
+
+	_, err = repo.Audience("nobody", now)
+	if err != ERR_INVALID_AUDIENCE {
+		t.Fatalf("expected invalid audience, got %v", err)
+	}
+}
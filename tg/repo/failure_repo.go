@@ -0,0 +1,110 @@
+package repo
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Only the latest failures are kept, they're for looking into what's going
+// wrong lately.
+const MAX_FAILURES = 500
+
+// Failure is a request a controller failed to handle.
+type Failure struct {
+	ID      int64
+	At      time.Time
+	ChatId  int64
+	Route   string
+	Err     string
+	Latency time.Duration
+}
+
+type FailureRepo struct {
+	db *sql.DB
+}
+
+func (this *FailureRepo) Add(f Failure) (int64, error) {
+	res, err := this.db.Exec(`
+	INSERT INTO failures (at, chat_id, route, error, latency)
+	VALUES (?, ?, ?, ?, ?)`,
+		f.At.Unix(), f.ChatId, f.Route, f.Err, f.Latency.Milliseconds(),
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = this.db.Exec(`DELETE FROM failures WHERE id <= ?`, id-MAX_FAILURES)
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// Recent lists the latest failures, the latest first.
+func (this *FailureRepo) Recent(limit int) ([]Failure, error) {
+	rows, err := this.db.Query(`
+	SELECT id, at, chat_id, route, error, latency FROM failures
+	ORDER BY id DESC
+	LIMIT ?`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	failures := make([]Failure, 0)
+
+	for rows.Next() {
+		var f Failure
+		var at, latency int64
+
+		err = rows.Scan(&f.ID, &at, &f.ChatId, &f.Route, &f.Err, &latency)
+		if err != nil {
+			return nil, err
+		}
+		f.At = time.Unix(at, 0)
+		f.Latency = time.Duration(latency) * time.Millisecond
+
+		failures = append(failures, f)
+	}
+
+	return failures, rows.Err()
+}
+
+func (this *FailureRepo) CountSince(since time.Time) (int, error) {
+	var count int
+
+	err := this.db.QueryRow(
+		`SELECT COUNT(*) FROM failures WHERE at >= ?`, since.Unix(),
+	).Scan(&count)
+
+	return count, err
+}
+
+func (this *FailureRepo) Clear() error {
+	_, err := this.db.Exec(`DELETE FROM failures`)
+	return err
+}
+
+func CreateFailureRepo(db *sql.DB) (*FailureRepo, error) {
+	sql := `CREATE TABLE IF NOT EXISTS failures (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		at INTEGER NOT NULL,
+		chat_id INTEGER NOT NULL,
+		route TEXT NOT NULL,
+		error TEXT NOT NULL,
+		latency INTEGER NOT NULL
+	);`
+
+	_, err := db.Exec(sql)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FailureRepo{db}, nil
+}
@@ -0,0 +1,75 @@
+package repo
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/thekhanj/csdmpro/db"
+)
+
+func TestFailureRepoSimply(t *testing.T) {
+	f := db.FakeDbFactory{}
+	db, err := f.Init()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Deinit()
+
+	repo, err := CreateFailureRepo(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Unix(1700000000, 0)
+
+	for i := 0; i < MAX_FAILURES+10; i++ {
+		_, err = repo.Add(Failure{
+			At:      now.Add(time.Duration(i-MAX_FAILURES) * time.Minute),
+			ChatId:  int64(i),
+			Route:   "/stats/1",
+			Err:     fmt.Sprintf("failure %d", i),
+			Latency: 1500 * time.Millisecond,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	failures, err := repo.Recent(MAX_FAILURES * 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(failures) != MAX_FAILURES {
+		t.Fatalf("expected only the latest %d failures, got %d", MAX_FAILURES, len(failures))
+	}
+
+	last := failures[0]
+	if last.ChatId != MAX_FAILURES+9 || last.Err != fmt.Sprintf("failure %d", MAX_FAILURES+9) {
+		t.Fatalf("expected the latest failure first, got %+v", last)
+	}
+	if last.Latency != 1500*time.Millisecond || last.Route != "/stats/1" {
+		t.Fatalf("unexpected failure %+v", last)
+	}
+
+	count, err := repo.CountSince(now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 10 {
+		t.Fatalf("expected 10 failures since now, got %d", count)
+	}
+
+	err = repo.Clear()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	failures, err = repo.Recent(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(failures) != 0 {
+		t.Fatalf("expected failures to be cleared, got %v", failures)
+	}
+}
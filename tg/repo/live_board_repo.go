@@ -0,0 +1,84 @@
+package repo
+
+import (
+	"database/sql"
+	"errors"
+)
+
+var ERR_LIVE_BOARD_NOT_FOUND error = errors.New("live board not found")
+
+// LiveBoard is the message of a chat the bot keeps editing with the online
+// players.
+type LiveBoard struct {
+	ChatId    int64
+	MessageId int
+}
+
+type LiveBoardRepo struct {
+	db *sql.DB
+}
+
+func (this *LiveBoardRepo) List() ([]LiveBoard, error) {
+	rows, err := this.db.Query(`SELECT chat_id, message_id FROM live_boards`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	boards := make([]LiveBoard, 0)
+
+	for rows.Next() {
+		var b LiveBoard
+		err = rows.Scan(&b.ChatId, &b.MessageId)
+		if err != nil {
+			return nil, err
+		}
+
+		boards = append(boards, b)
+	}
+
+	return boards, rows.Err()
+}
+
+func (this *LiveBoardRepo) Get(chatId int64) (LiveBoard, error) {
+	b := LiveBoard{ChatId: chatId}
+
+	err := this.db.QueryRow(
+		`SELECT message_id FROM live_boards WHERE chat_id = ?`, chatId,
+	).Scan(&b.MessageId)
+	if err == sql.ErrNoRows {
+		return LiveBoard{}, ERR_LIVE_BOARD_NOT_FOUND
+	}
+	if err != nil {
+		return LiveBoard{}, err
+	}
+
+	return b, nil
+}
+
+// Set makes the message the chat's live board, replacing the previous one.
+func (this *LiveBoardRepo) Set(b LiveBoard) error {
+	_, err := this.db.Exec(`
+	INSERT INTO live_boards (chat_id, message_id) VALUES (?, ?)
+	ON CONFLICT(chat_id) DO UPDATE SET message_id = excluded.message_id
+	`, b.ChatId, b.MessageId)
+	return err
+}
+
+func (this *LiveBoardRepo) Remove(chatId int64) error {
+	_, err := this.db.Exec(`DELETE FROM live_boards WHERE chat_id = ?`, chatId)
+	return err
+}
+
+func CreateLiveBoardRepo(db *sql.DB) (*LiveBoardRepo, error) {
+	createLiveBoardsTable := `CREATE TABLE IF NOT EXISTS live_boards (
+		chat_id INTEGER PRIMARY KEY,
+		message_id INTEGER NOT NULL
+	);`
+	_, err := db.Exec(createLiveBoardsTable)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LiveBoardRepo{db}, nil
+}
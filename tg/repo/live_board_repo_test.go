@@ -0,0 +1,60 @@
+package repo
+
+import (
+	"testing"
+
+	"github.com/thekhanj/csdmpro/db"
+)
+
+func TestLiveBoardRepoSimply(t *testing.T) {
+	f := db.FakeDbFactory{}
+	db, err := f.Init()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Deinit()
+
+	repo, err := CreateLiveBoardRepo(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = repo.Get(1)
+	if err != ERR_LIVE_BOARD_NOT_FOUND {
+		t.Fatalf("expected no board initially, got %v", err)
+	}
+
+	err = repo.Set(LiveBoard{ChatId: 1, MessageId: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = repo.Set(LiveBoard{ChatId: 1, MessageId: 20})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = repo.Set(LiveBoard{ChatId: 2, MessageId: 30})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := repo.Get(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.MessageId != 20 {
+		t.Fatalf("expected the board to be replaced, got %+v", b)
+	}
+
+	err = repo.Remove(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	boards, err := repo.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(boards) != 1 || boards[0].ChatId != 1 {
+		t.Fatalf("expected only chat 1's board, got %+v", boards)
+	}
+}
@@ -0,0 +1,45 @@
+package repo
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// addColumn adds the column to a table created by an older version, if it's
+// not there already.
+func addColumn(db *sql.DB, table string, column string, definition string) error {
+	exists, err := hasColumn(db, table, column)
+	if err != nil || exists {
+		return err
+	}
+
+	_, err = db.Exec(
+		fmt.Sprintf(`ALTER TABLE %s ADD COLUMN %s %s`, table, column, definition),
+	)
+	return err
+}
+
+func hasColumn(db *sql.DB, table string, column string) (bool, error) {
+	rows, err := db.Query(fmt.Sprintf(`PRAGMA table_info(%s)`, table))
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var cid, notNull, pk int
+		var name, typ string
+		var dflt sql.NullString
+
+		err = rows.Scan(&cid, &name, &typ, &notNull, &dflt, &pk)
+		if err != nil {
+			return false, err
+		}
+
+		if name == column {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
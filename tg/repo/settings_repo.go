@@ -0,0 +1,231 @@
+package repo
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/thekhanj/csdmpro/core"
+)
+
+type DigestFrequency string
+
+const (
+	DigestOff    DigestFrequency = "off"
+	DigestDaily  DigestFrequency = "daily"
+	DigestWeekly DigestFrequency = "weekly"
+)
+
+const (
+	DEFAULT_TIMEZONE    = "Asia/Tehran"
+	DEFAULT_DIGEST_TIME = 21 * 60
+)
+
+type ChatSettings struct {
+	ChatId   int64
+	Timezone string
+	Digest   DigestFrequency
+	// DigestTime is the time of the day, in minutes after midnight, the
+	// digest is sent at. Weekly digests are sent on mondays.
+	DigestTime     int
+	DigestLastSent *time.Time
+	// MembersCanEdit lets the members of a group, and not only its admins,
+	// edit the shared watchlist.
+	MembersCanEdit bool
+	// Language is the code of the language the bot talks in, empty until
+	// it's either picked or detected.
+	Language string
+}
+
+// Location loads the chat's timezone, falling back to the server's.
+func (this *ChatSettings) Location() *time.Location {
+	loc, err := time.LoadLocation(this.Timezone)
+	if err != nil {
+		return time.Local
+	}
+
+	return loc
+}
+
+// DigestPeriodStart is the beginning of the period the digest sent at now
+// covers.
+func (this *ChatSettings) DigestPeriodStart(now time.Time) time.Time {
+	if this.Digest == DigestWeekly {
+		return now.AddDate(0, 0, -7)
+	}
+
+	return now.AddDate(0, 0, -1)
+}
+
+// IsDigestDue tells whether the chat's latest scheduled digest, as of now, is
+// yet to be sent.
+func (this *ChatSettings) IsDigestDue(now time.Time) bool {
+	if this.Digest == DigestOff || this.Digest == "" {
+		return false
+	}
+
+	local := now.In(this.Location())
+
+	scheduled := core.StartOfDay(local)
+	if this.Digest == DigestWeekly {
+		scheduled = core.StartOfWeek(local)
+	}
+	scheduled = scheduled.Add(time.Duration(this.DigestTime) * time.Minute)
+
+	if now.Before(scheduled) {
+		return false
+	}
+
+	return this.DigestLastSent == nil || this.DigestLastSent.Before(scheduled)
+}
+
+type SettingsRepo struct {
+	db *sql.DB
+}
+
+func (this *SettingsRepo) Get(chatId int64) (ChatSettings, error) {
+	rows, err := this.db.Query(`
+	SELECT chat_id, timezone, digest, digest_time, digest_last_sent,
+		members_can_edit, language
+	FROM chat_settings
+	WHERE chat_id = ?
+	`, chatId)
+	if err != nil {
+		return ChatSettings{}, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return ChatSettings{
+			ChatId:     chatId,
+			Timezone:   DEFAULT_TIMEZONE,
+			Digest:     DigestOff,
+			DigestTime: DEFAULT_DIGEST_TIME,
+		}, nil
+	}
+
+	return this.scanSettings(rows)
+}
+
+// DigestSubscribers lists the settings of the chats that opted in for
+// digests and haven't blocked the bot.
+func (this *SettingsRepo) DigestSubscribers() ([]ChatSettings, error) {
+	rows, err := this.db.Query(`
+	SELECT s.chat_id, s.timezone, s.digest, s.digest_time, s.digest_last_sent,
+		s.members_can_edit, s.language
+	FROM chat_settings s JOIN chats c ON c.id = s.chat_id
+	WHERE s.digest != ? AND NOT c.blocked
+	`, DigestOff)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ret := make([]ChatSettings, 0)
+
+	for rows.Next() {
+		s, err := this.scanSettings(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		ret = append(ret, s)
+	}
+
+	return ret, nil
+}
+
+func (this *SettingsRepo) SetTimezone(chatId int64, timezone string) error {
+	return this.set(chatId, "timezone", timezone)
+}
+
+func (this *SettingsRepo) SetDigest(
+	chatId int64, frequency DigestFrequency,
+) error {
+	return this.set(chatId, "digest", frequency)
+}
+
+func (this *SettingsRepo) SetDigestTime(chatId int64, minutes int) error {
+	return this.set(chatId, "digest_time", minutes)
+}
+
+func (this *SettingsRepo) SetMembersCanEdit(chatId int64, canEdit bool) error {
+	return this.set(chatId, "members_can_edit", canEdit)
+}
+
+func (this *SettingsRepo) SetLanguage(chatId int64, language string) error {
+	return this.set(chatId, "language", language)
+}
+
+func (this *SettingsRepo) MarkDigestSent(chatId int64, t time.Time) error {
+	return this.set(chatId, "digest_last_sent", t.Unix())
+}
+
+// set updates a single column of the chat's settings, creating them with
+// the defaults first if missing.
+func (this *SettingsRepo) set(chatId int64, column string, value any) error {
+	_, err := this.db.Exec(`
+	INSERT INTO chat_settings (chat_id, timezone, digest, digest_time)
+	VALUES (?, ?, ?, ?)
+	ON CONFLICT(chat_id) DO NOTHING
+	`, chatId, DEFAULT_TIMEZONE, DigestOff, DEFAULT_DIGEST_TIME)
+	if err != nil {
+		return err
+	}
+
+	_, err = this.db.Exec(
+		`UPDATE chat_settings SET `+column+` = ? WHERE chat_id = ?`,
+		value, chatId,
+	)
+	return err
+}
+
+func (this *SettingsRepo) scanSettings(rows *sql.Rows) (ChatSettings, error) {
+	var s ChatSettings
+	var lastSent sql.NullInt64
+
+	err := rows.Scan(
+		&s.ChatId, &s.Timezone, &s.Digest, &s.DigestTime, &lastSent,
+		&s.MembersCanEdit, &s.Language,
+	)
+	if err != nil {
+		return ChatSettings{}, err
+	}
+
+	if lastSent.Valid {
+		t := time.Unix(lastSent.Int64, 0)
+		s.DigestLastSent = &t
+	}
+
+	return s, nil
+}
+
+func CreateSettingsRepo(db *sql.DB) (*SettingsRepo, error) {
+	sql := `CREATE TABLE IF NOT EXISTS chat_settings (
+		chat_id INTEGER PRIMARY KEY,
+		timezone TEXT NOT NULL,
+		digest TEXT NOT NULL,
+		digest_time INTEGER NOT NULL,
+		digest_last_sent INTEGER,
+		members_can_edit BOOLEAN NOT NULL DEFAULT FALSE,
+		language TEXT NOT NULL DEFAULT ''
+	);`
+
+	_, err := db.Exec(sql)
+	if err != nil {
+		return nil, err
+	}
+
+	err = addColumn(
+		db, "chat_settings", "members_can_edit", "BOOLEAN NOT NULL DEFAULT FALSE",
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	err = addColumn(db, "chat_settings", "language", "TEXT NOT NULL DEFAULT ''")
+	if err != nil {
+		return nil, err
+	}
+
+	return &SettingsRepo{db}, nil
+}
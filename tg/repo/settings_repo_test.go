@@ -0,0 +1,188 @@
+package repo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/thekhanj/csdmpro/db"
+)
+
+func TestSettingsRepoSimply(t *testing.T) {
+	f := db.FakeDbFactory{}
+	db, err := f.Init()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Deinit()
+
+	repo, err := CreateSettingsRepo(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chats, err := CreateChatRepo(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []int64{1, 2, 3} {
+		err = chats.Seen(Chat{ID: id, Type: "private"}, time.Now())
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var chatId int64 = 1
+	s, err := repo.Get(chatId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Timezone != DEFAULT_TIMEZONE || s.Digest != DigestOff ||
+		s.DigestTime != DEFAULT_DIGEST_TIME || s.DigestLastSent != nil {
+		t.Fatalf("expected default settings, got %+v", s)
+	}
+
+	err = repo.SetDigest(chatId, DigestDaily)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = repo.SetTimezone(chatId, "Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = repo.SetDigest(2, DigestOff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = repo.SetDigest(3, DigestDaily)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = chats.SetBlocked(3, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err = repo.Get(chatId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Timezone != "Europe/Berlin" || s.Digest != DigestDaily ||
+		s.DigestTime != DEFAULT_DIGEST_TIME {
+		t.Fatalf("unexpected settings %+v", s)
+	}
+
+	subscribers, err := repo.DigestSubscribers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(subscribers) != 1 || subscribers[0].ChatId != chatId {
+		t.Fatalf("expected only chat 1 to be subscribed, got %+v", subscribers)
+	}
+
+	sent := time.Unix(1700000000, 0)
+	err = repo.MarkDigestSent(chatId, sent)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err = repo.Get(chatId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.DigestLastSent == nil || !s.DigestLastSent.Equal(sent) {
+		t.Fatalf("expected digest last sent at %s, got %v", sent, s.DigestLastSent)
+	}
+}
+
+func TestChatSettingsIsDigestDue(t *testing.T) {
+	loc, err := time.LoadLocation("Asia/Tehran")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a wednesday
+	at := func(day int, hour int) time.Time {
+		return time.Date(2025, 1, day, hour, 0, 0, 0, loc)
+	}
+
+	s := ChatSettings{
+		Timezone:   "Asia/Tehran",
+		Digest:     DigestDaily,
+		DigestTime: 21 * 60,
+	}
+
+	if s.IsDigestDue(at(1, 20)) {
+		t.Fatal("expected daily digest not to be due before its time")
+	}
+	if !s.IsDigestDue(at(1, 21)) {
+		t.Fatal("expected daily digest to be due at its time")
+	}
+
+	sent := at(1, 21)
+	s.DigestLastSent = &sent
+	if s.IsDigestDue(at(1, 23)) {
+		t.Fatal("expected daily digest not to be due once sent")
+	}
+	if !s.IsDigestDue(at(2, 21)) {
+		t.Fatal("expected daily digest to be due the next day")
+	}
+
+	s.Digest = DigestWeekly
+	if s.IsDigestDue(at(5, 21)) {
+		t.Fatal("expected weekly digest not to be due before monday")
+	}
+	if !s.IsDigestDue(at(6, 21)) {
+		t.Fatal("expected weekly digest to be due on monday")
+	}
+
+	s.Digest = DigestOff
+	if s.IsDigestDue(at(6, 21)) {
+		t.Fatal("expected disabled digest never to be due")
+	}
+}
+
+func TestSettingsRepoMigratesMembersCanEdit(t *testing.T) {
+	f := db.FakeDbFactory{}
+	db, err := f.Init()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Deinit()
+
+	_, err = db.Exec(`CREATE TABLE chat_settings (
+		chat_id INTEGER PRIMARY KEY,
+		timezone TEXT NOT NULL,
+		digest TEXT NOT NULL,
+		digest_time INTEGER NOT NULL,
+		digest_last_sent INTEGER
+	);
+	INSERT INTO chat_settings VALUES (1, 'UTC', 'daily', 0, NULL);`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repo, err := CreateSettingsRepo(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := repo.Get(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.MembersCanEdit || s.Digest != DigestDaily {
+		t.Fatalf("unexpected migrated settings %+v", s)
+	}
+
+	err = repo.SetMembersCanEdit(1, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err = repo.Get(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s.MembersCanEdit {
+		t.Fatal("expected members to be able to edit")
+	}
+}
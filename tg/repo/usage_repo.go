@@ -0,0 +1,60 @@
+package repo
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Chats count as active when seen within this long.
+const ACTIVE_CHAT_PERIOD = 7 * 24 * time.Hour
+
+type UsageStats struct {
+	Chats          int
+	ActiveChats    int
+	BlockedChats   int
+	Watchlists     int
+	WatchedPlayers int
+	WatchRules     int
+	Alerts         int
+	LiveBoards     int
+	Bilakhs        int
+	Players        int
+	Onlines        int
+}
+
+// UsageRepo reads how the bot is used across the other repos' tables, which
+// have to be created by the time it's used.
+type UsageRepo struct {
+	db *sql.DB
+}
+
+func (this *UsageRepo) Stats(now time.Time) (UsageStats, error) {
+	var s UsageStats
+
+	err := this.db.QueryRow(`
+	SELECT
+		(SELECT COUNT(*) FROM chats),
+		(SELECT COUNT(*) FROM chats WHERE last_seen >= ? AND NOT blocked),
+		(SELECT COUNT(*) FROM chats WHERE blocked),
+		(SELECT COUNT(*) FROM watchlists),
+		(SELECT COUNT(DISTINCT player_id) FROM watchlist),
+		(SELECT COUNT(*) FROM watch_rules),
+		(SELECT COUNT(*) FROM alerts),
+		(SELECT COUNT(*) FROM live_boards),
+		(SELECT COUNT(*) FROM bilakhs WHERE until IS NULL OR until > ?),
+		(SELECT COUNT(*) FROM players),
+		(SELECT COUNT(*) FROM onlines WHERE end_time IS NULL)
+	`, now.Add(-ACTIVE_CHAT_PERIOD).Unix(), now.Unix()).Scan(
+		&s.Chats, &s.ActiveChats, &s.BlockedChats, &s.Watchlists, &s.WatchedPlayers, &s.WatchRules, &s.Alerts,
+		&s.LiveBoards, &s.Bilakhs, &s.Players, &s.Onlines,
+	)
+	if err != nil {
+		return UsageStats{}, err
+	}
+
+	return s, nil
+}
+
+func CreateUsageRepo(db *sql.DB) (*UsageRepo, error) {
+	return &UsageRepo{db}, nil
+}
@@ -0,0 +1,106 @@
+package repo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/thekhanj/csdmpro/db"
+)
+
+func TestUsageRepoSimply(t *testing.T) {
+	f := db.FakeDbFactory{}
+	db, err := f.Init()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Deinit()
+
+	err = addCoupleOfPlayers(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	watchlists, err := CreateWatchlistRepo(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	settings, err := CreateSettingsRepo(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	alerts, err := CreateAlertRepo(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = CreateLiveBoardRepo(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bilakhs, err := CreateBilakhRepo(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = watchlists.Add(1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = watchlists.Add(2, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = settings.SetTimezone(2, "UTC")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = alerts.Add(Alert{ChatId: 3, Metric: AlertScore, Op: AlertAtLeast})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = bilakhs.Add(4)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the chats so far are known from before the registry
+	chats, err := CreateChatRepo(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+
+	err = chats.Seen(Chat{ID: 5, Type: "private"}, now.Add(-30*24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = chats.SetBlocked(1, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repo, err := CreateUsageRepo(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := repo.Stats(now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Chats != 4 || s.ActiveChats != 2 || s.BlockedChats != 1 {
+		t.Fatalf(
+			"expected 4 chats, 2 active and 1 blocked, got %d, %d and %d",
+			s.Chats, s.ActiveChats, s.BlockedChats,
+		)
+	}
+	if s.Watchlists != 2 || s.WatchedPlayers != 1 {
+		t.Fatalf(
+			"expected 2 watchlists watching 1 player, got %d watching %d",
+			s.Watchlists, s.WatchedPlayers,
+		)
+	}
+	if s.Alerts != 1 || s.Bilakhs != 1 || s.Players != 100 {
+		t.Fatalf("unexpected stats %+v", s)
+	}
+}
@@ -0,0 +1,274 @@
+package repo
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/thekhanj/csdmpro/core"
+)
+
+type WatchRuleKind string
+
+const (
+	// WatchRulePattern watches anyone whose name matches the rule's pattern.
+	WatchRulePattern WatchRuleKind = "pattern"
+	// WatchRuleTop watches anyone ranked in the rule's top places.
+	WatchRuleTop WatchRuleKind = "top"
+)
+
+const (
+	MAX_WATCH_RULES_PER_LIST = 10
+	MAX_WATCH_PATTERN_LEN    = 32
+	MAX_WATCH_TOP            = 100
+)
+
+var (
+	ERR_TOO_MANY_WATCH_RULES  error = core.NewUserError("errors.too_many_watch_rules")
+	ERR_INVALID_WATCH_PATTERN error = core.NewUserError("errors.invalid_watch_pattern")
+	ERR_INVALID_WATCH_TOP     error = core.NewUserError("errors.invalid_watch_top")
+	ERR_WATCH_RULE_NOT_FOUND  error = errors.New("rule not found")
+)
+
+// WatchRule watches players of a watchlist dynamically, it's evaluated when
+// players get online or offline rather than when it's added, so newcomers
+// matching it are covered too.
+type WatchRule struct {
+	ID      int64
+	ListId  int64
+	Kind    WatchRuleKind
+	Pattern string
+	Top     int
+}
+
+func (this *WatchRule) Matches(p core.Player) bool {
+	switch this.Kind {
+	case WatchRulePattern:
+		return MatchPattern(this.Pattern, p.Name)
+	case WatchRuleTop:
+		return p.Rank != nil && *p.Rank <= this.Top
+	default:
+		return false
+	}
+}
+
+func (this *WatchRule) validate() error {
+	switch this.Kind {
+	case WatchRulePattern:
+		if utf8.RuneCountInString(this.Pattern) > MAX_WATCH_PATTERN_LEN ||
+			strings.Trim(this.Pattern, "*? ") == "" {
+			return ERR_INVALID_WATCH_PATTERN
+		}
+	case WatchRuleTop:
+		if this.Top < 1 || this.Top > MAX_WATCH_TOP {
+			return ERR_INVALID_WATCH_TOP
+		}
+	default:
+		return errors.New("unknown watch rule kind")
+	}
+
+	return nil
+}
+
+// MatchPattern matches the name against a case-insensitive glob pattern, "*"
+// matches any run of characters and "?" a single one. Everything else,
+// brackets included, is matched literally so clan tags like "[TAG]*" work as
+// they look.
+func MatchPattern(pattern string, name string) bool {
+	p := []rune(strings.ToLower(pattern))
+	n := []rune(strings.ToLower(name))
+
+	// the last star seen and the name position it's matched up to, to
+	// backtrack to on mismatches
+	star, starName := -1, 0
+	i, j := 0, 0
+
+	for j < len(n) {
+		switch {
+		case i < len(p) && (p[i] == '?' || p[i] == n[j]):
+			i++
+			j++
+		case i < len(p) && p[i] == '*':
+			star, starName = i, j
+			i++
+		case star != -1:
+			i = star + 1
+			starName++
+			j = starName
+		default:
+			return false
+		}
+	}
+
+	for i < len(p) && p[i] == '*' {
+		i++
+	}
+
+	return i == len(p)
+}
+
+func (this *WatchlistRepo) Rules(listId int64) ([]WatchRule, error) {
+	rows, err := this.db.Query(`
+	SELECT id, list_id, kind, pattern, top
+	FROM watch_rules
+	WHERE list_id = ?
+	ORDER BY id
+	`, listId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return this.scanRules(rows)
+}
+
+func (this *WatchlistRepo) GetRule(listId int64, ruleId int64) (WatchRule, error) {
+	rules, err := this.Rules(listId)
+	if err != nil {
+		return WatchRule{}, err
+	}
+
+	for _, rule := range rules {
+		if rule.ID == ruleId {
+			return rule, nil
+		}
+	}
+
+	return WatchRule{}, ERR_WATCH_RULE_NOT_FOUND
+}
+
+func (this *WatchlistRepo) AddRule(rule WatchRule) (WatchRule, error) {
+	rule.Pattern = strings.TrimFunc(rule.Pattern, unicode.IsSpace)
+
+	err := rule.validate()
+	if err != nil {
+		return WatchRule{}, err
+	}
+
+	rules, err := this.Rules(rule.ListId)
+	if err != nil {
+		return WatchRule{}, err
+	}
+	if len(rules) >= MAX_WATCH_RULES_PER_LIST {
+		return WatchRule{}, ERR_TOO_MANY_WATCH_RULES
+	}
+
+	res, err := this.db.Exec(
+		`INSERT INTO watch_rules (list_id, kind, pattern, top) VALUES (?, ?, ?, ?)`,
+		rule.ListId, rule.Kind, rule.Pattern, rule.Top,
+	)
+	if err != nil {
+		return WatchRule{}, err
+	}
+
+	rule.ID, err = res.LastInsertId()
+	if err != nil {
+		return WatchRule{}, err
+	}
+
+	return rule, nil
+}
+
+func (this *WatchlistRepo) RemoveRule(listId int64, ruleId int64) error {
+	_, err := this.db.Exec(
+		`DELETE FROM watch_rules WHERE id = ? AND list_id = ?`, ruleId, listId,
+	)
+	return err
+}
+
+// GetInterestedByRules lists the chats having a rule matching the player in
+// a watchlist with notify on.
+func (this *WatchlistRepo) GetInterestedByRules(p core.Player) ([]int64, error) {
+	rows, err := this.db.Query(`
+	SELECT r.id, r.list_id, r.kind, r.pattern, r.top, l.chat_id
+	FROM watch_rules as r
+	INNER JOIN watchlists as l ON l.id = r.list_id
+	WHERE l.notify
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	chatIds := make([]int64, 0)
+	seen := map[int64]bool{}
+
+	for rows.Next() {
+		var rule WatchRule
+		var chatId int64
+		err = rows.Scan(
+			&rule.ID, &rule.ListId, &rule.Kind, &rule.Pattern, &rule.Top, &chatId,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if seen[chatId] || !rule.Matches(p) {
+			continue
+		}
+
+		seen[chatId] = true
+		chatIds = append(chatIds, chatId)
+	}
+
+	return chatIds, rows.Err()
+}
+
+// GetInterestedIn lists the chats watching the player, whether it's in their
+// watchlists or matched by their rules.
+func (this *WatchlistRepo) GetInterestedIn(p core.DbPlayer) ([]int64, error) {
+	chatIds, err := this.GetInterested(p.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	byRules, err := this.GetInterestedByRules(p.Player)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[int64]bool, len(chatIds))
+	for _, chatId := range chatIds {
+		seen[chatId] = true
+	}
+
+	for _, chatId := range byRules {
+		if !seen[chatId] {
+			chatIds = append(chatIds, chatId)
+		}
+	}
+
+	return chatIds, nil
+}
+
+func (this *WatchlistRepo) scanRules(rows *sql.Rows) ([]WatchRule, error) {
+	rules := make([]WatchRule, 0)
+
+	for rows.Next() {
+		var rule WatchRule
+		err := rows.Scan(
+			&rule.ID, &rule.ListId, &rule.Kind, &rule.Pattern, &rule.Top,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return rules, rows.Err()
+}
+
+func createWatchRulesTable(db *sql.DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS watch_rules (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		list_id INTEGER NOT NULL,
+		kind TEXT NOT NULL,
+		pattern TEXT NOT NULL DEFAULT '',
+		top INTEGER NOT NULL DEFAULT 0,
+		FOREIGN KEY (list_id) REFERENCES watchlists(id) ON DELETE CASCADE
+	);`)
+	return err
+}
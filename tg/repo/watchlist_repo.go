@@ -2,20 +2,60 @@ package repo
 
 import (
 	"database/sql"
+	"errors"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/thekhanj/csdmpro/core"
 )
 
+// Chats start with, and fall back to, a single list of this name.
+const DEFAULT_WATCHLIST_NAME = "main"
+
+const (
+	MAX_WATCHLISTS_PER_CHAT = 10
+	MAX_WATCHLIST_NAME_LEN  = 32
+)
+
+var (
+	ERR_TOO_MANY_WATCHLISTS    error = core.NewUserError("errors.too_many_watchlists")
+	ERR_INVALID_WATCHLIST_NAME error = core.NewUserError("errors.invalid_watchlist_name")
+	ERR_DUPLICATE_WATCHLIST    error = core.NewUserError("errors.duplicate_watchlist")
+	ERR_LAST_WATCHLIST         error = core.NewUserError("errors.last_watchlist")
+	ERR_WATCHLIST_NOT_FOUND    error = errors.New("watchlist not found")
+)
+
+// Watchlist is one of the chat's named lists. The selected one is what the
+// watchlist pages show and edit, notifications are sent for the players of
+// all the lists with notify on.
+type Watchlist struct {
+	ID       int64
+	ChatId   int64
+	Name     string
+	Notify   bool
+	Selected bool
+}
+
 type WatchlistRepo struct {
 	db *sql.DB
 }
 
+// List lists the players of the chat's selected watchlist.
 func (this *WatchlistRepo) List(chatId int64) ([]core.PlayerId, error) {
+	list, err := this.Selected(chatId)
+	if err != nil {
+		return nil, err
+	}
+
+	return this.ListPlayers(list.ID)
+}
+
+func (this *WatchlistRepo) ListPlayers(listId int64) ([]core.PlayerId, error) {
 	rows, err := this.db.Query(`
 	SELECT w.player_id
 	FROM watchlist as w
-	WHERE w.chat_id = ?
-	`, chatId)
+	WHERE w.list_id = ?
+	`, listId)
 	if err != nil {
 		return nil, err
 	}
@@ -53,13 +93,16 @@ func (this *WatchlistRepo) IsInWatchlist(
 	return false, nil
 }
 
+// GetInterested lists the chats having the player in a watchlist with notify
+// on.
 func (this *WatchlistRepo) GetInterested(
 	playerId core.PlayerId,
 ) ([]int64, error) {
 	rows, err := this.db.Query(`
-	SELECT w.chat_id
+	SELECT DISTINCT l.chat_id
 	FROM watchlist as w
-	WHERE w.player_id = ?
+	INNER JOIN watchlists as l ON l.id = w.list_id
+	WHERE w.player_id = ? AND l.notify
 	`, playerId)
 	if err != nil {
 		return nil, err
@@ -84,28 +127,368 @@ func (this *WatchlistRepo) GetInterested(
 func (this *WatchlistRepo) Add(
 	chatId int64, playerId core.PlayerId,
 ) error {
-	insertSQL := `INSERT INTO watchlist (chat_id, player_id) VALUES (?, ?)`
-	_, err := this.db.Exec(insertSQL, chatId, playerId)
+	list, err := this.Selected(chatId)
+	if err != nil {
+		return err
+	}
+
+	return this.AddToList(list.ID, playerId)
+}
+
+func (this *WatchlistRepo) AddToList(
+	listId int64, playerId core.PlayerId,
+) error {
+	insertSQL := `INSERT INTO watchlist (list_id, player_id) VALUES (?, ?)`
+	_, err := this.db.Exec(insertSQL, listId, playerId)
 	return err
 }
 
 func (this *WatchlistRepo) Remove(
 	chatId int64, playerId core.PlayerId,
 ) error {
-	insertSQL := `DELETE FROM watchlist WHERE chat_id = ? AND player_id = ?`
-	_, err := this.db.Exec(insertSQL, chatId, playerId)
+	list, err := this.Selected(chatId)
+	if err != nil {
+		return err
+	}
+
+	insertSQL := `DELETE FROM watchlist WHERE list_id = ? AND player_id = ?`
+	_, err = this.db.Exec(insertSQL, list.ID, playerId)
+	return err
+}
+
+// Lists lists the chat's watchlists, creating the default one if the chat
+// has none yet.
+func (this *WatchlistRepo) Lists(chatId int64) ([]Watchlist, error) {
+	_, err := this.Selected(chatId)
+	if err != nil {
+		return nil, err
+	}
+
+	return this.lists(chatId)
+}
+
+// Selected returns the chat's selected watchlist, creating the default one
+// if the chat has none yet.
+func (this *WatchlistRepo) Selected(chatId int64) (Watchlist, error) {
+	lists, err := this.lists(chatId)
+	if err != nil {
+		return Watchlist{}, err
+	}
+
+	if len(lists) == 0 {
+		return this.insertList(chatId, DEFAULT_WATCHLIST_NAME, true)
+	}
+
+	for _, list := range lists {
+		if list.Selected {
+			return list, nil
+		}
+	}
+
+	err = this.Select(chatId, lists[0].ID)
+	if err != nil {
+		return Watchlist{}, err
+	}
+	lists[0].Selected = true
+
+	return lists[0], nil
+}
+
+// CreateList adds a watchlist to the chat and selects it.
+func (this *WatchlistRepo) CreateList(
+	chatId int64, name string,
+) (Watchlist, error) {
+	name, err := this.validateName(chatId, name, 0)
+	if err != nil {
+		return Watchlist{}, err
+	}
+
+	lists, err := this.Lists(chatId)
+	if err != nil {
+		return Watchlist{}, err
+	}
+	if len(lists) >= MAX_WATCHLISTS_PER_CHAT {
+		return Watchlist{}, ERR_TOO_MANY_WATCHLISTS
+	}
+
+	list, err := this.insertList(chatId, name, false)
+	if err != nil {
+		return Watchlist{}, err
+	}
+
+	err = this.Select(chatId, list.ID)
+	if err != nil {
+		return Watchlist{}, err
+	}
+	list.Selected = true
+
+	return list, nil
+}
+
+func (this *WatchlistRepo) GetList(chatId int64, listId int64) (Watchlist, error) {
+	lists, err := this.lists(chatId)
+	if err != nil {
+		return Watchlist{}, err
+	}
+
+	for _, list := range lists {
+		if list.ID == listId {
+			return list, nil
+		}
+	}
+
+	return Watchlist{}, ERR_WATCHLIST_NOT_FOUND
+}
+
+func (this *WatchlistRepo) RenameList(
+	chatId int64, listId int64, name string,
+) error {
+	_, err := this.GetList(chatId, listId)
+	if err != nil {
+		return err
+	}
+
+	name, err = this.validateName(chatId, name, listId)
+	if err != nil {
+		return err
+	}
+
+	_, err = this.db.Exec(
+		`UPDATE watchlists SET name = ? WHERE id = ? AND chat_id = ?`,
+		name, listId, chatId,
+	)
 	return err
 }
 
+// DeleteList deletes the watchlist along with its players and rules, the
+// chat must be left with at least one list.
+func (this *WatchlistRepo) DeleteList(chatId int64, listId int64) error {
+	lists, err := this.lists(chatId)
+	if err != nil {
+		return err
+	}
+	if len(lists) <= 1 {
+		return ERR_LAST_WATCHLIST
+	}
+
+	_, err = this.GetList(chatId, listId)
+	if err != nil {
+		return err
+	}
+
+	_, err = this.db.Exec(`DELETE FROM watchlist WHERE list_id = ?`, listId)
+	if err != nil {
+		return err
+	}
+
+	_, err = this.db.Exec(`DELETE FROM watch_rules WHERE list_id = ?`, listId)
+	if err != nil {
+		return err
+	}
+
+	_, err = this.db.Exec(
+		`DELETE FROM watchlists WHERE id = ? AND chat_id = ?`, listId, chatId,
+	)
+	return err
+}
+
+func (this *WatchlistRepo) Select(chatId int64, listId int64) error {
+	_, err := this.GetList(chatId, listId)
+	if err != nil {
+		return err
+	}
+
+	_, err = this.db.Exec(
+		`UPDATE watchlists SET selected = (id = ?) WHERE chat_id = ?`,
+		listId, chatId,
+	)
+	return err
+}
+
+func (this *WatchlistRepo) SetNotify(
+	chatId int64, listId int64, notify bool,
+) error {
+	_, err := this.GetList(chatId, listId)
+	if err != nil {
+		return err
+	}
+
+	_, err = this.db.Exec(
+		`UPDATE watchlists SET notify = ? WHERE id = ? AND chat_id = ?`,
+		notify, listId, chatId,
+	)
+	return err
+}
+
+func (this *WatchlistRepo) lists(chatId int64) ([]Watchlist, error) {
+	rows, err := this.db.Query(`
+	SELECT id, chat_id, name, notify, selected
+	FROM watchlists
+	WHERE chat_id = ?
+	ORDER BY id ASC
+	`, chatId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lists := make([]Watchlist, 0)
+
+	for rows.Next() {
+		var l Watchlist
+
+		err = rows.Scan(&l.ID, &l.ChatId, &l.Name, &l.Notify, &l.Selected)
+		if err != nil {
+			return nil, err
+		}
+
+		lists = append(lists, l)
+	}
+
+	return lists, nil
+}
+
+func (this *WatchlistRepo) insertList(
+	chatId int64, name string, selected bool,
+) (Watchlist, error) {
+	res, err := this.db.Exec(`
+	INSERT INTO watchlists (chat_id, name, notify, selected)
+	VALUES (?, ?, TRUE, ?)
+	`, chatId, name, selected)
+	if err != nil {
+		return Watchlist{}, err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return Watchlist{}, err
+	}
+
+	return Watchlist{
+		ID:       id,
+		ChatId:   chatId,
+		Name:     name,
+		Notify:   true,
+		Selected: selected,
+	}, nil
+}
+
+// validateName trims the name and makes sure no other list of the chat, but
+// the one being renamed, goes by it.
+func (this *WatchlistRepo) validateName(
+	chatId int64, name string, renamedId int64,
+) (string, error) {
+	name = strings.TrimSpace(name)
+
+	length := utf8.RuneCountInString(name)
+	if length == 0 || length > MAX_WATCHLIST_NAME_LEN {
+		return "", ERR_INVALID_WATCHLIST_NAME
+	}
+
+	lists, err := this.lists(chatId)
+	if err != nil {
+		return "", err
+	}
+
+	for _, list := range lists {
+		if list.ID != renamedId && strings.EqualFold(list.Name, name) {
+			return "", ERR_DUPLICATE_WATCHLIST
+		}
+	}
+
+	return name, nil
+}
+
+// migrateLists moves the watchlists of the older versions, one flat list per
+// chat, into a default named list of each chat.
+func migrateLists(db *sql.DB) error {
+	isMigrated, err := hasColumn(db, "watchlist", "list_id")
+	if err != nil || isMigrated {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+	INSERT INTO watchlists (chat_id, name, notify, selected)
+	SELECT DISTINCT chat_id, ?, TRUE, TRUE FROM watchlist
+	`, DEFAULT_WATCHLIST_NAME)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`CREATE TABLE watchlist_new (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		list_id INTEGER NOT NULL,
+		player_id INTEGER NOT NULL,
+		FOREIGN KEY (list_id) REFERENCES watchlists(id) ON DELETE CASCADE,
+		FOREIGN KEY (player_id) REFERENCES players(id) ON DELETE RESTRICT,
+		UNIQUE(list_id, player_id)
+	);`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+	INSERT INTO watchlist_new (list_id, player_id)
+	SELECT l.id, w.player_id
+	FROM watchlist AS w
+	INNER JOIN watchlists AS l ON l.chat_id = w.chat_id
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`DROP TABLE watchlist`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`ALTER TABLE watchlist_new RENAME TO watchlist`)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
 func CreateWatchlistRepo(db *sql.DB) (*WatchlistRepo, error) {
+	createWatchlistsTable := `CREATE TABLE IF NOT EXISTS watchlists (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		chat_id INTEGER NOT NULL,
+		name TEXT NOT NULL,
+		notify BOOLEAN NOT NULL DEFAULT TRUE,
+		selected BOOLEAN NOT NULL DEFAULT FALSE,
+		UNIQUE(chat_id, name)
+	);`
+	_, err := db.Exec(createWatchlistsTable)
+	if err != nil {
+		return nil, err
+	}
+
 	createPlayersOnlineTable := `CREATE TABLE IF NOT EXISTS watchlist (
 		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		chat_id INTEGER,
-		player_id INTEGER,
+		list_id INTEGER NOT NULL,
+		player_id INTEGER NOT NULL,
+		FOREIGN KEY (list_id) REFERENCES watchlists(id) ON DELETE CASCADE,
 		FOREIGN KEY (player_id) REFERENCES players(id) ON DELETE RESTRICT,
-		UNIQUE(chat_id, player_id)
+		UNIQUE(list_id, player_id)
 	);`
-	_, err := db.Exec(createPlayersOnlineTable)
+	_, err = db.Exec(createPlayersOnlineTable)
+	if err != nil {
+		return nil, err
+	}
+
+	err = createWatchRulesTable(db)
+	if err != nil {
+		return nil, err
+	}
+
+	err = migrateLists(db)
 	if err != nil {
 		return nil, err
 	}
@@ -95,3 +95,285 @@ func TestWatchlistRepoSimply(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestWatchlistRepoLists(t *testing.T) {
+	f := db.FakeDbFactory{}
+	db, err := f.Init()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Deinit()
+
+	err = addCoupleOfPlayers(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repo, err := CreateWatchlistRepo(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var chatId int64 = 1
+	err = repo.Add(chatId, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	main, err := repo.Selected(chatId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if main.Name != DEFAULT_WATCHLIST_NAME {
+		t.Fatalf("expected the default list to be selected, got %s", main.Name)
+	}
+
+	rivals, err := repo.CreateList(chatId, " rivals ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rivals.Name != "rivals" || !rivals.Selected {
+		t.Fatalf("expected new list to be trimmed and selected, got %+v", rivals)
+	}
+
+	_, err = repo.CreateList(chatId, "Rivals")
+	if err != ERR_DUPLICATE_WATCHLIST {
+		t.Fatalf("expected duplicate watchlist error, got %v", err)
+	}
+
+	err = repo.Add(chatId, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = repo.Add(chatId, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ids, err := repo.List(chatId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 2 {
+		t.Fatalf("expected 2 players on rivals, got %d", len(ids))
+	}
+
+	err = repo.SetNotify(chatId, rivals.ID, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chatIds, err := repo.GetInterested(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chatIds) != 0 {
+		t.Fatal("expected no one to be notified of players on muted lists")
+	}
+
+	chatIds, err = repo.GetInterested(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chatIds) != 1 || chatIds[0] != chatId {
+		t.Fatal("expected chat to be notified of player-1 once through main")
+	}
+
+	err = repo.RenameList(chatId, rivals.ID, "Enemies")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = repo.DeleteList(chatId, rivals.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = repo.DeleteList(chatId, main.ID)
+	if err != ERR_LAST_WATCHLIST {
+		t.Fatalf("expected last watchlist error, got %v", err)
+	}
+
+	selected, err := repo.Selected(chatId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if selected.ID != main.ID {
+		t.Fatal("expected main to be selected after deleting rivals")
+	}
+}
+
+func TestWatchlistRepoMigratesFlatWatchlist(t *testing.T) {
+	f := db.FakeDbFactory{}
+	db, err := f.Init()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Deinit()
+
+	err = addCoupleOfPlayers(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = db.Exec(`CREATE TABLE watchlist (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		chat_id INTEGER,
+		player_id INTEGER,
+		FOREIGN KEY (player_id) REFERENCES players(id) ON DELETE RESTRICT,
+		UNIQUE(chat_id, player_id)
+	);
+	INSERT INTO watchlist (chat_id, player_id) VALUES (1, 1), (1, 2), (2, 1);`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repo, err := CreateWatchlistRepo(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ids, err := repo.List(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 2 {
+		t.Fatalf("expected chat 1 to keep its 2 players, got %d", len(ids))
+	}
+
+	chatIds, err := repo.GetInterested(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chatIds) != 2 {
+		t.Fatalf("expected 2 chats interested in player-1, got %d", len(chatIds))
+	}
+
+	lists, err := repo.Lists(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lists) != 1 || lists[0].Name != DEFAULT_WATCHLIST_NAME {
+		t.Fatalf("expected chat 2 to have only the default list, got %+v", lists)
+	}
+}
+
+func TestMatchPattern(t *testing.T) {
+	cases := []struct {
+		pattern string
+		name    string
+		matches bool
+	}{
+		{"[TAG]*", "[tag]Khan", true},
+		{"[TAG]*", "Khan[TAG]", false},
+		{"*khan*", "TheKhanJ", true},
+		{"p?ayer", "player", true},
+		{"p?ayer", "pplayer", false},
+		{"*-1", "player-1", true},
+		{"*-1", "player-11", false},
+		{"a*b*c", "aXXbYYbc", true},
+	}
+
+	for _, c := range cases {
+		if MatchPattern(c.pattern, c.name) != c.matches {
+			t.Errorf(
+				"expected %q matching %q to be %t", c.pattern, c.name, c.matches,
+			)
+		}
+	}
+}
+
+func TestWatchlistRepoRules(t *testing.T) {
+	f := db.FakeDbFactory{}
+	db, err := f.Init()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Deinit()
+
+	err = addCoupleOfPlayers(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repo, err := CreateWatchlistRepo(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	main, err := repo.Selected(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = repo.AddRule(WatchRule{
+		ListId: main.ID, Kind: WatchRulePattern, Pattern: "**",
+	})
+	if err != ERR_INVALID_WATCH_PATTERN {
+		t.Fatalf("expected only wildcards to be rejected, got %v", err)
+	}
+
+	_, err = repo.AddRule(WatchRule{
+		ListId: main.ID, Kind: WatchRulePattern, Pattern: " player-1* ",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	top, err := repo.AddRule(WatchRule{ListId: main.ID, Kind: WatchRuleTop, Top: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = repo.Add(2, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rank := 3
+	p := core.DbPlayer{
+		ID:     5,
+		Player: core.Player{Name: "player-5", Rank: &rank},
+	}
+
+	chatIds, err := repo.GetInterestedIn(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chatIds) != 2 {
+		t.Fatalf("expected both chats to be interested, got %v", chatIds)
+	}
+
+	err = repo.RemoveRule(main.ID, top.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chatIds, err = repo.GetInterestedByRules(core.Player{Name: "Player-12"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chatIds) != 1 || chatIds[0] != 1 {
+		t.Fatalf("expected the pattern to match, got %v", chatIds)
+	}
+
+	chatIds, err = repo.GetInterestedByRules(p.Player)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chatIds) != 0 {
+		t.Fatalf("expected no rule to match once top is removed, got %v", chatIds)
+	}
+
+	err = repo.SetNotify(1, main.ID, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chatIds, err = repo.GetInterestedByRules(core.Player{Name: "player-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chatIds) != 0 {
+		t.Fatalf("expected muted lists' rules to be ignored, got %v", chatIds)
+	}
+}
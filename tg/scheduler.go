@@ -0,0 +1,86 @@
+package tg
+
+import (
+	"context"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/thekhanj/csdmpro/logging"
+	"github.com/thekhanj/csdmpro/tg/repo"
+	"github.com/thekhanj/csdmpro/tg/service"
+)
+
+var schedulerLog = logging.Component("scheduler")
+
+const SCHEDULER_INTERVAL = time.Minute
+
+// Scheduler sends the digests of the chats that opted in for them once
+// they're due.
+type Scheduler struct {
+	settingsRepo  *repo.SettingsRepo
+	digestService *service.DigestService
+	locales       *service.LocaleService
+	chats         *service.ChatService
+	bot           *tgbotapi.BotAPI
+}
+
+func (this *Scheduler) Start(ctx context.Context) {
+	schedulerLog.Info("started")
+	defer schedulerLog.Info("stopped")
+
+	ticker := time.NewTicker(SCHEDULER_INTERVAL)
+	defer ticker.Stop()
+
+	for {
+		this.sendDueDigests(time.Now())
+
+		select {
+		case <-ctx.Done():
+			schedulerLog.Info("stopping")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (this *Scheduler) sendDueDigests(now time.Time) {
+	subscribers, err := this.settingsRepo.DigestSubscribers()
+	if err != nil {
+		schedulerLog.Error("failed getting digest subscribers", "err", err)
+		return
+	}
+
+	for _, settings := range subscribers {
+		if !settings.IsDigestDue(now) {
+			continue
+		}
+
+		digest, err := this.digestService.Build(settings, now)
+		if err != nil {
+			schedulerLog.Error("failed building digest", "chat_id", settings.ChatId, "err", err)
+			continue
+		}
+
+		l, err := this.locales.ForChat(settings.ChatId)
+		if err != nil {
+			schedulerLog.Error("failed getting locale", "chat_id", settings.ChatId, "err", err)
+			continue
+		}
+
+		_, err = this.chats.Send(
+			this.bot,
+			settings.ChatId,
+			tgbotapi.NewMessage(settings.ChatId, digest.Text(l, settings.Location())),
+		)
+		if err != nil && !service.IsUnreachable(err) {
+			schedulerLog.Warn("failed sending digest", "chat_id", settings.ChatId, "err", err)
+			continue
+		}
+		// Unreachable chats are marked too, not to be retried every tick.
+
+		err = this.settingsRepo.MarkDigestSent(settings.ChatId, now)
+		if err != nil {
+			schedulerLog.Error("failed marking digest sent", "chat_id", settings.ChatId, "err", err)
+		}
+	}
+}
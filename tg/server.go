@@ -2,23 +2,34 @@ package tg
 
 import (
 	"context"
-	"log"
 	"os"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/thekhanj/tgool"
+
+	"github.com/thekhanj/csdmpro/logging"
 )
 
+var serverLog = logging.Component("server")
+
 type Server struct {
 	bot    *tgbotapi.BotAPI
 	router *tgool.Router
+	// webhook is nil when polling for updates.
+	webhook *WebhookConfig
 }
 
 func (this *Server) Listen(ctx context.Context) {
-	updateConfig := tgbotapi.NewUpdate(0)
-	updateConfig.Timeout = 60
-
-	updates := this.bot.GetUpdatesChan(updateConfig)
+	var updates tgbotapi.UpdatesChannel
+	if this.webhook != nil {
+		var err error
+		updates, err = this.listenWebhook(ctx)
+		if err != nil {
+			logging.Fatal(serverLog, "failed listening for webhook", "err", err)
+		}
+	} else {
+		updates = this.poll(ctx)
+	}
 
 	tgoolEngine := tgool.NewEngine(
 		this.router,
@@ -33,12 +44,33 @@ func (this *Server) Listen(ctx context.Context) {
 	}()
 
 	<-ctx.Done()
-	this.bot.StopReceivingUpdates()
 
 	if os.Getenv("ENV") == "dev" {
-		log.Println("tg: server forecfully stopped")
+		serverLog.Info("forcefully stopped")
 		return
 	}
 
 	<-tgDone
 }
+
+// poll polls telegram for updates until ctx is done. A webhook left from
+// running in webhook mode is removed first, telegram doesn't give updates
+// to polling while there's one.
+func (this *Server) poll(ctx context.Context) tgbotapi.UpdatesChannel {
+	_, err := this.bot.Request(tgbotapi.DeleteWebhookConfig{})
+	if err != nil {
+		serverLog.Warn("failed removing webhook", "err", err)
+	}
+
+	updateConfig := tgbotapi.NewUpdate(0)
+	updateConfig.Timeout = 60
+
+	updates := this.bot.GetUpdatesChan(updateConfig)
+
+	go func() {
+		<-ctx.Done()
+		this.bot.StopReceivingUpdates()
+	}()
+
+	return updates
+}
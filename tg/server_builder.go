@@ -7,6 +7,7 @@ import (
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/thekhanj/csdmpro/tg/middlewares"
 	"github.com/thekhanj/csdmpro/tg/repo"
+	"github.com/thekhanj/csdmpro/tg/service"
 	"github.com/thekhanj/tgool"
 	"golang.org/x/net/proxy"
 )
@@ -15,8 +16,15 @@ type ServerBuilder struct {
 	err         error
 	http_client *http.Client
 	token       string
+	endpoint    string
 	controllers []tgool.Controller
 	bilakhRepo  *repo.BilakhRepo
+	chatRepo    *repo.ChatRepo
+	locales     *service.LocaleService
+	admins      *service.AdminService
+	limiter     *service.RateLimiter
+	failureRepo *repo.FailureRepo
+	webhook     *WebhookConfig
 }
 
 // WithProxy uses a socks5 proxy for connecting to telegram'a api.
@@ -49,6 +57,16 @@ func (this *ServerBuilder) WithToken(token string) *ServerBuilder {
 	return this
 }
 
+// WithEndpoint talks to another bot api server than telegram's, a local one
+// or a fake one in tests.
+//
+//	Example: http://127.0.0.1:8081/bot%s/%s
+func (this *ServerBuilder) WithEndpoint(endpoint string) *ServerBuilder {
+	this.endpoint = endpoint
+
+	return this
+}
+
 func (this *ServerBuilder) WithControllers(controllers ...tgool.Controller) *ServerBuilder {
 	this.controllers = controllers
 	return this
@@ -59,6 +77,55 @@ func (this *ServerBuilder) WithBilakhRepo(repo *repo.BilakhRepo) *ServerBuilder
 	return this
 }
 
+func (this *ServerBuilder) WithChatRepo(repo *repo.ChatRepo) *ServerBuilder {
+	this.chatRepo = repo
+	return this
+}
+
+func (this *ServerBuilder) WithLocales(
+	locales *service.LocaleService,
+) *ServerBuilder {
+	this.locales = locales
+	return this
+}
+
+func (this *ServerBuilder) WithAdmins(
+	admins *service.AdminService,
+) *ServerBuilder {
+	this.admins = admins
+	return this
+}
+
+// WithRateLimiter limits how fast chats can go, it needs the bilakhs repo
+// and the locales too.
+func (this *ServerBuilder) WithRateLimiter(
+	limiter *service.RateLimiter,
+) *ServerBuilder {
+	this.limiter = limiter
+	return this
+}
+
+// WithFailureRepo logs the requests and records the failed ones, it needs
+// the locales too.
+func (this *ServerBuilder) WithFailureRepo(
+	repo *repo.FailureRepo,
+) *ServerBuilder {
+	this.failureRepo = repo
+	return this
+}
+
+// WithWebhook gets the updates through a webhook instead of polling.
+func (this *ServerBuilder) WithWebhook(config WebhookConfig) *ServerBuilder {
+	err := config.validate()
+	if err != nil {
+		this.err = err
+		return this
+	}
+
+	this.webhook = &config
+	return this
+}
+
 func (this *ServerBuilder) Build() (*Server, error) {
 	if this.err != nil {
 		return nil, this.err
@@ -68,25 +135,56 @@ func (this *ServerBuilder) Build() (*Server, error) {
 		this.http_client = &http.Client{}
 	}
 
+	if this.endpoint == "" {
+		this.endpoint = tgbotapi.APIEndpoint
+	}
+
 	bot, err := tgbotapi.NewBotAPIWithClient(
-		this.token, tgbotapi.APIEndpoint, this.http_client,
+		this.token, this.endpoint, this.http_client,
 	)
 	if err != nil {
 		return nil, err
 	}
 
-	ms := make([]tgool.Middleware, 0)
+	ms := []tgool.Middleware{
+		middlewares.NewCommandMiddleware(),
+		middlewares.NewInputMiddleware(),
+	}
+
+	if this.chatRepo != nil {
+		ms = append(ms, middlewares.NewChatMiddleware(this.chatRepo))
+	}
+
+	if this.admins != nil {
+		ms = append(ms, middlewares.NewAdminMiddleware(this.admins))
+	}
+
+	if this.limiter != nil && this.bilakhRepo != nil && this.locales != nil {
+		ms = append(ms, middlewares.NewRateLimitMiddleware(
+			this.limiter, this.bilakhRepo, this.locales, this.admins,
+		))
+	}
 
-	if this.bilakhRepo != nil {
-		ms = append(ms, middlewares.NewBilakhMiddleware(this.bilakhRepo))
+	if this.bilakhRepo != nil && this.locales != nil {
+		ms = append(ms, middlewares.NewBilakhMiddleware(this.bilakhRepo, this.locales))
 	}
 
 	if this.controllers != nil {
-		m := tgool.NewControllerMiddleware(this.controllers...)
-		ms = append(ms, m)
+		if this.failureRepo != nil && this.locales != nil {
+			m, err := middlewares.NewRequestMiddleware(
+				this.controllers, this.failureRepo, this.locales,
+			)
+			if err != nil {
+				return nil, err
+			}
+
+			ms = append(ms, m)
+		} else {
+			ms = append(ms, tgool.NewControllerMiddleware(this.controllers...))
+		}
 	}
 
 	router := tgool.NewRouter(ms...)
 
-	return &Server{bot, router}, nil
+	return &Server{bot, router, this.webhook}, nil
 }
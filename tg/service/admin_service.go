@@ -0,0 +1,41 @@
+package service
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// AdminService serves the bot's operator, whose chats are the admin chats.
+// Not to be confused with the admins of a group.
+type AdminService struct {
+	ChatIds []int64
+}
+
+// ParseAdminChatIds parses a comma separated list of chat ids.
+//
+//	Example: 12345,-100987654
+func ParseAdminChatIds(s string) ([]int64, error) {
+	chatIds := make([]int64, 0)
+
+	for _, field := range strings.Split(s, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+
+		chatId, err := strconv.ParseInt(field, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid admin chat id %q", field)
+		}
+
+		chatIds = append(chatIds, chatId)
+	}
+
+	return chatIds, nil
+}
+
+func (this *AdminService) IsAdminChat(chatId int64) bool {
+	return slices.Contains(this.ChatIds, chatId)
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/thekhanj/csdmpro/core"
+	"github.com/thekhanj/csdmpro/tg/format"
+	"github.com/thekhanj/csdmpro/tg/i18n"
+	"github.com/thekhanj/csdmpro/tg/repo"
+)
+
+type AlertService struct {
+	PlayerRepo *core.PlayerRepo
+	AlertRepo  *repo.AlertRepo
+}
+
+// Create adds the alert, marking it as already fired for the players who
+// meet it at the moment, alerts only fire on crossings.
+func (this *AlertService) Create(alert repo.Alert) (repo.Alert, error) {
+	alert, err := this.AlertRepo.Add(alert)
+	if err != nil {
+		return repo.Alert{}, err
+	}
+
+	if alert.PlayerId != nil {
+		p, err := this.PlayerRepo.GetPlayer(*alert.PlayerId)
+		if err != nil {
+			return repo.Alert{}, err
+		}
+
+		return alert, this.seed(alert, p)
+	}
+
+	for offset := 0; ; offset += 500 {
+		players, err := this.PlayerRepo.List(offset, 500)
+		if err != nil {
+			return repo.Alert{}, err
+		}
+		if len(players) == 0 {
+			return alert, nil
+		}
+
+		for _, p := range players {
+			err = this.seed(alert, p)
+			if err != nil {
+				return repo.Alert{}, err
+			}
+		}
+	}
+}
+
+func (this *AlertService) seed(alert repo.Alert, p core.DbPlayer) error {
+	value, ok := alert.Value(p.Player)
+	if !ok || !alert.IsMet(value) {
+		return nil
+	}
+
+	return this.AlertRepo.SetFired(alert.ID, p.ID, true)
+}
+
+type FiredAlert struct {
+	Alert repo.Alert
+	Value float64
+}
+
+// Check evaluates the alerts on the player's updated stats, returning the
+// ones that just fired.
+func (this *AlertService) Check(p core.DbPlayer) ([]FiredAlert, error) {
+	alerts, err := this.AlertRepo.ForPlayer(p.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]FiredAlert, 0)
+
+	for _, alert := range alerts {
+		value, ok := alert.Value(p.Player)
+		if !ok {
+			continue
+		}
+
+		wasFired, err := this.AlertRepo.IsFired(alert.ID, p.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		fired, notify := alert.Evaluate(value, wasFired)
+		if fired != wasFired {
+			err = this.AlertRepo.SetFired(alert.ID, p.ID, fired)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if notify {
+			ret = append(ret, FiredAlert{Alert: alert, Value: value})
+		}
+	}
+
+	return ret, nil
+}
+
+// ALERT_METRIC_NAMES are the keys of the alert metrics' names.
+var ALERT_METRIC_NAMES = map[repo.AlertMetric]string{
+	repo.AlertRank:     "alert_metric.rank",
+	repo.AlertScore:    "alert_metric.score",
+	repo.AlertKills:    "alert_metric.kills",
+	repo.AlertAccuracy: "alert_metric.accuracy",
+	repo.AlertKD:       "alert_metric.kd",
+}
+
+// Describe renders the alert's condition, e.g. "🇮🇷 foo: rank ≤ 10".
+func (this *AlertService) Describe(
+	l i18n.Localizer, alert repo.Alert,
+) (string, error) {
+	who := l.T("alerts.anyone")
+	if alert.PlayerId != nil {
+		p, err := this.PlayerRepo.GetPlayer(*alert.PlayerId)
+		if err != nil {
+			return "", err
+		}
+
+		who = fmt.Sprintf(
+			"%s %s",
+			core.CountryFlag(p.Player.Country), format.Name(p.Player.Name),
+		)
+	}
+
+	op := "≥"
+	if alert.Op == repo.AlertAtMost {
+		op = "≤"
+	}
+
+	return fmt.Sprintf(
+		"%s: %s %s %s",
+		who, l.T(ALERT_METRIC_NAMES[alert.Metric]), op,
+		FormatAlertValue(l, alert.Metric, alert.Threshold),
+	), nil
+}
+
+func FormatAlertValue(
+	l i18n.Localizer, metric repo.AlertMetric, value float64,
+) string {
+	switch metric {
+	case repo.AlertRank:
+		return l.Digits(fmt.Sprintf("#%d", int(value)))
+	case repo.AlertAccuracy:
+		return l.Digits(fmt.Sprintf("%d%%", int(value)))
+	case repo.AlertKD:
+		return l.Digits(fmt.Sprintf("%.2f", value))
+	default:
+		return l.Number(int(value))
+	}
+}
@@ -0,0 +1,229 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/thekhanj/csdmpro/core"
+	"github.com/thekhanj/csdmpro/logging"
+	"github.com/thekhanj/csdmpro/tg/repo"
+)
+
+var broadcastLog = logging.Component("broadcast")
+
+var ERR_BROADCAST_RUNNING error = core.NewUserError("errors.broadcast_running")
+var ERR_NO_BROADCAST_DRAFT error = core.NewUserError("errors.no_broadcast_draft")
+
+const (
+	// Gap between broadcasting to different chats, telegram allows around 30
+	// messages a second.
+	BROADCAST_GAP = 50 * time.Millisecond
+	// How often the progress of a broadcast is reported.
+	BROADCAST_PROGRESS_INTERVAL = 5 * time.Second
+	// Failures kept for the report, the rest are just counted.
+	MAX_BROADCAST_FAILURES = 20
+)
+
+// BroadcastDraft is a message of an admin chat waiting to be broadcast. The
+// message is copied as is, so it can be of any kind and keeps its
+// formatting.
+type BroadcastDraft struct {
+	FromChatId int64
+	MessageId  int
+	Audience   repo.Audience
+}
+
+type BroadcastFailure struct {
+	ChatId int64
+	Err    error
+}
+
+type BroadcastProgress struct {
+	Audience repo.Audience
+	Total    int
+	Sent     int
+	Failed   int
+	Failures []BroadcastFailure
+	Started  time.Time
+	// Done is set on the last report, Stopped too if it was stopped early.
+	Done    bool
+	Stopped bool
+}
+
+type broadcastJob struct {
+	stop context.CancelFunc
+	// done gets the final progress once the job ends.
+	done chan BroadcastProgress
+}
+
+// BroadcastService holds the admins' drafts and runs one broadcast at a
+// time in the background.
+type BroadcastService struct {
+	ChatRepo *repo.ChatRepo
+	Chats    *ChatService
+
+	mutex  sync.Mutex
+	drafts map[int64]BroadcastDraft
+	job    *broadcastJob
+}
+
+func (this *BroadcastService) SetDraft(chatId int64, draft BroadcastDraft) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	if this.drafts == nil {
+		this.drafts = make(map[int64]BroadcastDraft)
+	}
+	this.drafts[chatId] = draft
+}
+
+func (this *BroadcastService) Draft(chatId int64) (BroadcastDraft, error) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	draft, ok := this.drafts[chatId]
+	if !ok {
+		return BroadcastDraft{}, ERR_NO_BROADCAST_DRAFT
+	}
+
+	return draft, nil
+}
+
+func (this *BroadcastService) DiscardDraft(chatId int64) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	delete(this.drafts, chatId)
+}
+
+func (this *BroadcastService) IsRunning() bool {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	return this.job != nil
+}
+
+// Start broadcasts the chat's draft in the background, calling report with
+// the progress every once in a while and once it's done, unless it's
+// stopped.
+func (this *BroadcastService) Start(
+	bot *tgbotapi.BotAPI, chatId int64, report func(BroadcastProgress),
+) error {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	if this.job != nil {
+		return ERR_BROADCAST_RUNNING
+	}
+
+	draft, ok := this.drafts[chatId]
+	if !ok {
+		return ERR_NO_BROADCAST_DRAFT
+	}
+
+	chats, err := this.ChatRepo.Audience(draft.Audience, time.Now())
+	if err != nil {
+		return err
+	}
+
+	delete(this.drafts, chatId)
+
+	ctx, stop := context.WithCancel(context.Background())
+	job := &broadcastJob{stop, make(chan BroadcastProgress, 1)}
+	this.job = job
+
+	broadcastLog.Info(
+		"started", "chat_id", chatId, "audience", draft.Audience, "chats", len(chats),
+	)
+
+	go this.run(ctx, job, bot, draft, chats, report)
+
+	return nil
+}
+
+// Stop stops the running broadcast after the message being sent, returning
+// its final progress. It's false if there's no broadcast running.
+func (this *BroadcastService) Stop() (BroadcastProgress, bool) {
+	this.mutex.Lock()
+	job := this.job
+	this.mutex.Unlock()
+
+	if job == nil {
+		return BroadcastProgress{}, false
+	}
+
+	job.stop()
+
+	return <-job.done, true
+}
+
+func (this *BroadcastService) run(
+	ctx context.Context,
+	job *broadcastJob,
+	bot *tgbotapi.BotAPI,
+	draft BroadcastDraft,
+	chats []repo.Chat,
+	report func(BroadcastProgress),
+) {
+	p := BroadcastProgress{
+		Audience: draft.Audience,
+		Total:    len(chats),
+		Started:  time.Now(),
+	}
+	lastReport := p.Started
+
+	for i, chat := range chats {
+		if ctx.Err() != nil {
+			p.Stopped = true
+			break
+		}
+		if i != 0 {
+			time.Sleep(BROADCAST_GAP)
+		}
+
+		_, err := this.Chats.Send(
+			bot,
+			chat.ID,
+			tgbotapi.NewCopyMessage(chat.ID, draft.FromChatId, draft.MessageId),
+		)
+		if err != nil {
+			p.Failed++
+			if len(p.Failures) < MAX_BROADCAST_FAILURES {
+				p.Failures = append(p.Failures, BroadcastFailure{chat.ID, err})
+			}
+		} else {
+			p.Sent++
+		}
+
+		if time.Since(lastReport) >= BROADCAST_PROGRESS_INTERVAL {
+			report(p.snapshot())
+			lastReport = time.Now()
+		}
+	}
+
+	this.mutex.Lock()
+	job.stop()
+	this.job = nil
+	this.mutex.Unlock()
+
+	broadcastLog.Info(
+		"finished",
+		"sent", p.Sent, "failed", p.Failed, "total", p.Total, "stopped", p.Stopped,
+	)
+
+	p.Done = true
+	// a stopped job's progress is reported by whoever stopped it
+	if !p.Stopped {
+		report(p)
+	}
+	job.done <- p
+}
+
+// snapshot copies the progress so the broadcast can go on while it's being
+// reported.
+func (this BroadcastProgress) snapshot() BroadcastProgress {
+	this.Failures = append([]BroadcastFailure(nil), this.Failures...)
+	return this
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/thekhanj/csdmpro/logging"
+	"github.com/thekhanj/csdmpro/tg/repo"
+)
+
+var chatsLog = logging.Component("chats")
+
+type ChatService struct {
+	ChatRepo *repo.ChatRepo
+}
+
+// Send sends to the chat like bot.Send does, marking the chat blocked when
+// telegram says it can't be reached anymore.
+func (this *ChatService) Send(
+	bot *tgbotapi.BotAPI, chatId int64, c tgbotapi.Chattable,
+) (tgbotapi.Message, error) {
+	msg, err := bot.Send(c)
+	if err != nil && IsUnreachable(err) {
+		chatsLog.Info("chat is unreachable", "chat_id", chatId, "err", err)
+
+		blockErr := this.ChatRepo.SetBlocked(chatId, true)
+		if blockErr != nil {
+			chatsLog.Error("failed marking chat blocked", "chat_id", chatId, "err", blockErr)
+		}
+	}
+
+	return msg, err
+}
+
+// IsUnreachable tells whether the error is for the chat not being reachable
+// anymore, e.g. the bot being blocked or kicked out.
+func IsUnreachable(err error) bool {
+	var tgErr *tgbotapi.Error
+	if !errors.As(err, &tgErr) {
+		return false
+	}
+
+	return tgErr.Code == http.StatusForbidden ||
+		strings.Contains(tgErr.Message, "chat not found")
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/thekhanj/csdmpro/core"
+	"github.com/thekhanj/csdmpro/tg/format"
+	"github.com/thekhanj/csdmpro/tg/i18n"
+	"github.com/thekhanj/csdmpro/tg/repo"
+)
+
+// Players newly entering this top are mentioned in the digests.
+const DIGEST_TOP_N = 10
+
+type DigestService struct {
+	PlayerRepo    *core.PlayerRepo
+	WatchlistRepo *repo.WatchlistRepo
+}
+
+type DigestEntry struct {
+	DbPlayer   core.DbPlayer
+	Playtime   time.Duration
+	RankChange int
+	ScoreGain  int
+	KillsGain  int
+}
+
+type Digest struct {
+	Frequency   repo.DigestFrequency
+	Since       time.Time
+	Until       time.Time
+	Entries     []DigestEntry
+	TopEntrants []core.DbPlayer
+}
+
+func (this *DigestService) Build(
+	settings repo.ChatSettings, now time.Time,
+) (Digest, error) {
+	since := settings.DigestPeriodStart(now)
+
+	d := Digest{
+		Frequency: settings.Digest,
+		Since:     since,
+		Until:     now,
+		Entries:   []DigestEntry{},
+	}
+
+	ids, err := this.WatchlistRepo.List(settings.ChatId)
+	if err != nil {
+		return Digest{}, err
+	}
+
+	for _, id := range ids {
+		e, err := this.buildEntry(id, since)
+		if err != nil {
+			return Digest{}, err
+		}
+
+		d.Entries = append(d.Entries, e)
+	}
+
+	d.TopEntrants, err = this.PlayerRepo.TopEntrants(DIGEST_TOP_N, since)
+	if err != nil {
+		return Digest{}, err
+	}
+
+	return d, nil
+}
+
+func (this *DigestService) buildEntry(
+	id core.PlayerId, since time.Time,
+) (DigestEntry, error) {
+	p, err := this.PlayerRepo.GetPlayer(id)
+	if err != nil {
+		return DigestEntry{}, err
+	}
+
+	playtime, err := this.PlayerRepo.Playtime(id, since)
+	if err != nil {
+		return DigestEntry{}, err
+	}
+
+	history, err := this.PlayerRepo.StatsHistory(id, since)
+	if err != nil {
+		return DigestEntry{}, err
+	}
+
+	e := DigestEntry{DbPlayer: p, Playtime: playtime}
+
+	if len(history) != 0 {
+		first := history[0].Player
+
+		e.ScoreGain = p.Player.Score - first.Score
+		e.KillsGain = p.Player.Kills - first.Kills
+		if first.Rank != nil && p.Player.Rank != nil {
+			e.RankChange = *first.Rank - *p.Player.Rank
+		}
+	}
+
+	return e, nil
+}
+
+func (this *Digest) Text(l i18n.Localizer, loc *time.Location) string {
+	var txt string
+	if this.Frequency == repo.DigestWeekly {
+		txt = l.T(
+			"digest.weekly_title",
+			l.Day(this.Since.In(loc)), l.Day(this.Until.In(loc)),
+		)
+	} else {
+		txt = l.T("digest.daily_title", l.Day(this.Until.In(loc)))
+	}
+	txt += "\n\n"
+
+	if len(this.Entries) == 0 {
+		txt += l.T("digest.empty") + "\n"
+	} else {
+		txt += l.T("digest.watchlist") + "\n"
+	}
+
+	for _, e := range this.Entries {
+		p := e.DbPlayer.Player
+
+		rank := l.T("digest.unranked")
+		if p.Rank != nil {
+			rank = l.Digits(fmt.Sprintf("#%d", *p.Rank))
+		}
+		if e.RankChange > 0 {
+			rank += l.Digits(fmt.Sprintf(" (▲%d)", e.RankChange))
+		} else if e.RankChange < 0 {
+			rank += l.Digits(fmt.Sprintf(" (▼%d)", -e.RankChange))
+		}
+
+		txt += l.T(
+			"digest.entry",
+			core.CountryFlag(p.Country), format.Name(p.Name),
+			l.Duration(e.Playtime), rank, e.ScoreGain, e.KillsGain,
+		) + "\n"
+	}
+
+	if len(this.TopEntrants) != 0 {
+		txt += "\n" + l.T("digest.top_entrants", DIGEST_TOP_N) + "\n"
+
+		for _, p := range this.TopEntrants {
+			txt += fmt.Sprintf(
+				"%s %s %s\n",
+				l.Digits(fmt.Sprintf("#%d", *p.Player.Rank)),
+				core.CountryFlag(p.Player.Country), format.Name(p.Player.Name),
+			)
+		}
+	}
+
+	return txt
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/thekhanj/csdmpro/core"
+	"github.com/thekhanj/csdmpro/tg/format"
+	"github.com/thekhanj/csdmpro/tg/i18n"
+)
+
+// Players listed on a live board, the rest are just counted so the message
+// stays within telegram's length limit.
+const LIVE_BOARD_MAX_PLAYERS = 50
+
+type LiveBoardService struct {
+	PlayerRepo *core.PlayerRepo
+}
+
+// Render renders the live board's text out of the current online sessions.
+func (this *LiveBoardService) Render(
+	now time.Time, l i18n.Localizer,
+) (string, error) {
+	sessions, err := this.PlayerRepo.OnlineSessions()
+	if err != nil {
+		return "", err
+	}
+
+	txt := l.N("live_board.title", len(sessions)) + "\n\n"
+
+	if len(sessions) == 0 {
+		txt += l.T("onlines.empty")
+	}
+
+	for i, s := range sessions {
+		if i == LIVE_BOARD_MAX_PLAYERS {
+			txt += l.N("common.and_more", len(sessions)-i) + "\n"
+			break
+		}
+
+		rank := "-"
+		if s.DbPlayer.Player.Rank != nil {
+			rank = "#" + l.Number(*s.DbPlayer.Player.Rank)
+		}
+
+		txt += fmt.Sprintf(
+			"🟢 %s %s %s · %s\n",
+			rank,
+			core.CountryFlag(s.DbPlayer.Player.Country),
+			format.Name(s.DbPlayer.Player.Name),
+			l.Duration(now.Sub(s.Since)),
+		)
+	}
+
+	return txt, nil
+}
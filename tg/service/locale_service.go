@@ -0,0 +1,54 @@
+package service
+
+import (
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/thekhanj/csdmpro/tg/i18n"
+	"github.com/thekhanj/csdmpro/tg/repo"
+)
+
+type LocaleService struct {
+	SettingsRepo *repo.SettingsRepo
+}
+
+// ForChat localizes into the chat's language, chats that haven't got one
+// yet get the default.
+func (this *LocaleService) ForChat(chatId int64) (i18n.Localizer, error) {
+	settings, err := this.SettingsRepo.Get(chatId)
+	if err != nil {
+		return i18n.Localizer{}, err
+	}
+
+	lang, _ := i18n.ParseLang(settings.Language)
+
+	return i18n.New(lang), nil
+}
+
+// ForUser localizes into the chat's language. Chats that haven't got one yet
+// get the language of the user's telegram, which is remembered for the chat
+// so notifications are sent in it too.
+func (this *LocaleService) ForUser(
+	chatId int64, from *tgbotapi.User,
+) (i18n.Localizer, error) {
+	settings, err := this.SettingsRepo.Get(chatId)
+	if err != nil {
+		return i18n.Localizer{}, err
+	}
+
+	lang, ok := i18n.ParseLang(settings.Language)
+	if ok || from == nil || from.LanguageCode == "" {
+		return i18n.New(lang), nil
+	}
+
+	lang = i18n.FromLanguageCode(from.LanguageCode)
+
+	err = this.SettingsRepo.SetLanguage(chatId, string(lang))
+	if err != nil {
+		return i18n.Localizer{}, err
+	}
+
+	return i18n.New(lang), nil
+}
+
+func (this *LocaleService) SetLanguage(chatId int64, lang i18n.Lang) error {
+	return this.SettingsRepo.SetLanguage(chatId, string(lang))
+}
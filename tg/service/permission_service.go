@@ -0,0 +1,64 @@
+package service
+
+import (
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/thekhanj/csdmpro/core"
+	"github.com/thekhanj/csdmpro/tg/repo"
+)
+
+var ERR_ADMINS_ONLY error = core.NewUserError("errors.admins_only")
+
+type PermissionService struct {
+	SettingsRepo *repo.SettingsRepo
+}
+
+// IsAdmin tells whether the message, or the button press on it, comes from
+// an admin of the chat. Everyone is the admin of their private chat.
+func (this *PermissionService) IsAdmin(
+	bot *tgbotapi.BotAPI, msg *tgbotapi.Message, from *tgbotapi.User,
+) (bool, error) {
+	if msg.Chat.IsPrivate() {
+		return true, nil
+	}
+
+	// admins posting anonymously send messages on behalf of the group itself
+	if msg.SenderChat != nil && msg.SenderChat.ID == msg.Chat.ID {
+		return true, nil
+	}
+
+	if from == nil {
+		return false, nil
+	}
+
+	member, err := bot.GetChatMember(tgbotapi.GetChatMemberConfig{
+		ChatConfigWithUser: tgbotapi.ChatConfigWithUser{
+			ChatID: msg.Chat.ID,
+			UserID: from.ID,
+		},
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return member.IsCreator() || member.IsAdministrator(), nil
+}
+
+// CanEdit tells whether the user may edit the chat's shared watchlist, that
+// is an admin, or anyone if the admins allowed members to.
+func (this *PermissionService) CanEdit(
+	bot *tgbotapi.BotAPI, msg *tgbotapi.Message, from *tgbotapi.User,
+) (bool, error) {
+	if msg.Chat.IsPrivate() {
+		return true, nil
+	}
+
+	settings, err := this.SettingsRepo.Get(msg.Chat.ID)
+	if err != nil {
+		return false, err
+	}
+	if settings.MembersCanEdit {
+		return true, nil
+	}
+
+	return this.IsAdmin(bot, msg, from)
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// Chats that went quiet for this long are forgotten, along with their
+	// strikes and escalations.
+	RATE_LIMIT_IDLE_PERIOD = time.Hour
+)
+
+// RateLimit is a token bucket holding up to Burst tokens, refilled with
+// Rate tokens a second. Every update takes a token.
+type RateLimit struct {
+	Burst float64
+	Rate  float64
+}
+
+type bucket struct {
+	tokens float64
+	at     time.Time
+}
+
+func (this *bucket) take(limit RateLimit, now time.Time) bool {
+	if this.at.IsZero() {
+		this.tokens = limit.Burst
+	} else {
+		elapsed := now.Sub(this.at).Seconds()
+		this.tokens = min(limit.Burst, this.tokens+elapsed*limit.Rate)
+	}
+	this.at = now
+
+	if this.tokens < 1 {
+		return false
+	}
+
+	this.tokens--
+	return true
+}
+
+type chatLimits struct {
+	chat        bucket
+	sections    map[string]*bucket
+	strikes     []time.Time
+	escalations int
+	warned      bool
+	lastSeen    time.Time
+}
+
+// RateLimitResult tells what to do with an update. Warn is set on the
+// first update turned down after one that went through, so the chat is told
+// to slow down once instead of on every press. Bilakh is set when the chat
+// kept going and has to be bilakhed for that long.
+type RateLimitResult struct {
+	Allowed bool
+	Warn    bool
+	Bilakh  time.Duration
+}
+
+// RateLimiter limits the updates of each chat, overall and on every section
+// of the bot separately so that hammering one button doesn't use up the whole
+// chat's share. Sections are the first segment of the routes, so routes
+// differing only in their params can't be used to get around the limit. Chats turned down Strikes times within StrikePeriod get a
+// temporary bilakh, starting at BilakhFor and doubling each time up to
+// MaxBilakhFor.
+type RateLimiter struct {
+	Chat         RateLimit
+	Route        RateLimit
+	Strikes      int
+	StrikePeriod time.Duration
+	BilakhFor    time.Duration
+	MaxBilakhFor time.Duration
+
+	mutex  sync.Mutex
+	chats  map[int64]*chatLimits
+	pruned time.Time
+}
+
+func (this *RateLimiter) Allow(
+	chatId int64, route string, now time.Time,
+) RateLimitResult {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	this.prune(now)
+
+	c := this.limits(chatId)
+	c.lastSeen = now
+
+	section := routeSection(route)
+	r, ok := c.sections[section]
+	if !ok {
+		r = &bucket{}
+		c.sections[section] = r
+	}
+
+	// a turned down route doesn't eat the chat's share too
+	if r.take(this.Route, now) && c.chat.take(this.Chat, now) {
+		c.warned = false
+		return RateLimitResult{Allowed: true}
+	}
+
+	res := RateLimitResult{Warn: !c.warned}
+	c.warned = true
+
+	strikes := c.strikes[:0]
+	for _, at := range c.strikes {
+		if now.Sub(at) < this.StrikePeriod {
+			strikes = append(strikes, at)
+		}
+	}
+	c.strikes = append(strikes, now)
+
+	if this.Strikes > 0 && len(c.strikes) >= this.Strikes {
+		res.Bilakh = this.BilakhFor
+		for i := 0; i < c.escalations && res.Bilakh < this.MaxBilakhFor; i++ {
+			res.Bilakh *= 2
+		}
+		res.Bilakh = min(this.MaxBilakhFor, res.Bilakh)
+
+		c.escalations++
+		c.strikes = c.strikes[:0]
+	}
+
+	return res
+}
+
+// routeSection is the first segment of the route, e.g. watchlist for
+// /watchlist/1/players/2.
+func routeSection(route string) string {
+	route = strings.TrimPrefix(route, "/")
+	if i := strings.IndexAny(route, "/ "); i >= 0 {
+		route = route[:i]
+	}
+
+	return route
+}
+
+func (this *RateLimiter) limits(chatId int64) *chatLimits {
+	if this.chats == nil {
+		this.chats = make(map[int64]*chatLimits)
+	}
+
+	c, ok := this.chats[chatId]
+	if !ok {
+		c = &chatLimits{sections: make(map[string]*bucket)}
+		this.chats[chatId] = c
+	}
+
+	return c
+}
+
+func (this *RateLimiter) prune(now time.Time) {
+	if now.Sub(this.pruned) < RATE_LIMIT_IDLE_PERIOD {
+		return
+	}
+	this.pruned = now
+
+	for chatId, c := range this.chats {
+		if now.Sub(c.lastSeen) >= RATE_LIMIT_IDLE_PERIOD {
+			delete(this.chats, chatId)
+		}
+	}
+}
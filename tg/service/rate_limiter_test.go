@@ -0,0 +1,97 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateLimiterBuckets(t *testing.T) {
+	limiter := RateLimiter{
+		Chat:  RateLimit{Burst: 4, Rate: 1},
+		Route: RateLimit{Burst: 2, Rate: 0.5},
+	}
+
+	now := time.Unix(1700000000, 0)
+
+	allowed := func(route string) bool {
+		return limiter.Allow(1, route, now).Allowed
+	}
+
+	if !allowed("/a") || !allowed("/a") {
+		t.Fatal("expected the route's burst to go through")
+	}
+	if allowed("/a") {
+		t.Fatal("expected the route to run out")
+	}
+	if allowed("/a/2") || allowed("/a/3 (input)") {
+		t.Fatal("expected the routes of a section to share the same bucket")
+	}
+	if !allowed("/b") || !allowed("/b") {
+		t.Fatal("expected another route to have its own share")
+	}
+	if allowed("/c") {
+		t.Fatal("expected the chat to run out")
+	}
+	if !limiter.Allow(2, "/a", now).Allowed {
+		t.Fatal("expected another chat to have its own share")
+	}
+
+	now = now.Add(2 * time.Second)
+
+	if !allowed("/a") {
+		t.Fatal("expected the route to refill")
+	}
+	if allowed("/a") {
+		t.Fatal("expected the route to refill a token every two seconds")
+	}
+}
+
+func TestRateLimiterEscalation(t *testing.T) {
+	limiter := RateLimiter{
+		Chat:         RateLimit{Burst: 1, Rate: 0.001},
+		Route:        RateLimit{Burst: 1, Rate: 0.001},
+		Strikes:      3,
+		StrikePeriod: time.Minute,
+		BilakhFor:    10 * time.Minute,
+		MaxBilakhFor: 30 * time.Minute,
+	}
+
+	now := time.Unix(1700000000, 0)
+
+	if !limiter.Allow(1, "/a", now).Allowed {
+		t.Fatal("expected the first update to go through")
+	}
+
+	res := limiter.Allow(1, "/a", now)
+	if res.Allowed || !res.Warn {
+		t.Fatalf("expected to be warned, got %+v", res)
+	}
+
+	res = limiter.Allow(1, "/a", now)
+	if res.Warn || res.Bilakh != 0 {
+		t.Fatalf("expected to be warned only once, got %+v", res)
+	}
+
+	// strikes older than the period don't count
+	now = now.Add(2 * time.Minute)
+
+	for i := 0; i < 2; i++ {
+		res = limiter.Allow(1, "/a", now)
+		if res.Bilakh != 0 {
+			t.Fatalf("expected no bilakh on strike %d, got %+v", i, res)
+		}
+	}
+
+	expected := []time.Duration{10 * time.Minute, 20 * time.Minute, 30 * time.Minute}
+	for i, d := range expected {
+		if i != 0 {
+			limiter.Allow(1, "/a", now)
+			limiter.Allow(1, "/a", now)
+		}
+
+		res = limiter.Allow(1, "/a", now)
+		if res.Bilakh != d {
+			t.Fatalf("expected a bilakh of %s, got %+v", d, res)
+		}
+	}
+}
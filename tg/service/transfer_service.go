@@ -0,0 +1,324 @@
+package service
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/thekhanj/csdmpro/core"
+	"github.com/thekhanj/csdmpro/tg/repo"
+)
+
+// Imports adding more players than this are refused as a whole.
+const MAX_IMPORT_PLAYERS = 500
+
+var (
+	ERR_UNKNOWN_EXPORT_FORMAT error = errors.New("unknown export format")
+	ERR_INVALID_IMPORT        error = core.NewUserError("errors.invalid_import")
+	ERR_TOO_BIG_IMPORT        error = core.NewUserError("errors.too_big_import", MAX_IMPORT_PLAYERS)
+)
+
+type ExportFormat string
+
+const (
+	ExportJSON ExportFormat = "json"
+	ExportCSV  ExportFormat = "csv"
+)
+
+// WatchlistExport is the chat's watchlists as they're exported, players
+// are referred to by name so the file is readable and editable by hand.
+type WatchlistExport struct {
+	Lists []ExportedList `json:"lists"`
+}
+
+type ExportedList struct {
+	// Name is empty for players imported into the selected list.
+	Name    string   `json:"name"`
+	Muted   bool     `json:"muted"`
+	Players []string `json:"players"`
+}
+
+type ImportReport struct {
+	Added          int
+	AlreadyWatched int
+	CreatedLists   []string
+	// SkippedLists couldn't be created, e.g. for the chat having too many
+	// lists already.
+	SkippedLists []string
+	Unmatched    []string
+}
+
+type TransferService struct {
+	PlayerRepo    *core.PlayerRepo
+	WatchlistRepo *repo.WatchlistRepo
+}
+
+func (this *TransferService) Export(chatId int64) (WatchlistExport, error) {
+	lists, err := this.WatchlistRepo.Lists(chatId)
+	if err != nil {
+		return WatchlistExport{}, err
+	}
+
+	e := WatchlistExport{Lists: make([]ExportedList, 0, len(lists))}
+
+	for _, list := range lists {
+		ids, err := this.WatchlistRepo.ListPlayers(list.ID)
+		if err != nil {
+			return WatchlistExport{}, err
+		}
+
+		l := ExportedList{
+			Name:    list.Name,
+			Muted:   !list.Notify,
+			Players: make([]string, 0, len(ids)),
+		}
+
+		for _, id := range ids {
+			p, err := this.PlayerRepo.GetPlayer(id)
+			if err != nil {
+				return WatchlistExport{}, err
+			}
+
+			l.Players = append(l.Players, p.Player.Name)
+		}
+
+		e.Lists = append(e.Lists, l)
+	}
+
+	return e, nil
+}
+
+func (this *TransferService) Encode(
+	e WatchlistExport, format ExportFormat,
+) ([]byte, error) {
+	switch format {
+	case ExportJSON:
+		return json.MarshalIndent(e, "", "  ")
+	case ExportCSV:
+		var buf bytes.Buffer
+
+		w := csv.NewWriter(&buf)
+		w.Write([]string{"list", "player"})
+		for _, l := range e.Lists {
+			for _, name := range l.Players {
+				w.Write([]string{l.Name, name})
+			}
+		}
+		w.Flush()
+
+		return buf.Bytes(), w.Error()
+	default:
+		return nil, ERR_UNKNOWN_EXPORT_FORMAT
+	}
+}
+
+// Decode reads an exported file back. Besides the exported formats, a json
+// array of names or a csv of a single column of names is accepted, which are
+// imported into the selected list.
+func (this *TransferService) Decode(data []byte) (WatchlistExport, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	trimmed := bytes.TrimSpace(data)
+
+	if bytes.HasPrefix(trimmed, []byte("{")) {
+		var e WatchlistExport
+		err := json.Unmarshal(trimmed, &e)
+		if err != nil {
+			return WatchlistExport{}, ERR_INVALID_IMPORT
+		}
+
+		return e, nil
+	}
+
+	if bytes.HasPrefix(trimmed, []byte("[")) {
+		var names []string
+		err := json.Unmarshal(trimmed, &names)
+		if err != nil {
+			return WatchlistExport{}, ERR_INVALID_IMPORT
+		}
+
+		return WatchlistExport{
+			Lists: []ExportedList{{Players: names}},
+		}, nil
+	}
+
+	return this.decodeCSV(data)
+}
+
+func (this *TransferService) decodeCSV(data []byte) (WatchlistExport, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+
+	records, err := r.ReadAll()
+	if err != nil && err != io.EOF {
+		return WatchlistExport{}, ERR_INVALID_IMPORT
+	}
+
+	listColumn, playerColumn := -1, 0
+	if len(records) != 0 {
+		hasHeader := false
+
+		for i, column := range records[0] {
+			switch strings.ToLower(strings.TrimSpace(column)) {
+			case "list":
+				listColumn = i
+				hasHeader = true
+			case "player":
+				playerColumn = i
+				hasHeader = true
+			}
+		}
+
+		if hasHeader {
+			records = records[1:]
+		}
+	}
+
+	e := WatchlistExport{}
+	indexes := map[string]int{}
+
+	for _, record := range records {
+		if playerColumn >= len(record) {
+			continue
+		}
+
+		name := record[playerColumn]
+		if strings.TrimSpace(name) == "" {
+			continue
+		}
+
+		list := ""
+		if listColumn != -1 && listColumn < len(record) {
+			list = record[listColumn]
+		}
+
+		i, ok := indexes[list]
+		if !ok {
+			i = len(e.Lists)
+			indexes[list] = i
+			e.Lists = append(e.Lists, ExportedList{Name: list})
+		}
+
+		e.Lists[i].Players = append(e.Lists[i].Players, name)
+	}
+
+	return e, nil
+}
+
+// Import adds the players into the chat's lists of the same names, creating
+// missing lists, and matches players by their exact names. The chat's
+// selected list stays the same.
+func (this *TransferService) Import(
+	chatId int64, e WatchlistExport,
+) (ImportReport, error) {
+	count := 0
+	for _, l := range e.Lists {
+		count += len(l.Players)
+	}
+	if count > MAX_IMPORT_PLAYERS {
+		return ImportReport{}, ERR_TOO_BIG_IMPORT
+	}
+
+	selected, err := this.WatchlistRepo.Selected(chatId)
+	if err != nil {
+		return ImportReport{}, err
+	}
+
+	report := ImportReport{
+		CreatedLists: []string{},
+		SkippedLists: []string{},
+		Unmatched:    []string{},
+	}
+
+	for _, l := range e.Lists {
+		list, created, err := this.findOrCreateList(chatId, selected, l)
+		if errors.Is(err, repo.ERR_TOO_MANY_WATCHLISTS) ||
+			errors.Is(err, repo.ERR_INVALID_WATCHLIST_NAME) {
+			report.SkippedLists = append(report.SkippedLists, l.Name)
+			continue
+		}
+		if err != nil {
+			return ImportReport{}, err
+		}
+		if created {
+			report.CreatedLists = append(report.CreatedLists, list.Name)
+		}
+
+		ids, err := this.WatchlistRepo.ListPlayers(list.ID)
+		if err != nil {
+			return ImportReport{}, err
+		}
+
+		watched := make(map[core.PlayerId]bool, len(ids))
+		for _, id := range ids {
+			watched[id] = true
+		}
+
+		for _, name := range l.Players {
+			p, err := this.PlayerRepo.GetPlayerByName(strings.TrimSpace(name))
+			if err == core.ERR_PLAYER_NOT_FOUND {
+				report.Unmatched = append(report.Unmatched, name)
+				continue
+			}
+			if err != nil {
+				return ImportReport{}, err
+			}
+
+			if watched[p.ID] {
+				report.AlreadyWatched++
+				continue
+			}
+
+			err = this.WatchlistRepo.AddToList(list.ID, p.ID)
+			if err != nil {
+				return ImportReport{}, err
+			}
+
+			watched[p.ID] = true
+			report.Added++
+		}
+	}
+
+	err = this.WatchlistRepo.Select(chatId, selected.ID)
+	if err != nil {
+		return ImportReport{}, err
+	}
+
+	return report, nil
+}
+
+func (this *TransferService) findOrCreateList(
+	chatId int64, selected repo.Watchlist, l ExportedList,
+) (repo.Watchlist, bool, error) {
+	name := strings.TrimSpace(l.Name)
+	if name == "" {
+		return selected, false, nil
+	}
+
+	lists, err := this.WatchlistRepo.Lists(chatId)
+	if err != nil {
+		return repo.Watchlist{}, false, err
+	}
+
+	for _, list := range lists {
+		if strings.EqualFold(list.Name, name) {
+			return list, false, nil
+		}
+	}
+
+	list, err := this.WatchlistRepo.CreateList(chatId, name)
+	if err != nil {
+		return repo.Watchlist{}, false, err
+	}
+
+	if l.Muted {
+		err = this.WatchlistRepo.SetNotify(chatId, list.ID, false)
+		if err != nil {
+			return repo.Watchlist{}, false, err
+		}
+	}
+
+	return list, true, nil
+}
@@ -1,24 +1,27 @@
 package tg
 
 import (
-	"log"
 	"os"
+	"time"
 
 	"github.com/google/wire"
 	"github.com/thekhanj/csdmpro/core"
 	"github.com/thekhanj/csdmpro/db"
+	"github.com/thekhanj/csdmpro/logging"
 	"github.com/thekhanj/csdmpro/tg/controllers"
 	"github.com/thekhanj/csdmpro/tg/repo"
 	"github.com/thekhanj/csdmpro/tg/service"
 	"github.com/thekhanj/tgool"
 )
 
+var repoLog = logging.Component("repo")
+
 type TgControllers []tgool.Controller
 
 func ProvideWatchlistRepo(db db.Database) *repo.WatchlistRepo {
 	repo, err := repo.CreateWatchlistRepo(db)
 	if err != nil {
-		log.Fatal(err)
+		logging.Fatal(repoLog, "failed creating watchlist repo", "err", err)
 	}
 
 	return repo
@@ -27,7 +30,61 @@ func ProvideWatchlistRepo(db db.Database) *repo.WatchlistRepo {
 func ProvideBilakhRepo(db db.Database) *repo.BilakhRepo {
 	repo, err := repo.CreateBilakhRepo(db)
 	if err != nil {
-		log.Fatal(err)
+		logging.Fatal(repoLog, "failed creating bilakh repo", "err", err)
+	}
+
+	return repo
+}
+
+func ProvideSettingsRepo(db db.Database) *repo.SettingsRepo {
+	repo, err := repo.CreateSettingsRepo(db)
+	if err != nil {
+		logging.Fatal(repoLog, "failed creating settings repo", "err", err)
+	}
+
+	return repo
+}
+
+func ProvideAlertRepo(db db.Database) *repo.AlertRepo {
+	repo, err := repo.CreateAlertRepo(db)
+	if err != nil {
+		logging.Fatal(repoLog, "failed creating alert repo", "err", err)
+	}
+
+	return repo
+}
+
+func ProvideLiveBoardRepo(db db.Database) *repo.LiveBoardRepo {
+	repo, err := repo.CreateLiveBoardRepo(db)
+	if err != nil {
+		logging.Fatal(repoLog, "failed creating live board repo", "err", err)
+	}
+
+	return repo
+}
+
+func ProvideChatRepo(db db.Database) *repo.ChatRepo {
+	repo, err := repo.CreateChatRepo(db)
+	if err != nil {
+		logging.Fatal(repoLog, "failed creating chat repo", "err", err)
+	}
+
+	return repo
+}
+
+func ProvideUsageRepo(db db.Database) *repo.UsageRepo {
+	repo, err := repo.CreateUsageRepo(db)
+	if err != nil {
+		logging.Fatal(repoLog, "failed creating usage repo", "err", err)
+	}
+
+	return repo
+}
+
+func ProvideFailureRepo(db db.Database) *repo.FailureRepo {
+	repo, err := repo.CreateFailureRepo(db)
+	if err != nil {
+		logging.Fatal(repoLog, "failed creating failure repo", "err", err)
 	}
 
 	return repo
@@ -43,38 +100,250 @@ func ProvideWatchlistService(
 	}
 }
 
+func ProvidePermissionService(
+	settingsRepo *repo.SettingsRepo,
+) *service.PermissionService {
+	return &service.PermissionService{SettingsRepo: settingsRepo}
+}
+
+func ProvideTransferService(
+	playerRepo *core.PlayerRepo,
+	watchlistRepo *repo.WatchlistRepo,
+) *service.TransferService {
+	return &service.TransferService{
+		PlayerRepo:    playerRepo,
+		WatchlistRepo: watchlistRepo,
+	}
+}
+
+func ProvideLiveBoardService(
+	playerRepo *core.PlayerRepo,
+) *service.LiveBoardService {
+	return &service.LiveBoardService{PlayerRepo: playerRepo}
+}
+
+func ProvideLocaleService(
+	settingsRepo *repo.SettingsRepo,
+) *service.LocaleService {
+	return &service.LocaleService{SettingsRepo: settingsRepo}
+}
+
+func ProvideChatService(chatRepo *repo.ChatRepo) *service.ChatService {
+	return &service.ChatService{ChatRepo: chatRepo}
+}
+
+func ProvideAdminService() *service.AdminService {
+	chatIds, err := service.ParseAdminChatIds(os.Getenv("ADMIN_CHAT_IDS"))
+	if err != nil {
+		logging.Fatal(serverLog, "invalid ADMIN_CHAT_IDS", "err", err)
+	}
+
+	return &service.AdminService{
+		ChatIds: chatIds,
+	}
+}
+
+// ProvideRateLimiter lets a chat press around a button a second, with
+// room for bursts, and a single button once every two seconds. Pressing
+// through a minute of being turned down gets it bilakhed.
+func ProvideRateLimiter() *service.RateLimiter {
+	return &service.RateLimiter{
+		Chat:         service.RateLimit{Burst: 20, Rate: 1},
+		Route:        service.RateLimit{Burst: 5, Rate: 0.5},
+		Strikes:      30,
+		StrikePeriod: time.Minute,
+		BilakhFor:    10 * time.Minute,
+		MaxBilakhFor: 24 * time.Hour,
+	}
+}
+
+func ProvideBroadcastService(
+	chatRepo *repo.ChatRepo,
+	chats *service.ChatService,
+) *service.BroadcastService {
+	return &service.BroadcastService{
+		ChatRepo: chatRepo,
+		Chats:    chats,
+	}
+}
+
+func ProvideDigestService(
+	playerRepo *core.PlayerRepo,
+	watchlistRepo *repo.WatchlistRepo,
+) *service.DigestService {
+	return &service.DigestService{
+		PlayerRepo:    playerRepo,
+		WatchlistRepo: watchlistRepo,
+	}
+}
+
+func ProvideAlertService(
+	playerRepo *core.PlayerRepo,
+	alertRepo *repo.AlertRepo,
+) *service.AlertService {
+	return &service.AlertService{
+		PlayerRepo: playerRepo,
+		AlertRepo:  alertRepo,
+	}
+}
+
 func ProvideControllers(
 	playerRepo *core.PlayerRepo,
 	watchlistRepo *repo.WatchlistRepo,
+	settingsRepo *repo.SettingsRepo,
+	alertRepo *repo.AlertRepo,
 	service *service.WatchlistService,
+	digestService *service.DigestService,
+	alertService *service.AlertService,
+	permissions *service.PermissionService,
+	transferService *service.TransferService,
+	liveBoardRepo *repo.LiveBoardRepo,
+	liveBoardService *service.LiveBoardService,
+	locales *service.LocaleService,
+	observer *core.Observer,
+	bilakhRepo *repo.BilakhRepo,
+	chatRepo *repo.ChatRepo,
+	usageRepo *repo.UsageRepo,
+	failureRepo *repo.FailureRepo,
+	broadcastService *service.BroadcastService,
 ) TgControllers {
-	start := &controllers.StartController{}
+	start := &controllers.StartController{Locales: locales}
+	language := &controllers.LanguageController{
+		Locales:     locales,
+		Permissions: permissions,
+	}
 	watchlist := &controllers.WatchlistController{
 		Service:       service,
 		PlayerRepo:    playerRepo,
 		WatchlistRepo: watchlistRepo,
+		SettingsRepo:  settingsRepo,
+		Locales:       locales,
+		Permissions:   permissions,
+	}
+	watchlists := &controllers.WatchlistsController{
+		WatchlistRepo: watchlistRepo,
+		Locales:       locales,
+		Permissions:   permissions,
+	}
+	watchRules := &controllers.WatchRulesController{
+		WatchlistRepo: watchlistRepo,
+		Locales:       locales,
+		Permissions:   permissions,
+	}
+	transfer := &controllers.TransferController{
+		Service:     transferService,
+		Locales:     locales,
+		Permissions: permissions,
+	}
+	stats := &controllers.StatsController{
+		PlayerRepo:   playerRepo,
+		SettingsRepo: settingsRepo,
+		Locales:      locales,
+	}
+	onlines := &controllers.OnlinesController{
+		PlayerRepo:       playerRepo,
+		LiveBoardRepo:    liveBoardRepo,
+		LiveBoardService: liveBoardService,
+		Locales:          locales,
+		Permissions:      permissions,
+	}
+	leaderboards := &controllers.LeaderboardsController{
+		PlayerRepo: playerRepo,
+		Locales:    locales,
+	}
+	server := &controllers.ServerController{
+		PlayerRepo:   playerRepo,
+		SettingsRepo: settingsRepo,
+		Locales:      locales,
+	}
+	compare := &controllers.CompareController{
+		PlayerRepo: playerRepo,
+		Service:    service,
+		Locales:    locales,
+	}
+	digest := &controllers.DigestController{
+		SettingsRepo:  settingsRepo,
+		DigestService: digestService,
+		Locales:       locales,
+		Permissions:   permissions,
+	}
+	alerts := &controllers.AlertsController{
+		PlayerRepo:       playerRepo,
+		AlertRepo:        alertRepo,
+		AlertService:     alertService,
+		WatchlistService: service,
+		Locales:          locales,
+		Permissions:      permissions,
+	}
+	admin := &controllers.AdminController{
+		BilakhRepo:  bilakhRepo,
+		ChatRepo:    chatRepo,
+		UsageRepo:   usageRepo,
+		FailureRepo: failureRepo,
+		Observer:    observer,
+	}
+	broadcast := &controllers.BroadcastController{
+		ChatRepo: chatRepo,
+		Service:  broadcastService,
 	}
-	stats := &controllers.StatsController{PlayerRepo: playerRepo}
-	onlines := &controllers.OnlinesController{PlayerRepo: playerRepo}
 
 	return TgControllers{
 		start,
+		language,
 		watchlist,
+		watchlists,
+		watchRules,
+		transfer,
 		stats,
 		onlines,
+		leaderboards,
+		compare,
+		server,
+		digest,
+		alerts,
+		admin,
+		broadcast,
 	}
 }
 
 func ProvideTg(
 	controllers TgControllers,
 	bilakhRepo *repo.BilakhRepo,
+	chatRepo *repo.ChatRepo,
+	locales *service.LocaleService,
+	adminService *service.AdminService,
+	limiter *service.RateLimiter,
+	failureRepo *repo.FailureRepo,
 ) *Server {
 	serverBuilder := ServerBuilder{}
 
 	serverBuilder.
 		WithToken(os.Getenv("API_TOKEN")).
 		WithControllers(controllers...).
-		WithBilakhRepo(bilakhRepo)
+		WithBilakhRepo(bilakhRepo).
+		WithChatRepo(chatRepo).
+		WithLocales(locales).
+		WithAdmins(adminService).
+		WithRateLimiter(limiter).
+		WithFailureRepo(failureRepo)
+
+	// deployments behind a reverse proxy get the updates pushed instead of
+	// keeping a polling connection open
+	webhookUrl := os.Getenv("WEBHOOK_URL")
+	if webhookUrl != "" {
+		listen := os.Getenv("WEBHOOK_LISTEN")
+		if listen == "" {
+			listen = ":8443"
+		}
+
+		serverBuilder.WithWebhook(WebhookConfig{
+			URL:      webhookUrl,
+			Listen:   listen,
+			Secret:   os.Getenv("WEBHOOK_SECRET"),
+			CertFile: os.Getenv("WEBHOOK_CERT"),
+			KeyFile:  os.Getenv("WEBHOOK_KEY"),
+		})
+	}
 
 	socks_proxy := os.Getenv("http_proxy")
 	if socks_proxy != "" {
@@ -83,7 +352,7 @@ func ProvideTg(
 
 	s, err := serverBuilder.Build()
 	if err != nil {
-		log.Fatal(err)
+		logging.Fatal(serverLog, "failed building server", "err", err)
 	}
 
 	return s
@@ -93,18 +362,81 @@ func ProvideNotifier(
 	observer *core.Observer,
 	watchlistRepo *repo.WatchlistRepo,
 	playerRepo *core.PlayerRepo,
+	locales *service.LocaleService,
+	chats *service.ChatService,
 	server *Server,
 ) *Notifier {
 	return &Notifier{
 		observer:      observer,
 		watchlistRepo: watchlistRepo,
 		playerRepo:    playerRepo,
+		locales:       locales,
+		chats:         chats,
+		bot:           server.bot,
+	}
+}
+
+func ProvideScheduler(
+	settingsRepo *repo.SettingsRepo,
+	digestService *service.DigestService,
+	locales *service.LocaleService,
+	chats *service.ChatService,
+	server *Server,
+) *Scheduler {
+	return &Scheduler{
+		settingsRepo:  settingsRepo,
+		digestService: digestService,
+		locales:       locales,
+		chats:         chats,
+		bot:           server.bot,
+	}
+}
+
+func ProvideAlerter(
+	observer *core.Observer,
+	playerRepo *core.PlayerRepo,
+	alertService *service.AlertService,
+	locales *service.LocaleService,
+	chats *service.ChatService,
+	server *Server,
+) *Alerter {
+	return &Alerter{
+		observer:     observer,
+		playerRepo:   playerRepo,
+		alertService: alertService,
+		locales:      locales,
+		chats:        chats,
+		bot:          server.bot,
+	}
+}
+
+func ProvideLiveBoards(
+	observer *core.Observer,
+	liveBoardRepo *repo.LiveBoardRepo,
+	liveBoardService *service.LiveBoardService,
+	locales *service.LocaleService,
+	chats *service.ChatService,
+	server *Server,
+) *LiveBoards {
+	return &LiveBoards{
+		observer:      observer,
+		liveBoardRepo: liveBoardRepo,
+		service:       liveBoardService,
+		locales:       locales,
+		chats:         chats,
 		bot:           server.bot,
 	}
 }
 
 var TgModule = wire.NewSet(
 	ProvideTg, ProvideControllers,
-	ProvideWatchlistRepo, ProvideBilakhRepo,
-	ProvideWatchlistService, ProvideNotifier,
+	ProvideWatchlistRepo, ProvideBilakhRepo, ProvideSettingsRepo,
+	ProvideAlertRepo, ProvideLiveBoardRepo, ProvideUsageRepo,
+	ProvideChatRepo, ProvideFailureRepo,
+	ProvideWatchlistService, ProvideDigestService, ProvideAlertService,
+	ProvidePermissionService, ProvideTransferService, ProvideLiveBoardService,
+	ProvideLocaleService, ProvideAdminService, ProvideChatService,
+	ProvideBroadcastService,
+	ProvideRateLimiter,
+	ProvideNotifier, ProvideScheduler, ProvideAlerter, ProvideLiveBoards,
 )
@@ -0,0 +1,195 @@
+package tg
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+var ERR_INVALID_WEBHOOK_URL error = errors.New("webhook url must be https")
+var ERR_INVALID_WEBHOOK_SECRET error = errors.New(
+	"webhook secret must be 1 to 256 characters of A-Z, a-z, 0-9, _ and -",
+)
+var ERR_WEBHOOK_CERT_WITHOUT_KEY error = errors.New(
+	"webhook cert and key must be given together",
+)
+
+var webhookSecretRegex = regexp.MustCompile(`^[A-Za-z0-9_-]{1,256}$`)
+
+const (
+	// Telegram sends the secret of the webhook in this header.
+	WEBHOOK_SECRET_HEADER = "X-Telegram-Bot-Api-Secret-Token"
+	// Updates are way smaller than this, anything bigger isn't telegram.
+	MAX_WEBHOOK_BODY = 1 << 20
+	// Time given to the requests being handled when the server stops.
+	WEBHOOK_SHUTDOWN_TIMEOUT = 5 * time.Second
+)
+
+// WebhookConfig makes telegram push the updates to URL instead of the
+// server polling for them. The server listens on Listen for the path of
+// URL, serving https if CertFile and KeyFile are given, otherwise plain
+// http is served for a reverse proxy to terminate https in front of it.
+// The cert is uploaded to telegram so self-signed ones work too. Telegram
+// sends Secret along with the updates, requests without it are refused.
+type WebhookConfig struct {
+	URL      string
+	Listen   string
+	Secret   string
+	CertFile string
+	KeyFile  string
+}
+
+func (this *WebhookConfig) validate() error {
+	u, err := url.Parse(this.URL)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "https" || u.Host == "" {
+		return ERR_INVALID_WEBHOOK_URL
+	}
+
+	if !webhookSecretRegex.MatchString(this.Secret) {
+		return ERR_INVALID_WEBHOOK_SECRET
+	}
+
+	if (this.CertFile == "") != (this.KeyFile == "") {
+		return ERR_WEBHOOK_CERT_WITHOUT_KEY
+	}
+
+	return nil
+}
+
+func (this *WebhookConfig) path() string {
+	u, _ := url.Parse(this.URL)
+	if u.Path == "" {
+		return "/"
+	}
+
+	return u.Path
+}
+
+// setWebhook points telegram to the webhook, setWebhook of tgbotapi
+// doesn't know about the secret token.
+func (this *Server) setWebhook() error {
+	params := make(tgbotapi.Params)
+	params["url"] = this.webhook.URL
+	params.AddNonEmpty("secret_token", this.webhook.Secret)
+
+	if this.webhook.CertFile == "" {
+		_, err := this.bot.MakeRequest("setWebhook", params)
+		return err
+	}
+
+	_, err := this.bot.UploadFiles("setWebhook", params, []tgbotapi.RequestFile{{
+		Name: "certificate",
+		Data: tgbotapi.FilePath(this.webhook.CertFile),
+	}})
+	return err
+}
+
+// listenWebhook serves the webhook until ctx is done, sending the updates
+// to the returned channel, which is closed once the server is stopped.
+func (this *Server) listenWebhook(
+	ctx context.Context,
+) (tgbotapi.UpdatesChannel, error) {
+	ln, err := net.Listen("tcp", this.webhook.Listen)
+	if err != nil {
+		return nil, err
+	}
+
+	err = this.setWebhook()
+	if err != nil {
+		ln.Close()
+		return nil, err
+	}
+
+	updates := make(chan tgbotapi.Update, this.bot.Buffer)
+	// requests being handled, updates is closed once they're all done
+	var inflight sync.WaitGroup
+
+	mux := http.NewServeMux()
+	mux.Handle(this.webhook.path(), this.webhookHandler(updates, &inflight))
+
+	srv := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	serveDone := make(chan struct{})
+	go func() {
+		defer close(serveDone)
+
+		serverLog.Info("listening for webhook", "addr", this.webhook.Listen)
+
+		var err error
+		if this.webhook.CertFile != "" {
+			err = srv.ServeTLS(ln, this.webhook.CertFile, this.webhook.KeyFile)
+		} else {
+			err = srv.Serve(ln)
+		}
+
+		if err != nil && err != http.ErrServerClosed {
+			serverLog.Error("webhook server failed", "err", err)
+		}
+	}()
+
+	go func() {
+		defer close(updates)
+
+		select {
+		case <-ctx.Done():
+		case <-serveDone:
+		}
+
+		shutdownCtx, cancel := context.WithTimeout(
+			context.Background(), WEBHOOK_SHUTDOWN_TIMEOUT,
+		)
+		defer cancel()
+
+		err := srv.Shutdown(shutdownCtx)
+		if err != nil {
+			serverLog.Warn("failed shutting down webhook server", "err", err)
+			srv.Close()
+		}
+		<-serveDone
+		inflight.Wait()
+	}()
+
+	return updates, nil
+}
+
+func (this *Server) webhookHandler(
+	updates chan<- tgbotapi.Update, inflight *sync.WaitGroup,
+) http.HandlerFunc {
+	secret := []byte(this.webhook.Secret)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		inflight.Add(1)
+		defer inflight.Done()
+
+		given := []byte(r.Header.Get(WEBHOOK_SECRET_HEADER))
+		if subtle.ConstantTimeCompare(given, secret) != 1 {
+			serverLog.Warn("webhook request with a wrong secret", "remote_addr", r.RemoteAddr)
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, MAX_WEBHOOK_BODY)
+
+		update, err := this.bot.HandleUpdate(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		updates <- *update
+	}
+}
@@ -0,0 +1,82 @@
+package tg
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestWebhookConfigValidate(t *testing.T) {
+	valid := WebhookConfig{URL: "https://bot.example.com/tg", Secret: "s3cret_-"}
+
+	tests := []struct {
+		config WebhookConfig
+		err    error
+	}{
+		{valid, nil},
+		{WebhookConfig{URL: "http://bot.example.com/tg", Secret: "s"}, ERR_INVALID_WEBHOOK_URL},
+		{WebhookConfig{URL: "https://bot.example.com/tg"}, ERR_INVALID_WEBHOOK_SECRET},
+		{WebhookConfig{URL: valid.URL, Secret: "not secret"}, ERR_INVALID_WEBHOOK_SECRET},
+		{WebhookConfig{URL: valid.URL, Secret: "s", CertFile: "cert.pem"}, ERR_WEBHOOK_CERT_WITHOUT_KEY},
+	}
+
+	for _, test := range tests {
+		err := test.config.validate()
+		if err != test.err {
+			t.Fatalf("expected %v for %+v, got %v", test.err, test.config, err)
+		}
+	}
+
+	if valid.path() != "/tg" {
+		t.Fatalf("expected the webhook's path to be /tg, got %s", valid.path())
+	}
+}
+
+func TestWebhookHandlerChecksSecret(t *testing.T) {
+	s := &Server{
+		bot:     &tgbotapi.BotAPI{Buffer: 1},
+		webhook: &WebhookConfig{Secret: "s3cret"},
+	}
+
+	updates := make(chan tgbotapi.Update, 1)
+	var inflight sync.WaitGroup
+	handler := s.webhookHandler(updates, &inflight)
+
+	post := func(secret string) int {
+		r := httptest.NewRequest(
+			http.MethodPost, "/tg", strings.NewReader(`{"update_id": 42}`),
+		)
+		if secret != "" {
+			r.Header.Set(WEBHOOK_SECRET_HEADER, secret)
+		}
+
+		w := httptest.NewRecorder()
+		handler(w, r)
+
+		return w.Code
+	}
+
+	for _, secret := range []string{"", "wrong"} {
+		code := post(secret)
+		if code != http.StatusForbidden {
+			t.Fatalf("expected secret %q to be forbidden, got %d", secret, code)
+		}
+	}
+	if len(updates) != 0 {
+		t.Fatal("expected no updates from forbidden requests")
+	}
+
+	code := post("s3cret")
+	if code != http.StatusOK {
+		t.Fatalf("expected the update to be accepted, got %d", code)
+	}
+
+	update := <-updates
+	if update.UpdateID != 42 {
+		t.Fatalf("unexpected update %+v", update)
+	}
+}